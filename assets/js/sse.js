@@ -0,0 +1,147 @@
+/*
+Server Sent Events extension for htmx 1.9.
+
+Supports the sse-connect and sse-swap attributes of the official
+extension: an element with hx-ext="sse" and sse-connect="<url>" opens an
+EventSource, and its descendants with sse-swap="<event name>" swap each
+message of that event into themselves (or their hx-target) using hx-swap,
+out of band swaps included. Descendants can also be triggered with
+hx-trigger="sse:<event name>". The connection is closed when the element
+is removed, and the browser reconnects on its own after errors.
+*/
+(function () {
+    var api;
+
+    htmx.defineExtension("sse", {
+        init: function (apiRef) {
+            api = apiRef;
+            if (htmx.createEventSource == undefined) {
+                htmx.createEventSource = function (url) {
+                    return new EventSource(url, { withCredentials: true });
+                };
+            }
+        },
+
+        onEvent: function (name, evt) {
+            switch (name) {
+                case "htmx:beforeCleanupElement":
+                    var data = api.getInternalData(evt.target);
+                    if (data.sseEventSource) {
+                        data.sseEventSource.close();
+                    }
+                    return;
+
+                case "htmx:afterProcessNode":
+                    var elt = evt.target;
+                    if (connect(elt)) {
+                        // htmx does not process elements that only have
+                        // sse-swap, so they are looked up here.
+                        elt.querySelectorAll("[sse-swap], [data-sse-swap]").forEach(listen);
+                    }
+                    listen(elt);
+            }
+        }
+    });
+
+    // connect opens the EventSource of an element with sse-connect,
+    // reporting whether it did.
+    function connect(elt) {
+        var url = api.getAttributeValue(elt, "sse-connect");
+        if (url == null) {
+            return false;
+        }
+        var data = api.getInternalData(elt);
+        if (data.sseEventSource) {
+            return false;
+        }
+
+        var source = htmx.createEventSource(url);
+        source.onerror = function (err) {
+            api.triggerErrorEvent(elt, "htmx:sseError", { error: err, source: source });
+        };
+        source.onopen = function () {
+            api.triggerEvent(elt, "htmx:sseOpen", { source: source });
+        };
+        data.sseEventSource = source;
+        return true;
+    }
+
+    // listen subscribes the element's sse-swap and sse: triggers to the
+    // EventSource of the closest element with sse-connect.
+    function listen(elt) {
+        var data = api.getInternalData(elt);
+        var source = eventSource(elt);
+        if (source == null || data.sseListening) {
+            return;
+        }
+        data.sseListening = true;
+
+        var swapNames = api.getAttributeValue(elt, "sse-swap");
+        if (swapNames) {
+            swapNames.split(",").forEach(function (eventName) {
+                eventName = eventName.trim();
+                var listener = function (event) {
+                    if (!api.bodyContains(elt)) {
+                        source.removeEventListener(eventName, listener);
+                        return;
+                    }
+                    swap(elt, event.data);
+                    api.triggerEvent(elt, "htmx:sseMessage", event);
+                };
+                source.addEventListener(eventName, listener);
+            });
+        }
+
+        api.getTriggerSpecs(elt).forEach(function (spec) {
+            if (spec.trigger.slice(0, 4) !== "sse:") {
+                return;
+            }
+            var listener = function () {
+                if (!api.bodyContains(elt)) {
+                    source.removeEventListener(spec.trigger.slice(4), listener);
+                    return;
+                }
+                htmx.trigger(elt, spec.trigger);
+            };
+            source.addEventListener(spec.trigger.slice(4), listener);
+        });
+    }
+
+    function eventSource(elt) {
+        var connected = api.getClosestMatch(elt, function (e) {
+            return api.getInternalData(e).sseEventSource != null;
+        });
+        return connected ? api.getInternalData(connected).sseEventSource : null;
+    }
+
+    function swap(elt, content) {
+        var swapSpec = api.getSwapSpecification(elt);
+        var target = api.getTarget(elt);
+        var settleInfo = api.makeSettleInfo(elt);
+
+        api.selectAndSwap(swapSpec.swapStyle, target, elt, content, settleInfo);
+        settleInfo.elts.forEach(function (e) {
+            if (e.classList) {
+                e.classList.add(htmx.config.settlingClass);
+            }
+            api.triggerEvent(e, "htmx:beforeSettle");
+        });
+        if (swapSpec.settleDelay > 0) {
+            setTimeout(function () { settle(settleInfo); }, swapSpec.settleDelay);
+        } else {
+            settle(settleInfo);
+        }
+    }
+
+    function settle(settleInfo) {
+        settleInfo.tasks.forEach(function (task) {
+            task.call();
+        });
+        settleInfo.elts.forEach(function (e) {
+            if (e.classList) {
+                e.classList.remove(htmx.config.settlingClass);
+            }
+            api.triggerEvent(e, "htmx:afterSettle");
+        });
+    }
+})();
@@ -0,0 +1,144 @@
+package ashby_client
+
+import (
+	"context"
+	"fmt"
+	"htmxjb/clients/http_client"
+	"htmxjb/models/domain"
+	"htmxjb/models/responses"
+	"htmxjb/services/salary"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const defaultBaseURL = "https://api.ashbyhq.com"
+
+// AshbyClient reads one company's public Ashby job board.
+type AshbyClient struct {
+	board   string
+	company string
+	baseURL string
+	http    *http_client.Client
+}
+
+// NewAshbyClient returns a client for the board name in
+// jobs.ashbyhq.com/{board}. Ashby does not name the employer, so company
+// is used for every job. A shared hc lets sources share rate limits; nil
+// uses a default client.
+func NewAshbyClient(board, company string, hc *http_client.Client) *AshbyClient {
+	if hc == nil {
+		hc = http_client.New(http_client.DefaultConfig())
+	}
+	return &AshbyClient{
+		board:   board,
+		company: company,
+		baseURL: defaultBaseURL,
+		http:    hc,
+	}
+}
+
+func (c *AshbyClient) Source() domain.JobSource {
+	return domain.Ashby
+}
+
+// FetchJobs returns the board's listed jobs with their compensation. The
+// API is not paginated.
+func (c *AshbyClient) FetchJobs(ctx context.Context) ([]domain.Job, error) {
+	endpoint := c.baseURL + "/posting-api/job-board/" + url.PathEscape(c.board) + "?includeCompensation=true"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	var board responses.AshbyResponse
+	if err := c.http.GetJSON(req, &board); err != nil {
+		return nil, fmt.Errorf("failed to fetch ashby board %q: %w", c.board, err)
+	}
+
+	var jobs []domain.Job
+	for _, job := range board.Jobs {
+		if !job.IsListed {
+			continue
+		}
+		jobs = append(jobs, MapJob(job, c.company))
+	}
+
+	return jobs, nil
+}
+
+// MapJob converts an Ashby job to a domain job. The salary component of
+// the compensation is used; equity and bonuses are left out.
+func MapJob(job responses.AshbyJob, company string) domain.Job {
+	mapped := domain.Job{
+		ExternalID:      job.ID,
+		Title:           strings.TrimSpace(job.Title),
+		DescriptionHTML: job.DescriptionHTML,
+		Company:         company,
+		Department:      job.Department,
+		URL:             job.JobURL,
+		Source:          domain.Ashby,
+		TypeText:        strings.Join(nonEmpty(job.EmploymentType, job.WorkplaceType), ", "),
+		Location:        mapLocation(job),
+	}
+	if mapped.Department == "" {
+		mapped.Department = job.Team
+	}
+
+	if job.Compensation != nil {
+		for _, part := range job.Compensation.SummaryComponents {
+			if part.CompensationType != "Salary" {
+				continue
+			}
+			mapped.Salary, _ = salary.FromRange(deref(part.MinValue), deref(part.MaxValue), part.CurrencyCode, intervalUnit(part.Interval))
+			break
+		}
+	}
+
+	return mapped
+}
+
+func mapLocation(job responses.AshbyJob) domain.Location {
+	loc := domain.Location{
+		Raw:    strings.TrimSpace(job.Location),
+		Remote: job.IsRemote,
+	}
+	if job.Address == nil {
+		return loc
+	}
+
+	addr := job.Address.PostalAddress
+	loc.City = addr.AddressLocality
+	loc.Region = addr.AddressRegion
+	if len(addr.AddressCountry) == 2 {
+		loc.CountryCode = strings.ToUpper(addr.AddressCountry)
+	}
+	return loc
+}
+
+// intervalUnit turns an Ashby interval such as "1 YEAR" into a schema.org
+// unit, "YEAR".
+func intervalUnit(interval string) string {
+	fields := strings.Fields(interval)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToUpper(fields[len(fields)-1])
+}
+
+func nonEmpty(values ...string) []string {
+	var out []string
+	for _, v := range values {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func deref(v *float64) float64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
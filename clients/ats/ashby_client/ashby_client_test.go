@@ -0,0 +1,92 @@
+package ashby_client
+
+import (
+	"context"
+	"htmxjb/clients/fixtures"
+	"htmxjb/clients/http_client"
+	"htmxjb/models/domain"
+	"htmxjb/models/responses"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newReplayClient returns a client for the board the checked-in fixture
+// was recorded from. The posting API needs no key.
+func newReplayClient(t *testing.T) (*AshbyClient, *fixtures.Recorder) {
+	t.Helper()
+
+	rec := fixtures.New(t, "lonestar")
+	return NewAshbyClient("lonestar", "Lone Star", http_client.New(http_client.Config{MaxRetries: 0, Transport: rec})), rec
+}
+
+// TestContract checks the recorded board against AshbyResponse. Run with
+// RECORD_FIXTURES=1 to re-record.
+func TestContract(t *testing.T) {
+	c, rec := newReplayClient(t)
+
+	_, err := c.FetchJobs(context.Background())
+	require.NoError(t, err)
+
+	fixtures.RequireFields(t, rec.ResponseBody(0), responses.AshbyResponse{})
+}
+
+func TestFetchJobs(t *testing.T) {
+	if fixtures.Recording() {
+		t.Skip("asserts on the checked-in fixture")
+	}
+	c, _ := newReplayClient(t)
+
+	jobs, err := c.FetchJobs(context.Background())
+	require.NoError(t, err)
+
+	// The unlisted job is skipped.
+	require.Len(t, jobs, 2)
+
+	assert.Equal(t, domain.Job{
+		ExternalID:      "8d8c6a58-2b8f-4a5e-9a53-9b2f0b0f3a11",
+		Title:           "Staff Engineer",
+		DescriptionHTML: "<p>Scale our <strong>Go</strong> platform.</p>",
+		Company:         "Lone Star",
+		Department:      "Engineering",
+		URL:             "https://jobs.ashbyhq.com/lonestar/8d8c6a58-2b8f-4a5e-9a53-9b2f0b0f3a11",
+		Source:          domain.Ashby,
+		TypeText:        "FullTime, Hybrid",
+		Location: domain.Location{
+			Raw:         "San Francisco",
+			City:        "San Francisco",
+			Region:      "California",
+			CountryCode: "US",
+		},
+		Salary: jobs[0].Salary,
+	}, jobs[0])
+	assert.Equal(t, "USD 180,000 - 220,000 per year", jobs[0].Salary.Raw)
+
+	support := jobs[1]
+	assert.Equal(t, "Support", support.Department)
+	assert.Equal(t, "Contract", support.TypeText)
+	assert.Equal(t, domain.Location{Raw: "Remote", Remote: true}, support.Location)
+	assert.Empty(t, support.Salary.Raw)
+}
+
+func TestFetchJobsBoardNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/posting-api/job-board/missing", r.URL.Path)
+		assert.Equal(t, "true", r.URL.Query().Get("includeCompensation"))
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	c := NewAshbyClient("missing", "", http_client.New(http_client.Config{MaxRetries: 0}))
+	c.baseURL = srv.URL
+
+	_, err := c.FetchJobs(context.Background())
+
+	var statusErr *http_client.StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+	assert.ErrorContains(t, err, `"missing"`)
+}
@@ -0,0 +1,126 @@
+package greenhouse_client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"htmxjb/clients/http_client"
+	"htmxjb/models/domain"
+	"htmxjb/models/responses"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const defaultBaseURL = "https://boards-api.greenhouse.io"
+
+// employmentFields are the custom metadata names boards commonly use for
+// the employment type, lower case.
+var employmentFields = map[string]bool{
+	"employment type": true,
+	"employment":      true,
+	"job type":        true,
+	"time type":       true,
+	"commitment":      true,
+}
+
+// GreenhouseClient reads one company's public Greenhouse job board.
+type GreenhouseClient struct {
+	board   string
+	company string
+	baseURL string
+	http    *http_client.Client
+}
+
+// NewGreenhouseClient returns a client for the board token, the name in
+// boards.greenhouse.io/{board}. Company names the employer when the board
+// does not. A shared hc lets sources share rate limits; nil uses a default
+// client.
+func NewGreenhouseClient(board, company string, hc *http_client.Client) *GreenhouseClient {
+	if hc == nil {
+		hc = http_client.New(http_client.DefaultConfig())
+	}
+	return &GreenhouseClient{
+		board:   board,
+		company: company,
+		baseURL: defaultBaseURL,
+		http:    hc,
+	}
+}
+
+func (c *GreenhouseClient) Source() domain.JobSource {
+	return domain.Greenhouse
+}
+
+// FetchJobs returns every job on the board. The API is not paginated.
+func (c *GreenhouseClient) FetchJobs(ctx context.Context) ([]domain.Job, error) {
+	endpoint := c.baseURL + "/v1/boards/" + url.PathEscape(c.board) + "/jobs?content=true"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	var board responses.GreenhouseResponse
+	if err := c.http.GetJSON(req, &board); err != nil {
+		return nil, fmt.Errorf("failed to fetch greenhouse board %q: %w", c.board, err)
+	}
+
+	jobs := make([]domain.Job, 0, len(board.Jobs))
+	for _, job := range board.Jobs {
+		jobs = append(jobs, MapJob(job, c.company))
+	}
+
+	return jobs, nil
+}
+
+// MapJob converts a Greenhouse job to a domain job. The first department
+// and office are used; the employment type comes from custom metadata.
+func MapJob(job responses.GreenhouseJob, company string) domain.Job {
+	mapped := domain.Job{
+		ExternalID:      strconv.FormatInt(job.ID, 10),
+		Title:           strings.TrimSpace(job.Title),
+		DescriptionHTML: html.UnescapeString(job.Content),
+		Company:         job.CompanyName,
+		URL:             job.AbsoluteURL,
+		Source:          domain.Greenhouse,
+		TypeText:        employmentType(job.Metadata),
+		Location:        domain.Location{Raw: strings.TrimSpace(job.Location.Name)},
+	}
+	if mapped.Company == "" {
+		mapped.Company = company
+	}
+
+	if len(job.Departments) > 0 {
+		mapped.Department = job.Departments[0].Name
+	}
+
+	if mapped.Location.Raw == "" && len(job.Offices) > 0 {
+		office := job.Offices[0]
+		mapped.Location.Raw = office.Name
+		if office.Location != nil && *office.Location != "" {
+			mapped.Location.Raw = *office.Location
+		}
+	}
+
+	return mapped
+}
+
+func employmentType(metadata []responses.GreenhouseMetadata) string {
+	for _, m := range metadata {
+		if !employmentFields[strings.ToLower(strings.TrimSpace(m.Name))] {
+			continue
+		}
+
+		var value string
+		if err := json.Unmarshal(m.Value, &value); err == nil {
+			return value
+		}
+		var values []string
+		if err := json.Unmarshal(m.Value, &values); err == nil {
+			return strings.Join(values, ", ")
+		}
+	}
+	return ""
+}
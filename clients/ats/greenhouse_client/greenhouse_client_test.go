@@ -0,0 +1,85 @@
+package greenhouse_client
+
+import (
+	"context"
+	"htmxjb/clients/fixtures"
+	"htmxjb/clients/http_client"
+	"htmxjb/models/domain"
+	"htmxjb/models/responses"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newReplayClient returns a client for the board the checked-in fixture
+// was recorded from. The board API needs no key.
+func newReplayClient(t *testing.T) (*GreenhouseClient, *fixtures.Recorder) {
+	t.Helper()
+
+	rec := fixtures.New(t, "acme")
+	return NewGreenhouseClient("acme", "Acme", http_client.New(http_client.Config{MaxRetries: 0, Transport: rec})), rec
+}
+
+// TestContract checks the recorded board against GreenhouseResponse. Run
+// with RECORD_FIXTURES=1 to re-record.
+func TestContract(t *testing.T) {
+	c, rec := newReplayClient(t)
+
+	_, err := c.FetchJobs(context.Background())
+	require.NoError(t, err)
+
+	fixtures.RequireFields(t, rec.ResponseBody(0), responses.GreenhouseResponse{})
+}
+
+func TestFetchJobs(t *testing.T) {
+	if fixtures.Recording() {
+		t.Skip("asserts on the checked-in fixture")
+	}
+	c, _ := newReplayClient(t)
+
+	jobs, err := c.FetchJobs(context.Background())
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+
+	assert.Equal(t, domain.Job{
+		ExternalID:      "4012345",
+		Title:           "Senior Go Engineer",
+		DescriptionHTML: "<p>Build <strong>Go</strong> services for our platform.</p><ul><li>htmx</li><li>SQLite</li></ul>",
+		Company:         "Acme Cloud",
+		Department:      "Engineering",
+		URL:             "https://boards.greenhouse.io/acme/jobs/4012345",
+		Source:          domain.Greenhouse,
+		TypeText:        "Full-time",
+		Location:        domain.Location{Raw: "Berlin, Germany"},
+	}, jobs[0])
+
+	// Without a company name, location or employment type field the
+	// configured company, first office and multi-select metadata are used.
+	support := jobs[1]
+	assert.Equal(t, "Support Specialist", support.Title)
+	assert.Equal(t, "Acme", support.Company)
+	assert.Equal(t, "Customer Success", support.Department)
+	assert.Equal(t, "Lisbon, Portugal", support.Location.Raw)
+	assert.Equal(t, "Part-time, Remote", support.TypeText)
+}
+
+func TestFetchJobsBoardNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/boards/missing/jobs", r.URL.Path)
+		http.Error(w, `{"status":404,"error":"Job not found"}`, http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	c := NewGreenhouseClient("missing", "", http_client.New(http_client.Config{MaxRetries: 0}))
+	c.baseURL = srv.URL
+
+	_, err := c.FetchJobs(context.Background())
+
+	var statusErr *http_client.StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+	assert.ErrorContains(t, err, `"missing"`)
+}
@@ -0,0 +1,154 @@
+package lever_client
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"htmxjb/clients/http_client"
+	"htmxjb/models/domain"
+	"htmxjb/models/responses"
+	"htmxjb/services/salary"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const defaultBaseURL = "https://api.lever.co"
+
+// pageLimit is how many postings are requested per page.
+const pageLimit = 100
+
+// LeverClient reads one company's public Lever job site.
+type LeverClient struct {
+	site     string
+	company  string
+	baseURL  string
+	pageSize int
+	http     *http_client.Client
+}
+
+// NewLeverClient returns a client for the site name in
+// jobs.lever.co/{site}. Lever does not name the employer, so company is
+// used for every posting. A shared hc lets sources share rate limits; nil
+// uses a default client.
+func NewLeverClient(site, company string, hc *http_client.Client) *LeverClient {
+	if hc == nil {
+		hc = http_client.New(http_client.DefaultConfig())
+	}
+	return &LeverClient{
+		site:     site,
+		company:  company,
+		baseURL:  defaultBaseURL,
+		pageSize: pageLimit,
+		http:     hc,
+	}
+}
+
+func (c *LeverClient) Source() domain.JobSource {
+	return domain.Lever
+}
+
+// FetchJobs reads pages until one comes back short.
+func (c *LeverClient) FetchJobs(ctx context.Context) ([]domain.Job, error) {
+	var jobs []domain.Job
+
+	for skip := 0; ; {
+		postings, err := c.getPage(ctx, skip)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch lever site %q at %d: %w", c.site, skip, err)
+		}
+
+		for _, posting := range postings {
+			jobs = append(jobs, MapJob(posting, c.company))
+		}
+
+		if len(postings) < c.pageSize {
+			return jobs, nil
+		}
+		skip += len(postings)
+	}
+}
+
+func (c *LeverClient) getPage(ctx context.Context, skip int) (responses.LeverResponse, error) {
+	params := url.Values{
+		"mode":  {"json"},
+		"skip":  {strconv.Itoa(skip)},
+		"limit": {strconv.Itoa(c.pageSize)},
+	}
+	endpoint := c.baseURL + "/v0/postings/" + url.PathEscape(c.site) + "?" + params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	var page responses.LeverResponse
+	if err := c.http.GetJSON(req, &page); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// MapJob converts a Lever posting to a domain job. The description is put
+// back together from its opening, lists and closing sections.
+func MapJob(posting responses.LeverPosting, company string) domain.Job {
+	cat := posting.Categories
+
+	job := domain.Job{
+		ExternalID:      posting.ID,
+		Title:           strings.TrimSpace(posting.Text),
+		DescriptionHTML: descriptionHTML(posting),
+		Company:         company,
+		Department:      cat.Department,
+		URL:             posting.HostedURL,
+		Source:          domain.Lever,
+		TypeText:        typeText(cat.Commitment, posting.WorkplaceType),
+		Location:        domain.Location{Raw: strings.TrimSpace(cat.Location)},
+	}
+	if job.Department == "" {
+		job.Department = cat.Team
+	}
+	if len(posting.Country) == 2 {
+		job.Location.CountryCode = strings.ToUpper(posting.Country)
+	}
+
+	if r := posting.SalaryRange; r != nil {
+		job.Salary, _ = salary.FromRange(r.Min, r.Max, r.Currency, intervalUnit(r.Interval))
+	}
+
+	return job
+}
+
+func descriptionHTML(posting responses.LeverPosting) string {
+	var b strings.Builder
+	b.WriteString(posting.Description)
+	for _, list := range posting.Lists {
+		b.WriteString("<h3>" + html.EscapeString(list.Text) + "</h3><ul>" + list.Content + "</ul>")
+	}
+	b.WriteString(posting.Additional)
+	return b.String()
+}
+
+// typeText joins the commitment, e.g. "Full-time", with the workplace type
+// unless Lever left it unspecified.
+func typeText(commitment, workplace string) string {
+	var parts []string
+	if commitment != "" {
+		parts = append(parts, commitment)
+	}
+	if workplace != "" && workplace != "unspecified" {
+		parts = append(parts, workplace)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// intervalUnit turns a Lever interval such as "per-year-salary" into a
+// schema.org unit, "YEAR". One-time amounts have no unit.
+func intervalUnit(interval string) string {
+	parts := strings.Split(interval, "-")
+	if len(parts) < 2 || parts[0] != "per" {
+		return ""
+	}
+	return strings.ToUpper(parts[1])
+}
@@ -0,0 +1,118 @@
+package lever_client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"htmxjb/clients/fixtures"
+	"htmxjb/clients/http_client"
+	"htmxjb/models/domain"
+	"htmxjb/models/responses"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newReplayClient returns a client for the site the checked-in fixture
+// was recorded from. The postings API needs no key.
+func newReplayClient(t *testing.T) (*LeverClient, *fixtures.Recorder) {
+	t.Helper()
+
+	rec := fixtures.New(t, "hypermedia")
+	return NewLeverClient("hypermedia", "Hypermedia Co", http_client.New(http_client.Config{MaxRetries: 0, Transport: rec})), rec
+}
+
+// TestContract checks the recorded postings against LeverResponse. Run
+// with RECORD_FIXTURES=1 to re-record.
+func TestContract(t *testing.T) {
+	c, rec := newReplayClient(t)
+
+	_, err := c.FetchJobs(context.Background())
+	require.NoError(t, err)
+
+	for i := range rec.Interactions() {
+		fixtures.RequireFields(t, rec.ResponseBody(i), responses.LeverResponse{})
+	}
+}
+
+func TestFetchJobs(t *testing.T) {
+	if fixtures.Recording() {
+		t.Skip("asserts on the checked-in fixture")
+	}
+	c, _ := newReplayClient(t)
+
+	jobs, err := c.FetchJobs(context.Background())
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+
+	backend := jobs[0]
+	assert.Equal(t, "5ac21346-8e0c-4494-8e7a-3eb92ff77902", backend.ExternalID)
+	assert.Equal(t, "Backend Engineer (Go)", backend.Title)
+	assert.Equal(t, "Hypermedia Co", backend.Company)
+	assert.Equal(t, "Engineering", backend.Department)
+	assert.Equal(t, domain.Lever, backend.Source)
+	assert.Equal(t, "Full-time, hybrid", backend.TypeText)
+	assert.Equal(t, domain.Location{Raw: "Berlin", CountryCode: "DE"}, backend.Location)
+	assert.Equal(t, "EUR 70,000 - 90,000 per year", backend.Salary.Raw)
+	assert.Equal(t,
+		"<div>Build <b>Go</b> services.</div>"+
+			"<h3>What you&#39;ll do</h3><ul><li>Ship features</li><li>Review code</li></ul>"+
+			"<div>We offer a learning budget.</div>",
+		backend.DescriptionHTML)
+
+	// Without a department the team is used, and an unspecified
+	// workplace is left out of the type.
+	designer := jobs[1]
+	assert.Equal(t, "Design", designer.Department)
+	assert.Equal(t, "Contract", designer.TypeText)
+	assert.Equal(t, domain.Location{Raw: "Remote - Europe"}, designer.Location)
+	assert.Empty(t, designer.Salary.Raw)
+}
+
+func TestFetchJobsPaginates(t *testing.T) {
+	var skips []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v0/postings/hypermedia", r.URL.Path)
+		assert.Equal(t, "2", r.URL.Query().Get("limit"))
+		skip := r.URL.Query().Get("skip")
+		skips = append(skips, skip)
+
+		from, _ := strconv.Atoi(skip)
+		var page []map[string]string
+		for i := from; i < 5 && i < from+2; i++ {
+			page = append(page, map[string]string{"id": fmt.Sprint(i)})
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+	defer srv.Close()
+
+	c := NewLeverClient("hypermedia", "Hypermedia Co", http_client.New(http_client.Config{MaxRetries: 0}))
+	c.baseURL = srv.URL
+	c.pageSize = 2
+
+	jobs, err := c.FetchJobs(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, jobs, 5)
+	assert.Equal(t, []string{"0", "2", "4"}, skips)
+}
+
+func TestFetchJobsSiteNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"ok":false,"error":"Document not found"}`, http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	c := NewLeverClient("missing", "", http_client.New(http_client.Config{MaxRetries: 0}))
+	c.baseURL = srv.URL
+
+	_, err := c.FetchJobs(context.Background())
+
+	var statusErr *http_client.StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+	assert.ErrorContains(t, err, `"missing"`)
+}
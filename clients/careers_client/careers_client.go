@@ -0,0 +1,395 @@
+package careers_client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"htmxjb/clients/http_client"
+	"htmxjb/models/domain"
+	"htmxjb/models/responses"
+	"htmxjb/services/salary"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/andybalholm/cascadia"
+	xhtml "golang.org/x/net/html"
+)
+
+const DefaultUserAgent = "htmxjb-bot/1.0"
+
+var ldJSON = cascadia.MustCompile(`script[type="application/ld+json"]`)
+
+// Page is a careers page to scrape. Company names postings that do not
+// name their hiring organization.
+type Page struct {
+	Name      string
+	URL       string
+	Company   string
+	Selectors *Selectors
+}
+
+// Selectors find postings with CSS on pages that have no JobPosting
+// JSON-LD. Job matches one element per posting; the other selectors are
+// matched inside it and may be empty, except Title.
+type Selectors struct {
+	Job         string
+	Title       string
+	Link        string
+	Location    string
+	Description string
+	Type        string
+}
+
+// CareersClient scrapes company careers pages for schema.org JobPosting
+// JSON-LD. It honours robots.txt and waits the larger of its own delay and
+// the site's Crawl-delay between requests to one host.
+type CareersClient struct {
+	pages     []Page
+	http      *http_client.Client
+	userAgent string
+	delay     time.Duration
+
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// host is what one fetch knows about a site.
+type host struct {
+	robots robotsRules
+	last   time.Time
+}
+
+// NewCareersClient returns a client for pages. A shared hc lets sources
+// share rate limits; nil uses a default client.
+func NewCareersClient(pages []Page, hc *http_client.Client) *CareersClient {
+	if hc == nil {
+		hc = http_client.New(http_client.DefaultConfig())
+	}
+	return &CareersClient{
+		pages:     pages,
+		http:      hc,
+		userAgent: DefaultUserAgent,
+		delay:     2 * time.Second,
+		now:       time.Now,
+		sleep:     sleepContext,
+	}
+}
+
+// WithCrawling sets the User-Agent sent to sites and the minimum delay
+// between requests to one host. Empty values keep the defaults.
+func (c *CareersClient) WithCrawling(userAgent string, delay time.Duration) *CareersClient {
+	if userAgent != "" {
+		c.userAgent = userAgent
+	}
+	if delay > 0 {
+		c.delay = delay
+	}
+	return c
+}
+
+func (c *CareersClient) Source() domain.JobSource {
+	return domain.CareerPage
+}
+
+// FetchJobs scrapes every page. Pages robots.txt disallows are skipped;
+// any other failure fails the fetch, so jobs from a page that is briefly
+// down are not closed.
+func (c *CareersClient) FetchJobs(ctx context.Context) ([]domain.Job, error) {
+	hosts := make(map[string]*host)
+
+	var jobs []domain.Job
+	for _, page := range c.pages {
+		pageJobs, err := c.scrape(ctx, hosts, page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scrape careers page %q: %w", page.Name, err)
+		}
+		jobs = append(jobs, pageJobs...)
+	}
+
+	return jobs, nil
+}
+
+func (c *CareersClient) scrape(ctx context.Context, hosts map[string]*host, page Page) ([]domain.Job, error) {
+	pageURL, err := url.Parse(page.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+
+	h, ok := hosts[pageURL.Host]
+	if !ok {
+		h = &host{}
+		robots, err := c.get(ctx, h, pageURL.ResolveReference(&url.URL{Path: "/robots.txt"}))
+		var statusErr *http_client.StatusError
+		switch {
+		case err == nil:
+			h.robots = parseRobots(robots, productToken(c.userAgent))
+		case errors.As(err, &statusErr) && statusErr.StatusCode >= 400 && statusErr.StatusCode < 500:
+			// No robots.txt: everything is allowed.
+		default:
+			return nil, fmt.Errorf("failed to read robots.txt: %w", err)
+		}
+		hosts[pageURL.Host] = h
+	}
+
+	if !h.robots.Allowed(pageURL.RequestURI()) {
+		log.Printf("🔥 robots.txt disallows %s, skipping careers page %q", page.URL, page.Name)
+		return nil, nil
+	}
+
+	body, err := c.get(ctx, h, pageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := xhtml.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse page: %w", err)
+	}
+
+	var jobs []domain.Job
+	for _, posting := range findPostings(doc) {
+		jobs = append(jobs, MapJob(posting, page))
+	}
+	if len(jobs) == 0 && page.Selectors != nil {
+		jobs, err = selectJobs(doc, pageURL, page)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(jobs) == 0 {
+		log.Printf("🔥 no postings found on careers page %q", page.Name)
+	}
+
+	return jobs, nil
+}
+
+// get fetches u once the host's crawl delay has passed.
+func (c *CareersClient) get(ctx context.Context, h *host, u *url.URL) ([]byte, error) {
+	if !h.last.IsZero() {
+		wait := h.last.Add(max(c.delay, h.robots.delay)).Sub(c.now())
+		if err := c.sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+	defer func() { h.last = c.now() }()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+
+	return c.http.GetBytes(req)
+}
+
+// findPostings collects every JobPosting in the page's JSON-LD, including
+// ones inside lists and @graph.
+func findPostings(doc *xhtml.Node) []responses.JobPosting {
+	var postings []responses.JobPosting
+
+	for _, script := range cascadia.QueryAll(doc, ldJSON) {
+		var data interface{}
+		if err := json.Unmarshal([]byte(textContent(script)), &data); err != nil {
+			log.Printf("🔥 invalid JSON-LD: %s", err)
+			continue
+		}
+
+		for _, item := range jobPostingItems(data) {
+			raw, err := json.Marshal(item)
+			if err != nil {
+				continue
+			}
+			var posting responses.JobPosting
+			if err := json.Unmarshal(raw, &posting); err != nil {
+				log.Printf("🔥 invalid JobPosting JSON-LD: %s", err)
+				continue
+			}
+			postings = append(postings, posting)
+		}
+	}
+
+	return postings
+}
+
+func jobPostingItems(data interface{}) []map[string]interface{} {
+	switch v := data.(type) {
+	case []interface{}:
+		var items []map[string]interface{}
+		for _, item := range v {
+			items = append(items, jobPostingItems(item)...)
+		}
+		return items
+
+	case map[string]interface{}:
+		if isJobPosting(v["@type"]) {
+			return []map[string]interface{}{v}
+		}
+		return jobPostingItems(v["@graph"])
+	}
+
+	return nil
+}
+
+func isJobPosting(t interface{}) bool {
+	switch v := t.(type) {
+	case string:
+		return v == "JobPosting"
+	case []interface{}:
+		for _, item := range v {
+			if isJobPosting(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// MapJob converts a JobPosting found on page to a domain job.
+func MapJob(posting responses.JobPosting, page Page) domain.Job {
+	posting.URL = resolveURL(page.URL, posting.URL)
+
+	job := domain.Job{
+		ExternalID:      externalID(posting, page),
+		Title:           strings.TrimSpace(html.UnescapeString(posting.Title)),
+		DescriptionHTML: descriptionHTML(posting.Description),
+		Company:         posting.HiringOrganization.Name,
+		URL:             posting.URL,
+		Source:          domain.CareerPage,
+		TypeText:        strings.Join(posting.EmploymentType, ", "),
+		Location:        mapLocation(posting),
+	}
+	if job.Company == "" {
+		job.Company = page.Company
+	}
+	if org := posting.HiringOrganization; org.Name != "" {
+		job.CompanyWebsite = resolveURL(page.URL, org.URL)
+		if job.CompanyWebsite == "" {
+			job.CompanyWebsite = resolveURL(page.URL, org.SameAs)
+		}
+		job.CompanyLogo = resolveURL(page.URL, org.Logo.URL)
+	}
+	if job.URL == "" {
+		job.URL = page.URL
+	}
+
+	if posting.BaseSalary != nil {
+		v := posting.BaseSalary.Value
+		lo, hi := float64(v.MinValue), float64(v.MaxValue)
+		if lo == 0 && hi == 0 {
+			lo, hi = float64(v.Value), float64(v.Value)
+		}
+		job.Salary, _ = salary.FromRange(lo, hi, posting.BaseSalary.Currency, v.UnitText)
+	}
+
+	return job
+}
+
+// externalID prefers the posting URL, then the site's identifier scoped to
+// the page's host, then the title, so IDs stay unique across companies.
+func externalID(posting responses.JobPosting, page Page) string {
+	switch {
+	case posting.URL != "":
+		return posting.URL
+	case posting.Identifier != "":
+		u, err := url.Parse(page.URL)
+		if err == nil {
+			return u.Host + "#" + string(posting.Identifier)
+		}
+		return page.URL + "#" + string(posting.Identifier)
+	default:
+		return page.URL + "#" + slug(posting.Title)
+	}
+}
+
+// descriptionHTML undoes the entity encoding some sites apply to the
+// description markup. The description enricher sanitizes it.
+func descriptionHTML(description string) string {
+	if !strings.Contains(description, "<") && strings.Contains(description, "&lt;") {
+		return html.UnescapeString(description)
+	}
+	return description
+}
+
+func mapLocation(posting responses.JobPosting) domain.Location {
+	loc := domain.Location{Remote: posting.JobLocationType.Has("TELECOMMUTE")}
+	if len(posting.JobLocation) == 0 {
+		return loc
+	}
+
+	place := posting.JobLocation[0]
+	addr := place.Address
+	country := strings.TrimSpace(string(addr.AddressCountry))
+
+	var parts []string
+	for _, part := range []string{addr.AddressLocality, addr.AddressRegion, country} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	loc.Raw = strings.Join(parts, ", ")
+	loc.City = strings.TrimSpace(addr.AddressLocality)
+	loc.Region = strings.TrimSpace(addr.AddressRegion)
+	if len(country) == 2 {
+		loc.CountryCode = strings.ToUpper(country)
+	}
+	loc.Latitude, loc.Longitude = float64(place.Geo.Latitude), float64(place.Geo.Longitude)
+
+	return loc
+}
+
+// resolveURL resolves a link found on the page at base. Links that do not
+// parse are dropped.
+func resolveURL(base, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ""
+	}
+
+	b, err := url.Parse(base)
+	if err != nil {
+		return ""
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	return b.ResolveReference(r).String()
+}
+
+// productToken is the part of a User-Agent robots.txt groups are matched
+// against, e.g. "htmxjb-bot" for "htmxjb-bot/1.0 (+https://...)".
+func productToken(userAgent string) string {
+	token, _, _ := strings.Cut(userAgent, "/")
+	token, _, _ = strings.Cut(token, " ")
+	return token
+}
+
+func slug(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), "-")
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,230 @@
+package careers_client
+
+import (
+	"context"
+	"htmxjb/clients/http_client"
+	"htmxjb/models/domain"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSiteServer serves robots and the testdata pages, recording every
+// path requested.
+func newSiteServer(t *testing.T, robots string, paths *[]string) *httptest.Server {
+	t.Helper()
+
+	pages := map[string]string{
+		"/careers": "testdata/jsonld.html",
+		"/team":    "testdata/selectors.html",
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*paths = append(*paths, r.URL.Path)
+		assert.Equal(t, "htmxjb-test/2.0 (+https://jobs.example)", r.Header.Get("User-Agent"))
+
+		if r.URL.Path == "/robots.txt" {
+			if robots == "" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write([]byte(robots))
+			return
+		}
+
+		file, ok := pages[r.URL.Path]
+		if !ok {
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+		body, err := os.ReadFile(file)
+		require.NoError(t, err)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+// newTestClient returns a client whose clock stands still, so every wait
+// is a full crawl delay, and which records waits instead of sleeping.
+func newTestClient(pages []Page, sleeps *[]time.Duration) *CareersClient {
+	c := NewCareersClient(pages, http_client.New(http_client.Config{MaxRetries: 0}))
+	c.WithCrawling("htmxjb-test/2.0 (+https://jobs.example)", time.Second)
+
+	now := time.Date(2025, 2, 3, 12, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		*sleeps = append(*sleeps, d)
+		return nil
+	}
+	return c
+}
+
+func TestFetchJobsJSONLD(t *testing.T) {
+	var paths []string
+	srv := newSiteServer(t, "User-agent: *\nDisallow: /private\nCrawl-delay: 5\n", &paths)
+
+	var sleeps []time.Duration
+	c := newTestClient([]Page{
+		{Name: "acme", URL: srv.URL + "/careers", Company: "Acme"},
+		{Name: "private", URL: srv.URL + "/private/jobs"},
+	}, &sleeps)
+
+	jobs, err := c.FetchJobs(context.Background())
+	require.NoError(t, err)
+
+	// robots.txt is read once per host and the disallowed page is skipped.
+	assert.Equal(t, []string{"/robots.txt", "/careers"}, paths)
+	assert.Equal(t, []time.Duration{5 * time.Second}, sleeps)
+
+	require.Len(t, jobs, 2)
+
+	goJob := jobs[0]
+	assert.Equal(t, srv.URL+"/careers/go-42", goJob.ExternalID)
+	assert.Equal(t, srv.URL+"/careers/go-42", goJob.URL)
+	assert.Equal(t, "Senior Go Engineer", goJob.Title)
+	assert.Equal(t, "Acme Cloud", goJob.Company)
+	assert.Equal(t, "https://acme.example", goJob.CompanyWebsite)
+	assert.Equal(t, srv.URL+"/img/acme.png", goJob.CompanyLogo)
+	assert.Equal(t, domain.CareerPage, goJob.Source)
+	assert.Equal(t, "FULL_TIME", goJob.TypeText)
+	assert.Equal(t, "<p>Build <b>Go</b> services for our platform.</p>", goJob.DescriptionHTML)
+	assert.Equal(t, domain.Location{
+		Raw:         "Berlin, Berlin, DE",
+		City:        "Berlin",
+		Region:      "Berlin",
+		CountryCode: "DE",
+		Latitude:    52.52437,
+		Longitude:   13.41053,
+	}, goJob.Location)
+	assert.Equal(t, "EUR 70,000 - 90,000 per year", goJob.Salary.Raw)
+
+	u, _ := url.Parse(srv.URL)
+	support := jobs[1]
+	assert.Equal(t, u.Host+"#1077", support.ExternalID)
+	assert.Equal(t, srv.URL+"/careers", support.URL)
+	assert.Equal(t, "Support Engineer & Writer", support.Title)
+	assert.Equal(t, "Acme Support", support.Company)
+	assert.Equal(t, "<p>Help customers <em>succeed</em>.</p>", support.DescriptionHTML)
+	assert.True(t, support.Location.Remote)
+	assert.Equal(t, "USD 65", support.Salary.Raw)
+}
+
+func TestFetchJobsSelectors(t *testing.T) {
+	var paths []string
+	srv := newSiteServer(t, "", &paths)
+
+	var sleeps []time.Duration
+	c := newTestClient([]Page{{
+		Name:    "team",
+		URL:     srv.URL + "/team",
+		Company: "Hypermedia Co",
+		Selectors: &Selectors{
+			Job:         "li.opening",
+			Title:       "h3",
+			Location:    ".location",
+			Description: ".summary",
+			Type:        ".type",
+		},
+	}}, &sleeps)
+
+	jobs, err := c.FetchJobs(context.Background())
+	require.NoError(t, err)
+
+	// A missing robots.txt allows everything.
+	assert.Equal(t, []string{"/robots.txt", "/team"}, paths)
+	assert.Equal(t, []time.Duration{time.Second}, sleeps)
+
+	require.Len(t, jobs, 2)
+	assert.Equal(t, domain.Job{
+		ExternalID:      srv.URL + "/jobs/frontend",
+		Title:           "Frontend Developer",
+		DescriptionHTML: "<p>Ship <strong>htmx</strong> pages.</p>",
+		Company:         "Hypermedia Co",
+		URL:             srv.URL + "/jobs/frontend",
+		Source:          domain.CareerPage,
+		TypeText:        "Contract",
+		Location:        domain.Location{Raw: "Remote, EU"},
+	}, jobs[0])
+
+	assert.Equal(t, srv.URL+"/team#office-manager", jobs[1].ExternalID)
+	assert.Equal(t, srv.URL+"/team", jobs[1].URL)
+	assert.Equal(t, "Lisbon, Portugal", jobs[1].Location.Raw)
+}
+
+func TestFetchJobsInvalidSelectors(t *testing.T) {
+	var paths []string
+	srv := newSiteServer(t, "", &paths)
+
+	var sleeps []time.Duration
+	c := newTestClient([]Page{{Name: "team", URL: srv.URL + "/team", Selectors: &Selectors{Job: "li[", Title: "h3"}}}, &sleeps)
+
+	_, err := c.FetchJobs(context.Background())
+	assert.ErrorContains(t, err, `invalid selector "li["`)
+}
+
+func TestFetchJobsPageError(t *testing.T) {
+	var paths []string
+	srv := newSiteServer(t, "User-agent: *\nDisallow:\n", &paths)
+
+	var sleeps []time.Duration
+	c := newTestClient([]Page{
+		{Name: "acme", URL: srv.URL + "/careers"},
+		{Name: "broken", URL: srv.URL + "/broken"},
+	}, &sleeps)
+
+	_, err := c.FetchJobs(context.Background())
+
+	var statusErr *http_client.StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusInternalServerError, statusErr.StatusCode)
+	assert.ErrorContains(t, err, `"broken"`)
+}
+
+func TestParseRobots(t *testing.T) {
+	robots := []byte(`# Example
+User-agent: *
+Disallow: /
+
+User-agent: htmxjb-bot
+User-agent: otherbot
+Allow: /careers
+Disallow: /careers/internal
+Disallow: /*.pdf$
+Disallow: /search*q=
+Crawl-delay: 1.5
+
+User-agent: htmxjb
+Disallow: /careers
+`)
+
+	rules := parseRobots(robots, "htmxjb-bot")
+	assert.Equal(t, 1500*time.Millisecond, rules.delay)
+
+	tests := []struct {
+		path    string
+		allowed bool
+	}{
+		{"/careers", true},
+		{"/careers/go", true},
+		{"/careers/internal/go", false},
+		{"/docs/handbook.pdf", false},
+		{"/docs/handbook.pdf?download=1", true},
+		{"/search?lang=en&q=go", false},
+		{"/about", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.allowed, rules.Allowed(tt.path), tt.path)
+	}
+
+	assert.False(t, parseRobots(robots, "somebot").Allowed("/careers"))
+	assert.True(t, parseRobots(nil, "somebot").Allowed("/careers"))
+}
@@ -0,0 +1,146 @@
+package careers_client
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// robotsRules are the robots.txt rules that apply to our user agent.
+type robotsRules struct {
+	allow    []string
+	disallow []string
+	delay    time.Duration
+}
+
+// robotsGroup is one user-agent block of a robots.txt file.
+type robotsGroup struct {
+	agents []string
+	rules  robotsRules
+}
+
+// parseRobots picks the group for agent, the product token of our
+// User-Agent, falling back to the "*" group. Of several matching groups
+// the one with the longest user-agent wins.
+func parseRobots(data []byte, agent string) robotsRules {
+	var groups []*robotsGroup
+	var current *robotsGroup
+	inRules := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// Consecutive user-agent lines share one group.
+			if current == nil || inRules {
+				current = &robotsGroup{}
+				groups = append(groups, current)
+				inRules = false
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+		case "allow", "disallow", "crawl-delay":
+			if current == nil {
+				continue
+			}
+			inRules = true
+			current.rules.add(key, value)
+		}
+	}
+
+	agent = strings.ToLower(agent)
+	var best *robotsGroup
+	bestLen := -1
+	for _, g := range groups {
+		for _, a := range g.agents {
+			n := -1
+			switch {
+			case a == "*":
+				n = 0
+			case a != "" && strings.Contains(agent, a):
+				n = len(a)
+			}
+			if n > bestLen {
+				best, bestLen = g, n
+			}
+		}
+	}
+
+	if best == nil {
+		return robotsRules{}
+	}
+	return best.rules
+}
+
+func (r *robotsRules) add(key, value string) {
+	switch key {
+	case "allow":
+		if value != "" {
+			r.allow = append(r.allow, value)
+		}
+	case "disallow":
+		// An empty Disallow allows everything.
+		if value != "" {
+			r.disallow = append(r.disallow, value)
+		}
+	case "crawl-delay":
+		if secs, err := strconv.ParseFloat(value, 64); err == nil && secs > 0 {
+			r.delay = time.Duration(secs * float64(time.Second))
+		}
+	}
+}
+
+// Allowed reports whether path may be fetched. The longest matching rule
+// wins and Allow wins a tie.
+func (r robotsRules) Allowed(path string) bool {
+	allowLen, disallowLen := longestMatch(r.allow, path), longestMatch(r.disallow, path)
+	return disallowLen < 0 || allowLen >= disallowLen
+}
+
+func longestMatch(patterns []string, path string) int {
+	longest := -1
+	for _, p := range patterns {
+		if len(p) > longest && matchRobots(p, path) {
+			longest = len(p)
+		}
+	}
+	return longest
+}
+
+// matchRobots matches a robots.txt path pattern, where "*" matches any
+// run of characters and a trailing "$" anchors the end of the path.
+func matchRobots(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+
+	for i, part := range parts[1:] {
+		if anchored && i == len(parts)-2 {
+			return strings.HasSuffix(rest, part)
+		}
+		j := strings.Index(rest, part)
+		if j < 0 {
+			return false
+		}
+		rest = rest[j+len(part):]
+	}
+
+	return !anchored || rest == ""
+}
@@ -0,0 +1,157 @@
+package careers_client
+
+import (
+	"fmt"
+	"htmxjb/models/domain"
+	"net/url"
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+	xhtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// compiledSelectors are Selectors ready to match. Optional selectors that
+// were left empty are nil.
+type compiledSelectors struct {
+	job, title, link, location, description, typ cascadia.Sel
+}
+
+func (s Selectors) compile() (compiledSelectors, error) {
+	var c compiledSelectors
+
+	if s.Job == "" || s.Title == "" {
+		return c, fmt.Errorf("job and title selectors are required")
+	}
+
+	for _, sel := range []struct {
+		dst *cascadia.Sel
+		src string
+	}{
+		{&c.job, s.Job},
+		{&c.title, s.Title},
+		{&c.link, s.Link},
+		{&c.location, s.Location},
+		{&c.description, s.Description},
+		{&c.typ, s.Type},
+	} {
+		if sel.src == "" {
+			continue
+		}
+		compiled, err := cascadia.Parse(sel.src)
+		if err != nil {
+			return c, fmt.Errorf("invalid selector %q: %w", sel.src, err)
+		}
+		*sel.dst = compiled
+	}
+
+	return c, nil
+}
+
+// selectJobs finds postings with the page's CSS selectors. Without a Link
+// selector the first link in the posting is used.
+func selectJobs(doc *xhtml.Node, pageURL *url.URL, page Page) ([]domain.Job, error) {
+	sel, err := page.Selectors.compile()
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []domain.Job
+	for _, n := range cascadia.QueryAll(doc, sel.job) {
+		title := normalizeSpace(textContent(cascadia.Query(n, sel.title)))
+		if title == "" {
+			continue
+		}
+
+		link := firstLink(n, sel.link)
+		if link != "" {
+			link = resolveURL(pageURL.String(), link)
+		}
+
+		job := domain.Job{
+			ExternalID:      link,
+			Title:           title,
+			DescriptionHTML: innerHTML(query(n, sel.description)),
+			Company:         page.Company,
+			URL:             link,
+			Source:          domain.CareerPage,
+			TypeText:        normalizeSpace(textContent(query(n, sel.typ))),
+			Location:        domain.Location{Raw: normalizeSpace(textContent(query(n, sel.location)))},
+		}
+		if link == "" {
+			job.ExternalID = page.URL + "#" + slug(title)
+			job.URL = page.URL
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+// firstLink returns the href of the element sel matches in n, or of the
+// first link in n, which may be n itself.
+func firstLink(n *xhtml.Node, sel cascadia.Sel) string {
+	if sel != nil {
+		return attr(cascadia.Query(n, sel), "href")
+	}
+	if n.DataAtom == atom.A {
+		return attr(n, "href")
+	}
+	return attr(cascadia.Query(n, cascadia.MustCompile("a[href]")), "href")
+}
+
+func query(n *xhtml.Node, sel cascadia.Sel) *xhtml.Node {
+	if sel == nil {
+		return nil
+	}
+	return cascadia.Query(n, sel)
+}
+
+func attr(n *xhtml.Node, name string) string {
+	if n == nil {
+		return ""
+	}
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func textContent(n *xhtml.Node) string {
+	if n == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	var walk func(*xhtml.Node)
+	walk = func(n *xhtml.Node) {
+		if n.Type == xhtml.TextNode {
+			b.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+
+	return b.String()
+}
+
+func innerHTML(n *xhtml.Node) string {
+	if n == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		xhtml.Render(&b, c)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -1,17 +1,49 @@
 package csv_client
 
 import (
+	"context"
 	"encoding/csv"
+	"errors"
 	"fmt"
+	"htmxjb/models/domain"
+	"io"
+	"log"
 	"os"
 	"sync"
 )
 
+// A job feed row has external_id, title, description, company, location
+// and url columns, with the job type text in the optional eighth column.
+const minColumns = 6
+
 type CSVparser interface {
-	ParseCSV(filePath string, hasHeader bool, numWorkers int) ([]Job, error)
+	ParseCSV(filePath string, hasHeader bool, numWorkers int) ([]domain.Job, error)
+}
+
+// CSVClient reads a job feed file on every ingest.
+type CSVClient struct {
+	filePath   string
+	hasHeader  bool
+	numWorkers int
 }
 
-func ParseCSV(filePath string, hasHeader bool, numWorkers int) ([]Job, error) {
+func NewCSVClient(filePath string, hasHeader bool, numWorkers int) *CSVClient {
+	return &CSVClient{
+		filePath:   filePath,
+		hasHeader:  hasHeader,
+		numWorkers: numWorkers,
+	}
+}
+
+func (c *CSVClient) Source() domain.JobSource {
+	return domain.Csv
+}
+
+func (c *CSVClient) FetchJobs(ctx context.Context) ([]domain.Job, error) {
+	return ParseCSV(c.filePath, c.hasHeader, c.numWorkers)
+}
+
+func ParseCSV(filePath string, hasHeader bool, numWorkers int) ([]domain.Job, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("ERROR while open file: %v", err)
@@ -19,6 +51,7 @@ func ParseCSV(filePath string, hasHeader bool, numWorkers int) ([]Job, error) {
 	defer file.Close()
 
 	csvReader := csv.NewReader(file)
+	csvReader.FieldsPerRecord = -1
 
 	if hasHeader {
 		if _, err := csvReader.Read(); err != nil {
@@ -27,8 +60,8 @@ func ParseCSV(filePath string, hasHeader bool, numWorkers int) ([]Job, error) {
 	}
 
 	rowsChan := make(chan []string)
-	jobsChan := make(chan Job)
-	errChan := make(chan error)
+	jobsChan := make(chan domain.Job)
+	errChan := make(chan error, 1)
 	doneChan := make(chan struct{})
 
 	var wg sync.WaitGroup
@@ -39,11 +72,11 @@ func ParseCSV(filePath string, hasHeader bool, numWorkers int) ([]Job, error) {
 	}
 
 	go func() {
+		defer close(rowsChan)
 		for {
 			record, err := csvReader.Read()
 			if err != nil {
-				if err.Error() == "EOF" {
-					close(rowsChan)
+				if errors.Is(err, io.EOF) {
 					return
 				}
 				errChan <- fmt.Errorf("ERROR while reading CSV: %v", err)
@@ -53,7 +86,7 @@ func ParseCSV(filePath string, hasHeader bool, numWorkers int) ([]Job, error) {
 		}
 	}()
 
-	var jobs []Job
+	var jobs []domain.Job
 
 	go func() {
 		for job := range jobsChan {
@@ -67,31 +100,38 @@ func ParseCSV(filePath string, hasHeader bool, numWorkers int) ([]Job, error) {
 		close(jobsChan)
 	}()
 
+	<-doneChan
+
 	select {
 	case err := <-errChan:
 		return nil, err
-	case <-doneChan:
+	default:
 		return jobs, nil
 	}
 }
 
-func processRows(rows <-chan []string, jobs chan<- Job, errChan chan<- error, wg *sync.WaitGroup) {
+func processRows(rows <-chan []string, jobs chan<- domain.Job, errChan chan<- error, wg *sync.WaitGroup) {
 	defer wg.Done()
 
 	for row := range rows {
-		job := Job{
+		if len(row) < minColumns {
+			log.Printf("🔥 skipping CSV row with %d columns: %v", len(row), row)
+			continue
+		}
+
+		job := domain.Job{
 			ExternalID:  row[0],
 			Title:       row[1],
 			Description: row[2],
 			Company:     row[3],
-			Location:    row[4],
+			Location:    domain.Location{Raw: row[4]},
 			URL:         row[5],
-			Source:      3,
-			Type:        "full-time",
+			Source:      domain.Csv,
+			TypeText:    "full-time",
 		}
 
 		if len(row) > 7 {
-			job.Type = row[7]
+			job.TypeText = row[7]
 		}
 
 		jobs <- job
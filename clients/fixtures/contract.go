@@ -0,0 +1,115 @@
+package fixtures
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// RequireFields is the contract check for a recorded response: body must
+// decode into v's type, and every JSON field v declares must be present in
+// every object of body, nested ones included. Fields tagged omitempty are
+// optional. A renamed or removed upstream field fails the test instead of
+// silently decoding to a zero value.
+func RequireFields(t testing.TB, body json.RawMessage, v interface{}) {
+	t.Helper()
+
+	typ := reflect.TypeOf(v)
+	if err := json.Unmarshal(body, reflect.New(typ).Interface()); err != nil {
+		t.Errorf("response does not decode into %s: %v", typ, err)
+		return
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		t.Errorf("response is not JSON: %v", err)
+		return
+	}
+
+	for _, missing := range missingFields(typ, doc, "$") {
+		t.Errorf("response is missing %s", missing)
+	}
+}
+
+// ResponseBody returns the body of the i-th interaction, failing the test
+// if there is none.
+func (r *Recorder) ResponseBody(i int) json.RawMessage {
+	r.t.Helper()
+
+	interactions := r.Interactions()
+	if i >= len(interactions) {
+		r.t.Fatalf("no interaction %d in %s", i, r.path)
+	}
+	return interactions[i].Response.Body
+}
+
+func missingFields(typ reflect.Type, doc interface{}, path string) []string {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if doc == nil {
+		return nil
+	}
+
+	switch typ.Kind() {
+	case reflect.Slice, reflect.Array:
+		items, _ := doc.([]interface{})
+		var missing []string
+		for i, item := range items {
+			missing = append(missing, missingFields(typ.Elem(), item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return missing
+
+	case reflect.Struct:
+		obj, ok := doc.(map[string]interface{})
+		if !ok {
+			// Structs decoded from strings, like time.Time.
+			return nil
+		}
+
+		var missing []string
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name, optional := jsonField(f)
+			if name == "-" {
+				continue
+			}
+
+			value, ok := obj[name]
+			if !ok {
+				if !optional {
+					missing = append(missing, path+"."+name)
+				}
+				continue
+			}
+			missing = append(missing, missingFields(f.Type, value, path+"."+name)...)
+		}
+		return missing
+	}
+
+	return nil
+}
+
+func jsonField(f reflect.StructField) (name string, optional bool) {
+	tag := f.Tag.Get("json")
+	if tag == "" {
+		return f.Name, false
+	}
+
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = f.Name
+	}
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			optional = true
+		}
+	}
+	return name, optional
+}
@@ -0,0 +1,319 @@
+// Package fixtures records source client traffic to golden files and
+// replays it in tests. Recordings live in the client's
+// testdata/fixtures directory; re-record them against the live APIs with
+//
+//	RECORD_FIXTURES=1 RAPIDAPI_KEY=... go test ./clients/...
+package fixtures
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// RecordEnv switches every Recorder to record mode when set.
+const RecordEnv = "RECORD_FIXTURES"
+
+const redacted = "REDACTED"
+
+// keptHeaders are the response headers saved with a recording. Everything
+// else, cookies included, is dropped.
+var keptHeaders = []string{
+	"Content-Type",
+	"Retry-After",
+	"X-RateLimit-Requests-Limit",
+	"X-RateLimit-Requests-Remaining",
+	"X-RateLimit-Requests-Reset",
+}
+
+var secretParam = regexp.MustCompile(`(?i)(key|token|secret|password)`)
+
+// Interaction is one recorded request and its response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+type Request struct {
+	Method string          `json:"method"`
+	URL    string          `json:"url"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// Response keeps JSON bodies as JSON so golden files diff well; other
+// bodies go in BodyText.
+type Response struct {
+	Status   int               `json:"status"`
+	Header   map[string]string `json:"header,omitempty"`
+	Body     json.RawMessage   `json:"body,omitempty"`
+	BodyText string            `json:"body_text,omitempty"`
+}
+
+// Recorder is an http.RoundTripper that replays interactions from a golden
+// file in order, or in record mode sends requests upstream and writes what
+// it saw to the golden file when the test ends.
+type Recorder struct {
+	t         testing.TB
+	path      string
+	recording bool
+	upstream  http.RoundTripper
+	secrets   []string
+
+	mu           sync.Mutex
+	interactions []Interaction
+	next         int
+}
+
+// New returns a recorder for testdata/fixtures/<name>.json. Secrets, such
+// as API keys, are replaced in everything that gets recorded.
+func New(t testing.TB, name string, secrets ...string) *Recorder {
+	t.Helper()
+
+	return newRecorder(t, filepath.Join("testdata", "fixtures", name+".json"), secrets...)
+}
+
+func newRecorder(t testing.TB, path string, secrets ...string) *Recorder {
+	t.Helper()
+
+	r := &Recorder{
+		t:         t,
+		path:      path,
+		recording: Recording(),
+		upstream:  http.DefaultTransport,
+	}
+	for _, s := range secrets {
+		if s != "" {
+			r.secrets = append(r.secrets, s)
+		}
+	}
+
+	if r.recording {
+		t.Cleanup(r.save)
+		return r
+	}
+
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		t.Fatalf("missing fixture %s, record it with %s=1: %v", r.path, RecordEnv, err)
+	}
+	if err := json.Unmarshal(data, &r.interactions); err != nil {
+		t.Fatalf("invalid fixture %s: %v", r.path, err)
+	}
+	t.Cleanup(r.checkUsed)
+
+	return r
+}
+
+// Recording reports whether fixtures are being recorded in this run.
+func Recording() bool {
+	return os.Getenv(RecordEnv) != ""
+}
+
+// APIKey returns the real key from env when recording, skipping the test
+// if it is not set, and a placeholder when replaying.
+func APIKey(t testing.TB, env string) string {
+	t.Helper()
+
+	if !Recording() {
+		return "test-api-key"
+	}
+	key := os.Getenv(env)
+	if key == "" {
+		t.Skipf("%s is not set, cannot record", env)
+	}
+	return key
+}
+
+// Interactions returns the recorded or replayed interactions.
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Interaction(nil), r.interactions...)
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	recorded := Request{
+		Method: req.Method,
+		URL:    r.scrubURL(req.URL),
+		Body:   r.scrubJSON(reqBody),
+	}
+
+	if r.recording {
+		return r.record(req, recorded)
+	}
+	return r.replay(req, recorded)
+}
+
+func (r *Recorder) record(req *http.Request, recorded Request) (*http.Response, error) {
+	res, err := r.upstream.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	saved := Response{Status: res.StatusCode, Header: make(map[string]string)}
+	for _, h := range keptHeaders {
+		if v := res.Header.Get(h); v != "" {
+			saved.Header[h] = v
+		}
+	}
+	if json.Valid(body) {
+		saved.Body = r.scrubJSON(body)
+	} else {
+		saved.BodyText = r.scrub(string(body))
+	}
+
+	r.mu.Lock()
+	r.interactions = append(r.interactions, Interaction{Request: recorded, Response: saved})
+	r.mu.Unlock()
+
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	return res, nil
+}
+
+func (r *Recorder) replay(req *http.Request, got Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.next >= len(r.interactions) {
+		r.t.Errorf("unexpected request %s %s: all %d recorded interactions used", got.Method, got.URL, len(r.interactions))
+		return nil, fmt.Errorf("no recorded interaction for %s %s", got.Method, got.URL)
+	}
+
+	want := r.interactions[r.next]
+	r.next++
+
+	if got.Method != want.Request.Method || got.URL != want.Request.URL || !sameJSON(got.Body, want.Request.Body) {
+		r.t.Errorf("request %d does not match fixture %s:\n got: %s %s %s\nwant: %s %s %s",
+			r.next, r.path, got.Method, got.URL, got.Body, want.Request.Method, want.Request.URL, want.Request.Body)
+		return nil, fmt.Errorf("request does not match fixture")
+	}
+
+	header := make(http.Header)
+	for k, v := range want.Response.Header {
+		header.Set(k, v)
+	}
+
+	body := []byte(want.Response.BodyText)
+	if len(want.Response.Body) > 0 {
+		body = want.Response.Body
+	}
+
+	return &http.Response{
+		StatusCode: want.Response.Status,
+		Status:     fmt.Sprintf("%d %s", want.Response.Status, http.StatusText(want.Response.Status)),
+		Header:     header,
+		Body:       io.NopCloser(bytes.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func (r *Recorder) checkUsed() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.t.Failed() && r.next < len(r.interactions) {
+		r.t.Errorf("only %d of %d interactions in %s were used", r.next, len(r.interactions), r.path)
+	}
+}
+
+func (r *Recorder) save() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, err := json.MarshalIndent(r.interactions, "", "  ")
+	if err != nil {
+		r.t.Errorf("failed to encode fixture: %v", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		r.t.Errorf("failed to create fixture dir: %v", err)
+		return
+	}
+	if err := os.WriteFile(r.path, append(data, '\n'), 0o644); err != nil {
+		r.t.Errorf("failed to write fixture: %v", err)
+	}
+}
+
+// scrubURL redacts secrets and query parameters that look like
+// credentials. Parameters are sorted so the recording is stable.
+func (r *Recorder) scrubURL(u *url.URL) string {
+	scrubbed := *u
+	q := scrubbed.Query()
+	for name := range q {
+		if secretParam.MatchString(name) {
+			q.Set(name, redacted)
+		}
+	}
+	scrubbed.RawQuery = q.Encode()
+	return r.scrub(scrubbed.String())
+}
+
+func (r *Recorder) scrubJSON(body []byte) json.RawMessage {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, body, "", "  "); err != nil {
+		quoted, _ := json.Marshal(r.scrub(string(body)))
+		return quoted
+	}
+	return json.RawMessage(r.scrub(buf.String()))
+}
+
+func (r *Recorder) scrub(s string) string {
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	return s
+}
+
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
+
+func sameJSON(a, b json.RawMessage) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return bytes.Equal(a, b)
+	}
+
+	ja, _ := json.Marshal(va)
+	jb, _ := json.Marshal(vb)
+	return bytes.Equal(ja, jb)
+}
@@ -0,0 +1,199 @@
+package fixtures
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTB records failures instead of failing the enclosing test.
+type fakeTB struct {
+	testing.TB
+	errors []string
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeTB) Failed() bool {
+	return len(f.errors) > 0
+}
+
+func writeFixture(t *testing.T, interactions []Interaction) string {
+	t.Helper()
+
+	data, err := json.Marshal(interactions)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+	return path
+}
+
+func TestRecordScrubsSecrets(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=abc")
+		w.Header().Set("X-RateLimit-Requests-Remaining", "99")
+		fmt.Fprintf(w, `{"echo": %q}`, r.Header.Get("x-rapidapi-key"))
+	}))
+	defer srv.Close()
+
+	t.Setenv(RecordEnv, "1")
+	path := filepath.Join(t.TempDir(), "fixtures", "recorded.json")
+
+	t.Run("record", func(t *testing.T) {
+		rec := newRecorder(t, path, "s3cret")
+		client := &http.Client{Transport: rec}
+
+		req, err := http.NewRequest(http.MethodPost, srv.URL+"/jobs?q=go&api_key=s3cret", strings.NewReader(`{"key":"s3cret"}`))
+		require.NoError(t, err)
+		req.Header.Set("x-rapidapi-key", "s3cret")
+
+		res, err := client.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		// The caller still sees the real response.
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"echo": "s3cret"}`, string(body))
+	})
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "s3cret")
+	assert.NotContains(t, string(data), "session=abc")
+
+	var interactions []Interaction
+	require.NoError(t, json.Unmarshal(data, &interactions))
+	require.Len(t, interactions, 1)
+	assert.Equal(t, srv.URL+"/jobs?api_key=REDACTED&q=go", interactions[0].Request.URL)
+	assert.JSONEq(t, `{"key": "REDACTED"}`, string(interactions[0].Request.Body))
+	assert.JSONEq(t, `{"echo": "REDACTED"}`, string(interactions[0].Response.Body))
+	assert.Equal(t, "99", interactions[0].Response.Header["X-RateLimit-Requests-Remaining"])
+}
+
+func TestReplay(t *testing.T) {
+	path := writeFixture(t, []Interaction{{
+		Request: Request{Method: http.MethodGet, URL: "https://api.example.com/jobs?q=go"},
+		Response: Response{
+			Status: http.StatusOK,
+			Header: map[string]string{"Content-Type": "application/json"},
+			Body:   json.RawMessage(`[{"id": "1"}]`),
+		},
+	}})
+
+	client := &http.Client{Transport: newRecorder(t, path)}
+
+	res, err := client.Get("https://api.example.com/jobs?q=go")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "application/json", res.Header.Get("Content-Type"))
+	assert.JSONEq(t, `[{"id": "1"}]`, string(body))
+}
+
+func TestReplayFailsOnMismatch(t *testing.T) {
+	path := writeFixture(t, []Interaction{{
+		Request:  Request{Method: http.MethodPost, URL: "https://api.example.com/job", Body: json.RawMessage(`{"query": "go"}`)},
+		Response: Response{Status: http.StatusOK, Body: json.RawMessage(`{}`)},
+	}})
+
+	tests := []struct {
+		name string
+		url  string
+		body string
+	}{
+		{name: "url", url: "https://api.example.com/other", body: `{"query": "go"}`},
+		{name: "body", url: "https://api.example.com/job", body: `{"query": "rust"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := &fakeTB{TB: t}
+			client := &http.Client{Transport: newRecorder(tb, path)}
+
+			_, err := client.Post(tt.url, "application/json", strings.NewReader(tt.body))
+			assert.Error(t, err)
+			assert.Len(t, tb.errors, 1)
+		})
+	}
+
+	t.Run("extra request", func(t *testing.T) {
+		tb := &fakeTB{TB: t}
+		client := &http.Client{Transport: newRecorder(tb, path)}
+
+		_, err := client.Post("https://api.example.com/job", "application/json", strings.NewReader(`{"query": "go"}`))
+		require.NoError(t, err)
+		_, err = client.Post("https://api.example.com/job", "application/json", strings.NewReader(`{"query": "go"}`))
+		assert.Error(t, err)
+		assert.Len(t, tb.errors, 1)
+	})
+}
+
+type contractJob struct {
+	ID       string            `json:"id"`
+	Salary   *contractSalary   `json:"salary"`
+	Places   []contractPlace   `json:"places"`
+	Note     string            `json:"note,omitempty"`
+	Ignored  string            `json:"-"`
+	Extra    map[string]string `json:"extra,omitempty"`
+	internal string
+}
+
+type contractSalary struct {
+	Min float64 `json:"min"`
+}
+
+type contractPlace struct {
+	City string `json:"city"`
+}
+
+func TestMissingFields(t *testing.T) {
+	var doc interface{}
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"id": "1", "salary": null, "places": [{"city": "Berlin"}]},
+		{"identifier": "2", "salary": {"minimum": 10}, "places": [{"town": "Paris"}]}
+	]`), &doc))
+
+	missing := missingFields(reflect.TypeOf([]contractJob{}), doc, "$")
+	assert.Equal(t, []string{"$[1].id", "$[1].salary.min", "$[1].places[0].city"}, missing)
+}
+
+func TestRequireFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		errors int
+	}{
+		{name: "complete", body: `[{"id": "1", "salary": {"min": 1}, "places": [], "unknown": true}]`},
+		{name: "missing field", body: `[{"id": "1", "places": []}]`, errors: 1},
+		{name: "changed type", body: `[{"id": 1, "salary": null, "places": []}]`, errors: 1},
+		{name: "object instead of array", body: `{"jobs": []}`, errors: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := &fakeTB{TB: t}
+			RequireFields(tb, json.RawMessage(tt.body), []contractJob{})
+			assert.Len(t, tb.errors, tt.errors, tb.errors)
+		})
+	}
+}
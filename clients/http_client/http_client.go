@@ -0,0 +1,291 @@
+package http_client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Config tunes a Client. Zero timeouts and backoffs fall back to
+// DefaultConfig; a zero RateInterval turns rate limiting off.
+type Config struct {
+	// Timeout bounds a single attempt, including reading the body.
+	Timeout time.Duration
+	// MaxRetries is how many times a failed request is retried.
+	MaxRetries  int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// MaxRetryAfter is the longest Retry-After the client waits for; a
+	// server asking for more gets its response returned instead.
+	MaxRetryAfter time.Duration
+	// RateInterval is the minimum time between requests to one host, with
+	// Burst requests allowed at once. Zero disables rate limiting.
+	RateInterval time.Duration
+	Burst        int
+	// Transport is used instead of http.DefaultTransport, mainly by tests.
+	Transport http.RoundTripper
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Timeout:       30 * time.Second,
+		MaxRetries:    3,
+		BaseBackoff:   500 * time.Millisecond,
+		MaxBackoff:    30 * time.Second,
+		MaxRetryAfter: 2 * time.Minute,
+		RateInterval:  200 * time.Millisecond,
+		Burst:         1,
+	}
+}
+
+// Client is an HTTP client shared by the source clients. It retries
+// transient failures with jittered exponential backoff, honours
+// Retry-After, rate limits each host and stops calling a host whose
+// RapidAPI quota is used up until it resets.
+type Client struct {
+	cfg  Config
+	http *http.Client
+
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+	quotas   map[string]Quota
+
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// StatusError is returned by GetJSON for non-2xx responses.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("received non-2xx response: %d, body: %s", e.StatusCode, e.Body)
+}
+
+var ErrQuotaExhausted = errors.New("API quota exhausted")
+
+// bodyPreviewLen limits how much of an error body ends up in errors.
+const bodyPreviewLen = 200
+
+func New(cfg Config) *Client {
+	def := DefaultConfig()
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = def.Timeout
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = def.BaseBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = def.MaxBackoff
+	}
+	if cfg.MaxRetryAfter <= 0 {
+		cfg.MaxRetryAfter = def.MaxRetryAfter
+	}
+	if cfg.Burst <= 0 {
+		cfg.Burst = 1
+	}
+
+	return &Client{
+		cfg:      cfg,
+		http:     &http.Client{Timeout: cfg.Timeout, Transport: cfg.Transport},
+		limiters: make(map[string]*rate.Limiter),
+		quotas:   make(map[string]Quota),
+		now:      time.Now,
+		sleep:    sleepContext,
+	}
+}
+
+// Do sends req, retrying network errors, 429s and 502-504s for requests
+// that are safe to repeat. The caller must close the response body.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	host := req.URL.Host
+
+	for attempt := 0; ; attempt++ {
+		if err := c.checkQuota(host); err != nil {
+			return nil, err
+		}
+		if err := c.limiter(host).Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("error rewinding request body: %w", err)
+			}
+			req.Body = body
+		}
+
+		res, err := c.http.Do(req)
+		if err == nil {
+			c.recordQuota(host, res.Header)
+		}
+
+		wait, retry := c.retryAfter(req, res, err, attempt)
+		if !retry {
+			return res, err
+		}
+
+		if res != nil {
+			io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+			res.Body.Close()
+			log.Printf("🔥 %s %s returned %d, retrying in %s", req.Method, req.URL.Redacted(), res.StatusCode, wait)
+		} else {
+			log.Printf("🔥 %s %s failed: %s, retrying in %s", req.Method, req.URL.Redacted(), err, wait)
+		}
+
+		if err := c.sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// GetBytes sends req and returns the body of a 2xx response.
+func (c *Client) GetBytes(req *http.Request) ([]byte, error) {
+	res, err := c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %w", err)
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, &StatusError{StatusCode: res.StatusCode, Body: preview(body)}
+	}
+
+	return body, nil
+}
+
+// GetJSON sends req and decodes a 2xx JSON response into v.
+func (c *Client) GetJSON(req *http.Request, v interface{}) error {
+	body, err := c.GetBytes(req)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("error parsing JSON: %w, response preview: %s", err, preview(body))
+	}
+
+	return nil
+}
+
+// retryAfter decides whether an attempt should be retried and how long to
+// wait first.
+func (c *Client) retryAfter(req *http.Request, res *http.Response, err error, attempt int) (time.Duration, bool) {
+	if attempt >= c.cfg.MaxRetries || req.Context().Err() != nil || !replayable(req) {
+		return 0, false
+	}
+
+	if err != nil {
+		return c.backoff(attempt), true
+	}
+
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+	default:
+		return 0, false
+	}
+
+	if wait, ok := parseRetryAfter(res.Header.Get("Retry-After"), c.now()); ok {
+		if wait > c.cfg.MaxRetryAfter {
+			return 0, false
+		}
+		return wait, true
+	}
+
+	return c.backoff(attempt), true
+}
+
+// backoff doubles the delay on every attempt up to MaxBackoff and picks a
+// random point in its upper half, so clients that failed together do not
+// retry together.
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.cfg.MaxBackoff
+	if attempt < 30 {
+		d = min(c.cfg.BaseBackoff<<attempt, c.cfg.MaxBackoff)
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int64N(int64(half)+1))
+}
+
+func (c *Client) limiter(host string) *rate.Limiter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	l, ok := c.limiters[host]
+	if !ok {
+		limit := rate.Inf
+		if c.cfg.RateInterval > 0 {
+			limit = rate.Every(c.cfg.RateInterval)
+		}
+		l = rate.NewLimiter(limit, c.cfg.Burst)
+		c.limiters[host] = l
+	}
+	return l
+}
+
+// replayable reports whether req can be sent again: an idempotent method
+// and a body that can be rewound.
+func replayable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an
+// HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func preview(body []byte) string {
+	if len(body) > bodyPreviewLen {
+		return string(body[:bodyPreviewLen])
+	}
+	return string(body)
+}
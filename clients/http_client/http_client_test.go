@@ -0,0 +1,200 @@
+package http_client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient returns a client without rate limiting whose sleeps are
+// recorded instead of waited for.
+func newTestClient(cfg Config) (*Client, *[]time.Duration) {
+	c := New(cfg)
+	var waits []time.Duration
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	return c, &waits
+}
+
+func get(t *testing.T, c *Client, url string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	res, err := c.Do(req)
+	require.NoError(t, err)
+	res.Body.Close()
+	return res
+}
+
+func TestDoRetriesTransientFailures(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	c, waits := newTestClient(Config{MaxRetries: 3, BaseBackoff: 100 * time.Millisecond})
+
+	res := get(t, c, srv.URL)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, int32(3), calls.Load())
+	require.Len(t, *waits, 2)
+	assert.True(t, (*waits)[0] >= 50*time.Millisecond && (*waits)[0] <= 100*time.Millisecond, "first backoff %s", (*waits)[0])
+	assert.True(t, (*waits)[1] >= 100*time.Millisecond && (*waits)[1] <= 200*time.Millisecond, "second backoff %s", (*waits)[1])
+}
+
+func TestDoGivesUpAfterMaxRetries(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	c, _ := newTestClient(Config{MaxRetries: 2})
+
+	res := get(t, c, srv.URL)
+	assert.Equal(t, http.StatusBadGateway, res.StatusCode)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestDoHonoursRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer srv.Close()
+
+	c, waits := newTestClient(Config{MaxRetries: 5, MaxRetryAfter: time.Minute})
+
+	// The second Retry-After is longer than we are willing to wait.
+	res := get(t, c, srv.URL)
+	assert.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+	assert.Equal(t, []time.Duration{2 * time.Second}, *waits)
+}
+
+func TestDoDoesNotRetryPost(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c, _ := newTestClient(Config{MaxRetries: 3})
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader("{}"))
+	require.NoError(t, err)
+	res, err := c.Do(req)
+	require.NoError(t, err)
+	res.Body.Close()
+
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestDoStopsWhenQuotaExhausted(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set(headerQuotaLimit, "100")
+		w.Header().Set(headerQuotaRemaining, "0")
+		w.Header().Set(headerQuotaReset, "60")
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	c, _ := newTestClient(Config{})
+	c.now = func() time.Time { return now }
+
+	get(t, c, srv.URL)
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+	_, err = c.Do(req)
+	assert.ErrorIs(t, err, ErrQuotaExhausted)
+	assert.Equal(t, int32(1), calls.Load())
+
+	host := req.URL.Host
+	assert.Equal(t, Quota{Limit: 100, Remaining: 0, ResetAt: now.Add(time.Minute), UpdatedAt: now}, c.Quotas()[host])
+
+	now = now.Add(61 * time.Second)
+	get(t, c, srv.URL)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestDoRateLimitsPerHost(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	c := New(Config{RateInterval: 50 * time.Millisecond, Burst: 1})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		get(t, c, srv.URL)
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+}
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestGetJSON(t *testing.T) {
+	c, _ := newTestClient(Config{
+		MaxRetries: 1,
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, "secret", req.Header.Get("x-rapidapi-key"))
+			assert.Equal(t, "jobs.p.rapidapi.com", req.Header.Get("x-rapidapi-host"))
+
+			if req.URL.Query().Get("q") == "missing" {
+				return &http.Response{StatusCode: http.StatusNotFound, Body: http.NoBody, Header: make(http.Header)}, nil
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"title":"Go developer"}`)),
+				Header:     make(http.Header),
+			}, nil
+		}),
+	})
+
+	req, err := NewRapidAPIRequest(context.Background(), "https://jobs.p.rapidapi.com/search", map[string][]string{"q": {"go"}}, "secret")
+	require.NoError(t, err)
+
+	var v struct{ Title string }
+	require.NoError(t, c.GetJSON(req, &v))
+	assert.Equal(t, "Go developer", v.Title)
+
+	req, err = NewRapidAPIRequest(context.Background(), "https://jobs.p.rapidapi.com/search", map[string][]string{"q": {"missing"}}, "secret")
+	require.NoError(t, err)
+
+	var statusErr *StatusError
+	require.ErrorAs(t, c.GetJSON(req, &v), &statusErr)
+	assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+}
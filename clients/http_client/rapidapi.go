@@ -0,0 +1,115 @@
+package http_client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// RapidAPI reports the plan quota in these response headers; the reset is
+// the number of seconds until the quota renews.
+const (
+	headerQuotaLimit     = "X-RateLimit-Requests-Limit"
+	headerQuotaRemaining = "X-RateLimit-Requests-Remaining"
+	headerQuotaReset     = "X-RateLimit-Requests-Reset"
+)
+
+// Quota is the last RapidAPI quota a host reported.
+type Quota struct {
+	Limit     int
+	Remaining int
+	ResetAt   time.Time
+	UpdatedAt time.Time
+}
+
+// NewRapidAPIRequest builds a GET request with the RapidAPI key and host
+// headers set.
+func NewRapidAPIRequest(ctx context.Context, rawURL string, query url.Values, apiKey string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	if len(query) > 0 {
+		req.URL.RawQuery = query.Encode()
+	}
+
+	req.Header.Set("x-rapidapi-key", apiKey)
+	req.Header.Set("x-rapidapi-host", req.URL.Hostname())
+
+	return req, nil
+}
+
+// NewRapidAPIJSONRequest builds a POST request with body encoded as JSON
+// and the RapidAPI headers set.
+func NewRapidAPIJSONRequest(ctx context.Context, rawURL string, body interface{}, apiKey string) (*http.Request, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rawURL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-rapidapi-key", apiKey)
+	req.Header.Set("x-rapidapi-host", req.URL.Hostname())
+
+	return req, nil
+}
+
+// Quotas returns the last quota seen for every host that reported one.
+func (c *Client) Quotas() map[string]Quota {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	quotas := make(map[string]Quota, len(c.quotas))
+	for host, q := range c.quotas {
+		quotas[host] = q
+	}
+	return quotas
+}
+
+func (c *Client) checkQuota(host string) error {
+	c.mu.Lock()
+	q, ok := c.quotas[host]
+	c.mu.Unlock()
+
+	if ok && q.Remaining <= 0 && c.now().Before(q.ResetAt) {
+		return fmt.Errorf("%w for %s until %s", ErrQuotaExhausted, host, q.ResetAt.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func (c *Client) recordQuota(host string, header http.Header) {
+	limit, err := strconv.Atoi(header.Get(headerQuotaLimit))
+	if err != nil {
+		return
+	}
+	remaining, err := strconv.Atoi(header.Get(headerQuotaRemaining))
+	if err != nil {
+		return
+	}
+
+	now := c.now()
+	q := Quota{Limit: limit, Remaining: remaining, UpdatedAt: now}
+	if secs, err := strconv.Atoi(header.Get(headerQuotaReset)); err == nil {
+		q.ResetAt = now.Add(time.Duration(secs) * time.Second)
+	}
+
+	c.mu.Lock()
+	c.quotas[host] = q
+	c.mu.Unlock()
+
+	if limit > 0 && remaining*10 < limit {
+		log.Printf("🔥 %s quota low: %d of %d requests left", host, remaining, limit)
+	}
+}
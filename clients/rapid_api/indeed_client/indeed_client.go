@@ -1,147 +1,223 @@
 package indeed_client
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
+	"htmxjb/clients/http_client"
+	"htmxjb/models/domain"
 	"htmxjb/models/responses"
-	"htmxjb/clients/csv_client"
-	"io"
-	"net/http"
+	"htmxjb/services/salary"
+	"strconv"
+	"time"
 )
 
-// Job представляет универсальную модель вакансии для сохранения в БД
-type Job struct {
-	ExternalID  string
-	Title       string
-	Description string
-	Company     string
-	Location    string
-	URL         string
-	Source      int // 1 для Indeed
-	Type        string
-}
+const defaultBaseURL = "https://indeed-scraper-api.p.rapidapi.com"
+
+// Состояния задачи скрапера
+const (
+	stateCompleted = "completed"
+	stateFailed    = "failed"
+)
 
-// Интерфейс клиента для Indeed
-type IndeedClientInterface interface {
-	GetJobs() ([]Job, error)
-	SaveJobs(db Database) error
+var ErrScrapeFailed = errors.New("indeed scrape job failed")
+
+// SearchProfile описывает один поиск на Indeed
+type SearchProfile struct {
+	Name     string
+	Query    string
+	Location string
+	Country  string
+	JobType  string
+	Sort     string
+	Radius   int
+	FromDays int
+	MaxRows  int
 }
 
-// Реализация клиента
+// IndeedClient запускает задачу скрапера для одного профиля поиска и
+// ждет ее завершения
 type IndeedClient struct {
-	apiKey string
-	host   string
+	apiKey  string
+	baseURL string
+	profile SearchProfile
+	http    *http_client.Client
+
+	pollInterval time.Duration
+	timeout      time.Duration
 }
 
-// NewIndeedClient создает новый экземпляр клиента Indeed
-func NewIndeedClient(apiKey string) *IndeedClient {
+// NewIndeedClient создает новый экземпляр клиента Indeed. Общий hc
+// позволяет источникам делить лимиты запросов; nil — клиент по умолчанию.
+func NewIndeedClient(apiKey string, profile SearchProfile, hc *http_client.Client) *IndeedClient {
+	if hc == nil {
+		hc = http_client.New(http_client.DefaultConfig())
+	}
 	return &IndeedClient{
-		apiKey: apiKey,
-		host:   "indeed-scraper-api.p.rapidapi.com",
+		apiKey:       apiKey,
+		baseURL:      defaultBaseURL,
+		profile:      profile,
+		http:         hc,
+		pollInterval: 10 * time.Second,
+		timeout:      5 * time.Minute,
 	}
 }
 
-// GetJobs выполняет запрос к API Indeed и возвращает вакансии
-func (c *IndeedClient) GetJobs() ([]Job, error) {
-	url := "https://indeed-scraper-api.p.rapidapi.com/jobs"
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
+// WithPolling задает интервал опроса задачи и общий таймаут одного поиска
+func (c *IndeedClient) WithPolling(interval, timeout time.Duration) *IndeedClient {
+	if interval > 0 {
+		c.pollInterval = interval
+	}
+	if timeout > 0 {
+		c.timeout = timeout
 	}
+	return c
+}
 
-	// Добавление параметров запроса
-	q := req.URL.Query()
-	q.Add("query", "htmx")
+func (c *IndeedClient) Source() domain.JobSource {
+	return domain.Indeed
+}
 
-	req.URL.RawQuery = q.Encode()
+// Complete всегда false: скрапер возвращает не больше MaxRows вакансий
+// за последние FromDays дней, а не все открытые
+func (c *IndeedClient) Complete() bool {
+	return false
+}
 
-	// Установка заголовков
-	req.Header.Add("x-rapidapi-key", c.apiKey)
-	req.Header.Add("x-rapidapi-host", c.host)
+// FetchJobs отправляет задачу скрапера, ждет ее завершения и возвращает
+// найденные вакансии
+func (c *IndeedClient) FetchJobs(ctx context.Context) ([]domain.Job, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
 
-	// Выполнение запроса
-	res, err := http.DefaultClient.Do(req)
+	id, err := c.submit(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("error making request: %w", err)
+		return nil, fmt.Errorf("failed to submit indeed search %q: %w", c.profile.Name, err)
 	}
-	defer res.Body.Close()
 
-	// Проверка статус-кода
-	if res.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(res.Body)
-		return nil, fmt.Errorf("received non-200 response: %d, body: %s", res.StatusCode, string(body))
-	}
-
-	// Чтение и разбор ответа
-	body, err := io.ReadAll(res.Body)
+	result, err := c.wait(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("error reading response: %w", err)
+		return nil, fmt.Errorf("indeed search %q (job %s): %w", c.profile.Name, id, err)
 	}
 
-	var response responses.IndeedResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		// Для отладки выводим часть тела ответа
-		previewLen := 200
-		if len(body) < previewLen {
-			previewLen = len(body)
-		}
-		return nil, fmt.Errorf("error parsing JSON: %w, response preview: %s", err, string(body[:previewLen]))
-	}
-
-	// Преобразование данных из API в нашу структуру Job
-	var jobs []Job
-	for _, indeedJob := range response.ReturnValue.Data {
-		location := ""
-		if indeedJob.Location.FormattedAddressShort != "" {
-			location = indeedJob.Location.FormattedAddressShort
-		} else if indeedJob.Location.FormattedAddressLong != "" {
-			location = indeedJob.Location.FormattedAddressLong
-		} else if indeedJob.Location.City != "" {
-			location = indeedJob.Location.City
-		}
-
-		jobs = append(jobs, Job{
-			ExternalID:  indeedJob.JobKey,
-			Title:       indeedJob.Title,
-			Description: indeedJob.DescriptionText,
-			Company:     indeedJob.CompanyName,
-			Location:    location,
-			URL:         indeedJob.JobUrl,
-			Source:      1,
-			Type:        indeedJob.JobType,
-		})
+	// Преобразование данных из API в domain.Job
+	jobs := make([]domain.Job, 0, len(result.ReturnValue.Data))
+	for _, indeedJob := range result.ReturnValue.Data {
+		jobs = append(jobs, MapJob(indeedJob))
 	}
 
 	return jobs, nil
 }
 
-// SaveJobs сохраняет вакансии в базу данных
-func (c *IndeedClient) SaveJobs(db Database) error {
-	jobs, err := c.GetJobs()
+// submit создает задачу скрапера и возвращает ее ID
+func (c *IndeedClient) submit(ctx context.Context) (string, error) {
+	body := responses.IndeedScraperRequest{Scraper: c.profile.params()}
+
+	req, err := http_client.NewRapidAPIJSONRequest(ctx, c.baseURL+"/api/job", body, c.apiKey)
 	if err != nil {
-		return fmt.Errorf("error fetching jobs: %w", err)
+		return "", err
 	}
 
-	for _, job := range jobs {
-		// Проверяем существование вакансии перед сохранением
-		existingJob, err := db.GetJobByExternalID(job.ExternalID)
-		if err == nil && existingJob.ExternalID != "" {
-			// Вакансия уже существует, пропускаем
-			continue
+	var created responses.IndeedResponse
+	if err := c.http.GetJSON(req, &created); err != nil {
+		return "", err
+	}
+	if created.ID == "" {
+		return "", errors.New("response has no job id")
+	}
+
+	return created.ID, nil
+}
+
+// wait опрашивает задачу, пока она не завершится, не упадет или не
+// истечет контекст
+func (c *IndeedClient) wait(ctx context.Context, id string) (responses.IndeedResponse, error) {
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		req, err := http_client.NewRapidAPIRequest(ctx, c.baseURL+"/api/job/"+id, nil, c.apiKey)
+		if err != nil {
+			return responses.IndeedResponse{}, err
+		}
+
+		var status responses.IndeedResponse
+		if err := c.http.GetJSON(req, &status); err != nil {
+			return responses.IndeedResponse{}, err
+		}
+
+		switch status.State {
+		case stateCompleted:
+			return status, nil
+		case stateFailed:
+			return responses.IndeedResponse{}, ErrScrapeFailed
 		}
 
-		// Сохраняем новую вакансию
-		if err := db.SaveJob(job); err != nil {
-			return fmt.Errorf("error saving job %s: %w", job.ExternalID, err)
+		select {
+		case <-ctx.Done():
+			return responses.IndeedResponse{}, fmt.Errorf("gave up at %d%% (%s): %w", status.Progress, status.State, ctx.Err())
+		case <-ticker.C:
 		}
 	}
+}
+
+func (p SearchProfile) params() responses.IndeedScraperParams {
+	params := responses.IndeedScraperParams{
+		Query:    p.Query,
+		Location: p.Location,
+		JobType:  p.JobType,
+		Sort:     p.Sort,
+		Country:  p.Country,
+		MaxRows:  p.MaxRows,
+	}
+	if p.Radius > 0 {
+		params.Radius = strconv.Itoa(p.Radius)
+	}
+	if p.FromDays > 0 {
+		params.FromDays = strconv.Itoa(p.FromDays)
+	}
+	return params
+}
+
+// MapJob переносит вакансию Indeed в domain.Job. Зарплату берем из
+// атрибутов как текст — ее разбирает SalaryEnricher.
+func MapJob(indeedJob responses.IndeedJob) domain.Job {
+	job := domain.Job{
+		ExternalID:      indeedJob.JobKey,
+		Title:           indeedJob.Title,
+		Description:     indeedJob.DescriptionText,
+		DescriptionHTML: indeedJob.DescriptionHtml,
+		Company:         indeedJob.CompanyName,
+		Location:        mapLocation(indeedJob.Location, indeedJob.RemoteLocation),
+		URL:             indeedJob.JobUrl,
+		Source:          domain.Indeed,
+		TypeText:        indeedJob.JobType,
+	}
+
+	if s, ok := salary.FromAttributes(indeedJob.Attributes, ""); ok {
+		job.Salary = domain.Salary{Raw: s.Raw}
+	}
 
-	return nil
+	return job
 }
 
-// Интерфейс для базы данных
-type Database interface {
-	SaveJob(job Job) error
-	GetJobByExternalID(externalID string) (Job, error)
+// mapLocation переносит структурированный адрес Indeed в domain.Location.
+// Координаты приходят от Indeed, поэтому геокодер их не перезаписывает.
+func mapLocation(loc responses.IndeedLocation, remote bool) domain.Location {
+	raw := loc.FormattedAddressShort
+	if raw == "" {
+		raw = loc.FormattedAddressLong
+	}
+	if raw == "" {
+		raw = loc.City
+	}
+
+	return domain.Location{
+		Raw:         raw,
+		City:        loc.City,
+		CountryCode: loc.CountryCode,
+		Latitude:    loc.Latitude,
+		Longitude:   loc.Longitude,
+		Remote:      remote,
+	}
 }
@@ -1,178 +1,132 @@
 package indeed_client
 
 import (
+	"context"
 	"encoding/json"
-	"io"
+	"htmxjb/clients/fixtures"
+	"htmxjb/clients/http_client"
+	"htmxjb/models/domain"
+	"htmxjb/models/responses"
 	"net/http"
-	"strings"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-func TestNewIndeedClient(t *testing.T) {
-	apiKey := "test-api-key"
-	client := NewIndeedClient(apiKey)
-	
-	if client.apiKey != apiKey {
-		t.Errorf("Expected apiKey to be %s, got %s", apiKey, client.apiKey)
-	}
-	
-	expectedHost := "indeed-scraper-api.p.rapidapi.com"
-	if client.host != expectedHost {
-		t.Errorf("Expected host to be %s, got %s", expectedHost, client.host)
+// austinProfile — поиск, с которым записана фикстура htmx-austin
+var austinProfile = SearchProfile{Name: "htmx", Query: "htmx", Location: "Austin, TX", Country: "us", Sort: "date", Radius: 25, FromDays: 7, MaxRows: 50}
+
+// newReplayClient воспроизводит testdata/fixtures/<name>.json; с
+// RECORD_FIXTURES=1 и RAPIDAPI_KEY запросы уходят в API и фикстура
+// перезаписывается
+func newReplayClient(t *testing.T, name string, profile SearchProfile) (*IndeedClient, *fixtures.Recorder) {
+	t.Helper()
+
+	key := fixtures.APIKey(t, "RAPIDAPI_KEY")
+	rec := fixtures.New(t, name, key)
+	c := NewIndeedClient(key, profile, http_client.New(http_client.Config{MaxRetries: 0, Transport: rec}))
+
+	if fixtures.Recording() {
+		return c.WithPolling(5*time.Second, 5*time.Minute), rec
 	}
+	return c.WithPolling(time.Millisecond, time.Second), rec
 }
 
-// MockRoundTripper реализует интерфейс http.RoundTripper для тестирования
-type MockRoundTripper struct {
-	RoundTripFunc func(req *http.Request) (*http.Response, error)
+// newScraperServer имитирует API скрапера: задача проходит состояния states
+func newScraperServer(t *testing.T, states []string) *httptest.Server {
+	t.Helper()
+
+	var polls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-api-key", r.Header.Get("x-rapidapi-key"))
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/job":
+			w.Write([]byte(`{"id": "gtk6rn0y35rcm5r0hz5qvf0i", "state": "waiting"}`))
+
+		case r.Method == http.MethodGet && r.URL.Path == "/api/job/gtk6rn0y35rcm5r0hz5qvf0i":
+			n := int(polls.Add(1))
+			if n > len(states) {
+				t.Errorf("unexpected poll %d", n)
+				http.NotFound(w, r)
+				return
+			}
+			json.NewEncoder(w).Encode(responses.IndeedResponse{State: states[n-1], Progress: 50})
+
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
 }
 
-func (m *MockRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
-	return m.RoundTripFunc(req)
+func newTestClient(baseURL string, profile SearchProfile) *IndeedClient {
+	c := NewIndeedClient("test-api-key", profile, http_client.New(http_client.Config{MaxRetries: 0}))
+	c.baseURL = baseURL
+	return c.WithPolling(time.Millisecond, time.Second)
 }
 
-func TestGetJobs_Success(t *testing.T) {
-	// Подготовка тестовых данных
-	mockJobs := []IndeedJob{
-		{
-			ID:          "job1",
-			Title:       "Software Engineer",
-			Description: "Job description 1",
-			Type:        "Full-time",
-		},
-		{
-			ID:          "job2",
-			Title:       "Data Scientist", 
-			Description: "Job description 2",
-			Type:        "Contract",
-		},
-	}
-	
-	// Преобразование в JSON
-	mockJSON, err := json.Marshal(mockJobs)
-	if err != nil {
-		t.Fatalf("Failed to marshal mock jobs: %v", err)
-	}
-	
-	// Создание мок-клиента HTTP
-	mockClient := &http.Client{
-		Transport: &MockRoundTripper{
-			RoundTripFunc: func(req *http.Request) (*http.Response, error) {
-				// Проверка заголовков
-				apiKey := req.Header.Get("x-rapidapi-key")
-				if apiKey != "test-api-key" {
-					t.Errorf("Expected API key header to be test-api-key, got %s", apiKey)
-				}
-				
-				host := req.Header.Get("x-rapidapi-host")
-				if host != "indeed-scraper-api.p.rapidapi.com" {
-					t.Errorf("Expected host header to be indeed-scraper-api.p.rapidapi.com, got %s", host)
-				}
-				
-				// Формирование ответа
-				response := &http.Response{
-					StatusCode: http.StatusOK,
-					Body:       io.NopCloser(strings.NewReader(string(mockJSON))),
-					Header:     make(http.Header),
-				}
-				return response, nil
-			},
-		},
-	}
-	
-	// Временная замена стандартного HTTP-клиента
-	defaultClient := http.DefaultClient
-	http.DefaultClient = mockClient
-	defer func() { http.DefaultClient = defaultClient }()
-	
-	// Создание клиента Indeed и выполнение запроса
-	client := NewIndeedClient("test-api-key")
-	jobs, err := client.GetJobs()
-	
-	// Проверки
-	if err != nil {
-		t.Errorf("Expected no error, got %v", err)
-	}
-	
-	if len(jobs) != 2 {
-		t.Errorf("Expected 2 jobs, got %d", len(jobs))
-	}
-	
-	// Проверка первой работы
-	if jobs[0].ExternalID != "job1" {
-		t.Errorf("Expected job ID job1, got %s", jobs[0].ExternalID)
-	}
-	if jobs[0].Title != "Software Engineer" {
-		t.Errorf("Expected job title Software Engineer, got %s", jobs[0].Title)
-	}
-	
-	// Проверка второй работы
-	if jobs[1].ExternalID != "job2" {
-		t.Errorf("Expected job ID job2, got %s", jobs[1].ExternalID)
-	}
-	if jobs[1].Type != "Contract" {
-		t.Errorf("Expected job type Contract, got %s", jobs[1].Type)
-	}
+// TestContract сверяет записанный результат скрапера с IndeedResponse:
+// тест падает, если API переименует или уберет поле, которое мы читаем
+func TestContract(t *testing.T) {
+	c, rec := newReplayClient(t, "htmx-austin", austinProfile)
+
+	_, err := c.FetchJobs(context.Background())
+	require.NoError(t, err)
+
+	interactions := rec.Interactions()
+	fixtures.RequireFields(t, rec.ResponseBody(0), struct {
+		ID    string `json:"id"`
+		State string `json:"state"`
+	}{})
+	fixtures.RequireFields(t, rec.ResponseBody(len(interactions)-1), responses.IndeedResponse{})
 }
 
-func TestGetJobs_HttpError(t *testing.T) {
-	// Создание мок-клиента, возвращающего ошибку
-	mockClient := &http.Client{
-		Transport: &MockRoundTripper{
-			RoundTripFunc: func(req *http.Request) (*http.Response, error) {
-				// Возврат ошибки 500
-				response := &http.Response{
-					StatusCode: http.StatusInternalServerError,
-					Body:       io.NopCloser(strings.NewReader("Internal Server Error")),
-					Header:     make(http.Header),
-				}
-				return response, nil
-			},
-		},
-	}
-	
-	// Временная замена стандартного HTTP-клиента
-	defaultClient := http.DefaultClient
-	http.DefaultClient = mockClient
-	defer func() { http.DefaultClient = defaultClient }()
-	
-	// Выполнение теста
-	client := NewIndeedClient("test-api-key")
-	_, err := client.GetJobs()
-	
-	// Проверка наличия ошибки
-	if err == nil {
-		t.Error("Expected an error, got nil")
+func TestFetchJobsPollsUntilCompleted(t *testing.T) {
+	if fixtures.Recording() {
+		t.Skip("проверяет записанную фикстуру")
 	}
+	c, rec := newReplayClient(t, "htmx-austin", austinProfile)
+
+	jobs, err := c.FetchJobs(context.Background())
+	require.NoError(t, err)
+
+	// Запрос на создание задачи и три опроса; тело запроса сверяет фикстура
+	assert.Len(t, rec.Interactions(), 4)
+
+	require.Len(t, jobs, 2)
+	assert.Equal(t, "5f2c1a9e0b7d4c31", jobs[0].ExternalID)
+	assert.Equal(t, domain.Indeed, jobs[0].Source)
+	assert.Equal(t, "Full-time", jobs[0].TypeText)
+	assert.Equal(t, "$120,000 - $150,000 a year", jobs[0].Salary.Raw)
+	assert.Equal(t, "<p>Build <b>Go</b> services with htmx front ends.</p>", jobs[0].DescriptionHTML)
+	assert.Equal(t, domain.Location{Raw: "Austin, TX", City: "Austin", CountryCode: "US", Latitude: 30.2672, Longitude: -97.7431}, jobs[0].Location)
+
+	assert.True(t, jobs[1].Location.Remote)
+	assert.Empty(t, jobs[1].Salary.Raw)
 }
 
-func TestGetJobs_InvalidJson(t *testing.T) {
-	// Создание мок-клиента, возвращающего неверный JSON
-	mockClient := &http.Client{
-		Transport: &MockRoundTripper{
-			RoundTripFunc: func(req *http.Request) (*http.Response, error) {
-				// Возврат неверного JSON
-				response := &http.Response{
-					StatusCode: http.StatusOK,
-					Body:       io.NopCloser(strings.NewReader("invalid json")),
-					Header:     make(http.Header),
-				}
-				return response, nil
-			},
-		},
-	}
-	
-	// Временная замена стандартного HTTP-клиента
-	defaultClient := http.DefaultClient
-	http.DefaultClient = mockClient
-	defer func() { http.DefaultClient = defaultClient }()
-	
-	// Выполнение теста
-	client := NewIndeedClient("test-api-key")
-	_, err := client.GetJobs()
-	
-	// Проверка наличия ошибки при парсинге JSON
-	if err == nil {
-		t.Error("Expected a JSON parsing error, got nil")
+func TestFetchJobsFailedScrape(t *testing.T) {
+	srv := newScraperServer(t, []string{"active", "failed"})
+
+	_, err := newTestClient(srv.URL, SearchProfile{Name: "htmx"}).FetchJobs(context.Background())
+	assert.ErrorIs(t, err, ErrScrapeFailed)
+}
+
+func TestFetchJobsTimesOut(t *testing.T) {
+	states := make([]string, 1000)
+	for i := range states {
+		states[i] = "active"
 	}
+	srv := newScraperServer(t, states)
+
+	c := newTestClient(srv.URL, SearchProfile{Name: "htmx"}).WithPolling(5*time.Millisecond, 50*time.Millisecond)
+	_, err := c.FetchJobs(context.Background())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
 }
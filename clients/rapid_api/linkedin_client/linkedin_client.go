@@ -1,39 +1,213 @@
 package linkedin_client
 
 import (
+	"context"
 	"fmt"
-	"io"
-	"net/http"
+	"htmxjb/clients/http_client"
+	"htmxjb/models/domain"
+	"htmxjb/models/responses"
+	"htmxjb/services/salary"
+	"net/url"
+	"strconv"
+	"strings"
 )
 
-type LinkedinClient interface {
-	GetJobs() error
+const defaultBaseURL = "https://linkedin-job-search-api.p.rapidapi.com"
+
+// DateWindow picks how far back postings are fetched. Each window is its
+// own endpoint.
+type DateWindow string
+
+const (
+	LastHour DateWindow = "1h"
+	LastDay  DateWindow = "24h"
+	LastWeek DateWindow = "7d"
+)
+
+// pageLimit is the most postings the API returns per request.
+const pageLimit = 100
+
+// Query selects the postings to fetch. Title and Location use the API's
+// filter syntax, e.g. `"Go" OR "Golang"`.
+type Query struct {
+	Title    string
+	Location string
+	Window   DateWindow
+	// Offset is where the first page starts.
+	Offset int
+	// MaxPages caps how many pages of PageSize postings one fetch reads.
+	MaxPages int
+	PageSize int
 }
 
 type LinkedinClient struct {
-	apiKey string
+	apiKey  string
+	baseURL string
+	query   Query
+	http    *http_client.Client
 }
 
-func NewLinkedinClient(apiKey string) *LinkedinClient {
+// NewLinkedinClient returns a client for query. A shared hc lets sources
+// share rate limits; nil uses a default client.
+func NewLinkedinClient(apiKey string, query Query, hc *http_client.Client) *LinkedinClient {
+	if hc == nil {
+		hc = http_client.New(http_client.DefaultConfig())
+	}
+	if query.Window == "" {
+		query.Window = LastWeek
+	}
+	if query.PageSize <= 0 || query.PageSize > pageLimit {
+		query.PageSize = pageLimit
+	}
+	if query.MaxPages <= 0 {
+		query.MaxPages = 5
+	}
+
 	return &LinkedinClient{
-		apiKey: apiKey,
+		apiKey:  apiKey,
+		baseURL: defaultBaseURL,
+		query:   query,
+		http:    hc,
+	}
+}
+
+func (c *LinkedinClient) Source() domain.JobSource {
+	return domain.LinkedIn
+}
+
+// Complete is false: the API only lists postings from the date window, and
+// a fetch stops after MaxPages.
+func (c *LinkedinClient) Complete() bool {
+	return false
+}
+
+// FetchJobs reads pages until one comes back short or MaxPages is reached.
+func (c *LinkedinClient) FetchJobs(ctx context.Context) ([]domain.Job, error) {
+	var jobs []domain.Job
+
+	offset := c.query.Offset
+	for page := 0; page < c.query.MaxPages; page++ {
+		postings, err := c.GetPage(ctx, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch linkedin page at offset %d: %w", offset, err)
+		}
+
+		for _, posting := range postings {
+			jobs = append(jobs, MapJob(posting))
+		}
+
+		if len(postings) < c.query.PageSize {
+			break
+		}
+		offset += len(postings)
+	}
+
+	return jobs, nil
+}
+
+// GetPage fetches one page of postings starting at offset.
+func (c *LinkedinClient) GetPage(ctx context.Context, offset int) (responses.LinkedinResponse, error) {
+	params := url.Values{
+		"offset":           {strconv.Itoa(offset)},
+		"limit":            {strconv.Itoa(c.query.PageSize)},
+		"description_type": {"html"},
+	}
+	if c.query.Title != "" {
+		params.Set("title_filter", c.query.Title)
+	}
+	if c.query.Location != "" {
+		params.Set("location_filter", c.query.Location)
+	}
+
+	req, err := http_client.NewRapidAPIRequest(ctx, c.baseURL+"/active-jb-"+string(c.query.Window), params, c.apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var page responses.LinkedinResponse
+	if err := c.http.GetJSON(req, &page); err != nil {
+		return nil, err
 	}
+
+	return page, nil
 }
-func(c *LinkedinClient) GetJobs() error {
 
-	url := "https://linkedin-job-search-api.p.rapidapi.com/active-jb-7d"
+// MapJob converts a LinkedIn posting to a domain job. Employment types
+// such as "FULL_TIME" go to TypeText for the type mapper.
+func MapJob(posting responses.LinkedinJob) domain.Job {
+	return domain.Job{
+		ExternalID:      posting.ID,
+		Title:           posting.Title,
+		Description:     posting.DescriptionText,
+		DescriptionHTML: posting.DescriptionHTML,
+		Company:         posting.Organization,
+		URL:             posting.URL,
+		Source:          domain.LinkedIn,
+		TypeText:        strings.Join(posting.EmploymentType, ", "),
+		Location:        mapLocation(posting),
+		Salary:          mapSalary(posting.SalaryRaw),
+	}
+}
 
-	req, _ := http.NewRequest("GET", url, nil)
+func mapLocation(posting responses.LinkedinJob) domain.Location {
+	loc := domain.Location{
+		Raw:    first(posting.LocationsDerived),
+		City:   first(posting.CitiesDerived),
+		Region: first(posting.RegionsDerived),
+		Remote: posting.RemoteDerived || (posting.LocationType != nil && *posting.LocationType == "TELECOMMUTE"),
+	}
 
-	req.Header.Add("x-rapidapi-key", "3ba6283321msh80a967930e01301p13ea9fjsna2afafa73bdc")
-	req.Header.Add("x-rapidapi-host", "linkedin-job-search-api.p.rapidapi.com")
+	if len(posting.LocationsRaw) > 0 {
+		place := posting.LocationsRaw[0]
+		if len(place.Address.AddressCountry) == 2 {
+			loc.CountryCode = strings.ToUpper(place.Address.AddressCountry)
+		}
+		if loc.City == "" {
+			loc.City = place.Address.AddressLocality
+		}
+		if loc.Region == "" {
+			loc.Region = place.Address.AddressRegion
+		}
+		loc.Latitude, loc.Longitude = place.Latitude, place.Longitude
+	}
 
-	res, _ := http.DefaultClient.Do(req)
+	if !loc.HasCoordinates() && len(posting.LatsDerived) > 0 && len(posting.LngsDerived) > 0 {
+		loc.Latitude, loc.Longitude = posting.LatsDerived[0], posting.LngsDerived[0]
+	}
+
+	return loc
+}
 
-	defer res.Body.Close()
-	body, _ := io.ReadAll(res.Body)
+// mapSalary takes the structured range as is. Raw is filled in for
+// display, which also lets the salary enricher normalize the range.
+func mapSalary(raw *responses.LinkedinSalary) domain.Salary {
+	if raw == nil {
+		return domain.Salary{}
+	}
 
-	fmt.Println(res)
-	fmt.Println(string(body))
+	v := raw.Value
+	var lo, hi float64
+	switch {
+	case v.MinValue != nil || v.MaxValue != nil:
+		lo, hi = deref(v.MinValue), deref(v.MaxValue)
+	case v.Value != nil:
+		lo, hi = *v.Value, *v.Value
+	}
 
+	s, _ := salary.FromRange(lo, hi, raw.Currency, v.UnitText)
+	return s
+}
+
+func deref(v *float64) float64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
 }
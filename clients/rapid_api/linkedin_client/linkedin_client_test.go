@@ -0,0 +1,131 @@
+package linkedin_client
+
+import (
+	"context"
+	"htmxjb/clients/fixtures"
+	"htmxjb/clients/http_client"
+	"htmxjb/models/domain"
+	"htmxjb/models/responses"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newReplayClient returns a client for the query the checked-in
+// fixtures were recorded with.
+func newReplayClient(t *testing.T) (*LinkedinClient, *fixtures.Recorder) {
+	t.Helper()
+
+	key := fixtures.APIKey(t, "RAPIDAPI_KEY")
+	rec := fixtures.New(t, "active-jb-7d", key)
+	query := Query{Title: `"Go" OR "Golang"`, Location: "Germany", PageSize: 2, MaxPages: 2}
+
+	return NewLinkedinClient(key, query, http_client.New(http_client.Config{MaxRetries: 0, Transport: rec})), rec
+}
+
+func newTestClient(baseURL string, query Query) *LinkedinClient {
+	c := NewLinkedinClient("test-api-key", query, http_client.New(http_client.Config{MaxRetries: 0}))
+	c.baseURL = baseURL
+	return c
+}
+
+// TestContract checks the recorded pages against LinkedinResponse; it
+// fails when a field we read is renamed or dropped upstream. Run with
+// RECORD_FIXTURES=1 and RAPIDAPI_KEY set to re-record.
+func TestContract(t *testing.T) {
+	c, rec := newReplayClient(t)
+
+	_, err := c.FetchJobs(context.Background())
+	require.NoError(t, err)
+
+	for i := range rec.Interactions() {
+		fixtures.RequireFields(t, rec.ResponseBody(i), responses.LinkedinResponse{})
+	}
+}
+
+func TestFetchJobsPaginates(t *testing.T) {
+	if fixtures.Recording() {
+		t.Skip("asserts on the checked-in fixture")
+	}
+	c, rec := newReplayClient(t)
+
+	jobs, err := c.FetchJobs(context.Background())
+	require.NoError(t, err)
+	require.Len(t, jobs, 3)
+	assert.Equal(t, []string{"1587322491", "1587410022", "1587500871"}, []string{jobs[0].ExternalID, jobs[1].ExternalID, jobs[2].ExternalID})
+
+	// The second page is short, so there is no third request.
+	assert.Len(t, rec.Interactions(), 2)
+}
+
+func TestFetchJobsStopsAtMaxPages(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "test-api-key", r.Header.Get("x-rapidapi-key"))
+		w.Write([]byte(`[{"id": "1"}, {"id": "2"}]`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(srv.URL, Query{PageSize: 2, MaxPages: 1})
+
+	jobs, err := c.FetchJobs(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, jobs, 2)
+	assert.Equal(t, 1, requests)
+}
+
+func TestFetchJobsError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid key", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	_, err := newTestClient(srv.URL, Query{}).FetchJobs(context.Background())
+
+	var statusErr *http_client.StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusForbidden, statusErr.StatusCode)
+}
+
+func TestMapJob(t *testing.T) {
+	if fixtures.Recording() {
+		t.Skip("asserts on the checked-in fixture")
+	}
+	c, _ := newReplayClient(t)
+
+	jobs, err := c.FetchJobs(context.Background())
+	require.NoError(t, err)
+	require.Len(t, jobs, 3)
+
+	onsite := jobs[0]
+	assert.Equal(t, domain.LinkedIn, onsite.Source)
+	assert.Equal(t, "Senior Go Engineer", onsite.Title)
+	assert.Equal(t, "Acme Cloud", onsite.Company)
+	assert.Equal(t, "FULL_TIME", onsite.TypeText)
+	assert.Equal(t, domain.Location{
+		Raw:         "Berlin, Berlin, Germany",
+		City:        "Berlin",
+		Region:      "Berlin",
+		CountryCode: "DE",
+		Latitude:    52.52437,
+		Longitude:   13.41053,
+	}, onsite.Location)
+	assert.Equal(t, domain.Salary{
+		Raw:      "EUR 70,000 - 90,000 per year",
+		Min:      70000,
+		Max:      90000,
+		Currency: "EUR",
+		Period:   domain.Yearly,
+	}, onsite.Salary)
+
+	remote := jobs[1]
+	assert.True(t, remote.Location.Remote)
+	assert.Equal(t, "US", remote.Location.CountryCode)
+	assert.False(t, remote.Location.HasCoordinates())
+	assert.Equal(t, "USD 65 per hour", remote.Salary.Raw)
+	assert.Equal(t, domain.Hourly, remote.Salary.Period)
+}
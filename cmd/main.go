@@ -1,18 +1,39 @@
 package main
 
 import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/igorrize/htmxjb/clients/ats/ashby_client"
+	"github.com/igorrize/htmxjb/clients/ats/greenhouse_client"
+	"github.com/igorrize/htmxjb/clients/ats/lever_client"
+	"github.com/igorrize/htmxjb/clients/careers_client"
+	"github.com/igorrize/htmxjb/clients/csv_client"
+	"github.com/igorrize/htmxjb/clients/http_client"
+	"github.com/igorrize/htmxjb/clients/rapid_api/indeed_client"
+	"github.com/igorrize/htmxjb/clients/rapid_api/linkedin_client"
+	"github.com/igorrize/htmxjb/config"
 	"github.com/igorrize/htmxjb/db"
 	"github.com/igorrize/htmxjb/handlers"
+	"github.com/igorrize/htmxjb/models/domain"
 	"github.com/igorrize/htmxjb/services"
+	"github.com/igorrize/htmxjb/services/blob"
+	"github.com/igorrize/htmxjb/services/events"
+	"github.com/igorrize/htmxjb/services/geo"
+	"github.com/igorrize/htmxjb/services/i18n"
+	"github.com/igorrize/htmxjb/services/mail"
+	"github.com/igorrize/htmxjb/services/mapping"
+	"github.com/igorrize/htmxjb/services/payments"
+	"github.com/igorrize/htmxjb/services/salary"
+	"github.com/igorrize/htmxjb/services/spam"
+	"github.com/igorrize/htmxjb/services/tags"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
 
-const (
-	DB_NAME string = "jobs.db"
-)
-
 func main() {
+	cfg := config.Load()
 
 	e := echo.New()
 
@@ -22,16 +43,298 @@ func main() {
 	// Helpers Middleware
 	e.Use(middleware.Logger())
 
-	store, err := db.NewStore(DB_NAME)
+	catalog, err := i18n.Bundled()
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+	e.Use(handlers.Locale(catalog))
+
+	store, err := db.NewStore(cfg.DBName)
 	if err != nil {
 		e.Logger.Fatal(err)
 	}
+	defer store.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	snapshotter := db.NewSnapshotter(store, cfg.BackupDir, cfg.BackupInterval, cfg.BackupRetain)
+	go snapshotter.Run(ctx)
 
 	js := services.NewJobServices(services.Job{}, store)
-	jh := handlers.NewJobHandler(js)
+	bus := events.NewBus()
+
+	blobs, err := blob.NewDisk(cfg.UploadDir)
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+
+	retention := services.NewRetentionService(store, services.RetentionPolicy{
+		TTL: map[domain.JobSource]time.Duration{
+			domain.Indeed:     cfg.IndeedTTL,
+			domain.LinkedIn:   cfg.LinkedinTTL,
+			domain.Csv:        cfg.CsvTTL,
+			domain.CareerPage: cfg.CareersTTL,
+			domain.Greenhouse: cfg.ATSTTL,
+			domain.Lever:      cfg.ATSTTL,
+			domain.Ashby:      cfg.ATSTTL,
+		},
+		ArchiveAfter: cfg.ArchiveAfter,
+		PurgeAfter:   cfg.PurgeAfter,
+	}).WithEvents(bus).WithBlobs(blobs)
+	go retention.Run(ctx, cfg.RetentionInterval)
+
+	rates, err := salary.ParseRates(cfg.SalaryRates)
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+	salaries := salary.NewNormalizer(cfg.SalaryBaseCurrency, rates)
+
+	places, err := geo.Bundled()
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+
+	skills, err := tags.Bundled()
+	if cfg.SkillsFile != "" {
+		skills, err = tags.LoadFile(cfg.SkillsFile)
+	}
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+
+	// One HTTP client for all API sources so retries and rate limits are
+	// shared per host.
+	httpClient := http_client.New(http_client.Config{
+		Timeout:      cfg.HTTPTimeout,
+		MaxRetries:   cfg.HTTPMaxRetries,
+		RateInterval: cfg.HTTPRateInterval,
+	})
+
+	var fetchers []services.JobFetcher
+	if cfg.RapidAPIKey != "" && cfg.SearchProfilesFile != "" {
+		profiles, err := config.LoadSearchProfiles(cfg.SearchProfilesFile)
+		if err != nil {
+			e.Logger.Fatal(err)
+		}
+		fetchers = append(fetchers, apiFetchers(cfg, profiles, httpClient)...)
+	}
+	if cfg.CareerPagesFile != "" {
+		pages, err := config.LoadCareerPages(cfg.CareerPagesFile)
+		if err != nil {
+			e.Logger.Fatal(err)
+		}
+		fetchers = append(fetchers, careersFetcher(cfg, pages, httpClient))
+	}
+	if cfg.ATSBoardsFile != "" {
+		boards, err := config.LoadATSBoards(cfg.ATSBoardsFile)
+		if err != nil {
+			e.Logger.Fatal(err)
+		}
+		fetchers = append(fetchers, atsFetchers(boards, httpClient)...)
+	}
+	if cfg.CSVFile != "" {
+		fetchers = append(fetchers, csv_client.NewCSVClient(cfg.CSVFile, cfg.CSVHasHeader, cfg.CSVWorkers))
+	}
+
+	moderation, err := services.ParseModerationRules(cfg.ModeratedSources, cfg.TrustedSources, cfg.TrustedCompanies)
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+
+	history := services.NewRunHistory(store)
+	companies := services.NewCompanyService(store)
+
+	ingestor := services.NewIngestor(js, retention, []services.JobEnricher{
+		services.DescriptionEnricher(),
+		services.SalaryEnricher(salaries),
+		services.LocationEnricher(places),
+		services.TypeEnricher(mapping.NewMapper()),
+		services.TagEnricher(skills),
+	}, fetchers...).WithHistory(history).WithModeration(moderation).WithCompanies(companies).WithEvents(bus)
+
+	if cfg.SpamFilter {
+		rules, err := spam.Bundled()
+		if cfg.SpamRulesFile != "" {
+			rules, err = spam.LoadFile(cfg.SpamRulesFile)
+		}
+		if err != nil {
+			e.Logger.Fatal(err)
+		}
+
+		filter := services.NewSpamFilter(store, spam.NewClassifier(rules, cfg.SpamReviewAt, cfg.SpamRejectAt))
+		if err := filter.Train(); err != nil {
+			e.Logger.Fatal(err)
+		}
+		go filter.Run(ctx, cfg.SpamTrainInterval)
+		ingestor.WithSpamFilter(filter)
+	}
+	go ingestor.Run(ctx, cfg.IngestInterval)
+
+	jh := handlers.NewJobHandler(js, places)
+	bh := handlers.NewBackupHandler(snapshotter)
+	sh := handlers.NewSourceHandler(history, httpClient)
+	rh := handlers.NewReviewHandler(services.NewReviewService(store).WithEvents(bus))
+	ch := handlers.NewCompanyHandler(companies)
+
+	employers := services.NewEmployerService(store, companies)
+	employers.SessionTTL = cfg.EmployerSessionTTL
+	eh := handlers.NewEmployerHandler(employers)
+	postings := services.NewPostingService(store, []services.JobEnricher{
+		services.SalaryEnricher(salaries),
+		services.LocationEnricher(places),
+		services.TagEnricher(skills),
+	}).WithEvents(bus)
+	ph := handlers.NewPostingHandler(postings)
+
+	// Paid features are off unless a payment provider is configured.
+	provider, err := payments.New(cfg.PaymentProvider, cfg.PaymentWebhookSecret, cfg.AllowFakePayments)
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+	var bl *handlers.BillingHandler
+	if provider != nil {
+		if _, ok := provider.(*payments.Fake); ok {
+			log.Printf("🔥 using the fake payment provider: paid features are free")
+		}
+		billing := services.NewBillingService(store, provider, postings, []services.Plan{
+			{
+				Feature:     services.Pin,
+				Label:       "Pinned listing",
+				AmountCents: int64(cfg.PinPriceCents),
+				Currency:    cfg.FeatureCurrency,
+				Duration:    cfg.PinDuration,
+			},
+			{
+				Feature:     services.Highlight,
+				Label:       "Highlighted listing",
+				AmountCents: int64(cfg.HighlightPriceCents),
+				Currency:    cfg.FeatureCurrency,
+				Duration:    cfg.HighlightDuration,
+			},
+		})
+		bl = handlers.NewBillingHandler(billing, postings, provider)
+		ph.Billing = true
+	}
+
+	var mailer mail.Mailer = mail.Log{}
+	if cfg.SMTPAddr != "" {
+		mailer = mail.NewSMTP(cfg.SMTPAddr, cfg.MailFrom, cfg.SMTPUser, cfg.SMTPPassword)
+	}
+	applications := services.NewApplicationService(store, blobs, mailer, postings, cfg.BaseURL)
+	applications.MaxResumeBytes = cfg.MaxResumeBytes
+	ah := handlers.NewApplicationHandler(applications, js, postings, int64(cfg.MaxResumeBytes))
+
+	seekers := services.NewSeekerProfileService(store, skills)
+	seekers.TTL = cfg.SeekerProfileTTL
+	seekers.MaxResumeBytes = cfg.MaxResumeBytes
+	rs := handlers.NewResumeHandler(seekers, int64(cfg.MaxResumeBytes))
+
+	similar := services.NewSimilarService(store)
+	go similar.Run(ctx, cfg.SimilarInterval)
+	sm := handlers.NewSimilarHandler(similar)
+	st := handlers.NewStreamHandler(jh, js, bus)
+
 	// Setting Routes
-	handlers.SetupRoutes(e, jh)
+	handlers.SetupRoutes(e, jh, bh, sh, rh, ch, eh, ph, bl, ah, rs, sm, st, handlers.AdminAuth(cfg.AdminUser, cfg.AdminPassword))
 
 	// Start Server
 	e.Logger.Fatal(e.Start(":8080"))
 }
+
+// apiFetchers builds one fetcher per API source that runs all of the
+// source's search profiles in a single ingest.
+func apiFetchers(cfg config.Config, profiles config.SearchProfiles, hc *http_client.Client) []services.JobFetcher {
+	var fetchers []services.JobFetcher
+
+	var indeed []services.JobFetcher
+	for _, p := range profiles.Indeed {
+		client := indeed_client.NewIndeedClient(cfg.RapidAPIKey, indeed_client.SearchProfile{
+			Name:     p.Name,
+			Query:    p.Query,
+			Location: p.Location,
+			Country:  p.Country,
+			JobType:  p.JobType,
+			Sort:     p.Sort,
+			Radius:   p.Radius,
+			FromDays: p.FromDays,
+			MaxRows:  p.MaxRows,
+		}, hc)
+		indeed = append(indeed, client.WithPolling(cfg.IndeedPollInterval, cfg.IndeedTimeout))
+	}
+	if len(indeed) > 0 {
+		fetchers = append(fetchers, services.CombineFetchers(domain.Indeed, indeed...))
+	}
+
+	var linkedin []services.JobFetcher
+	for _, p := range profiles.Linkedin {
+		window := linkedin_client.LastWeek
+		if p.FromDays == 1 {
+			window = linkedin_client.LastDay
+		}
+		linkedin = append(linkedin, linkedin_client.NewLinkedinClient(cfg.RapidAPIKey, linkedin_client.Query{
+			Title:    p.Query,
+			Location: p.Location,
+			Window:   window,
+			MaxPages: p.MaxPages,
+		}, hc))
+	}
+	if len(linkedin) > 0 {
+		fetchers = append(fetchers, services.CombineFetchers(domain.LinkedIn, linkedin...))
+	}
+
+	return fetchers
+}
+
+// careersFetcher scrapes all configured careers pages as one source.
+func careersFetcher(cfg config.Config, pages []config.CareerPage, hc *http_client.Client) services.JobFetcher {
+	var sitePages []careers_client.Page
+	for _, p := range pages {
+		page := careers_client.Page{Name: p.Name, URL: p.URL, Company: p.Company}
+		if s := p.Selectors; s != nil {
+			page.Selectors = &careers_client.Selectors{
+				Job:         s.Job,
+				Title:       s.Title,
+				Link:        s.Link,
+				Location:    s.Location,
+				Description: s.Description,
+				Type:        s.Type,
+			}
+		}
+		sitePages = append(sitePages, page)
+	}
+
+	return careers_client.NewCareersClient(sitePages, hc).WithCrawling(cfg.CrawlUserAgent, cfg.CrawlDelay)
+}
+
+// atsFetchers builds one fetcher per applicant tracking system that reads
+// all of its configured boards in a single ingest.
+func atsFetchers(boards config.ATSBoards, hc *http_client.Client) []services.JobFetcher {
+	var fetchers []services.JobFetcher
+
+	var greenhouse []services.JobFetcher
+	for _, b := range boards.Greenhouse {
+		greenhouse = append(greenhouse, greenhouse_client.NewGreenhouseClient(b.Token, b.Company, hc))
+	}
+	if len(greenhouse) > 0 {
+		fetchers = append(fetchers, services.CombineFetchers(domain.Greenhouse, greenhouse...))
+	}
+
+	var lever []services.JobFetcher
+	for _, b := range boards.Lever {
+		lever = append(lever, lever_client.NewLeverClient(b.Token, b.Company, hc))
+	}
+	if len(lever) > 0 {
+		fetchers = append(fetchers, services.CombineFetchers(domain.Lever, lever...))
+	}
+
+	var ashby []services.JobFetcher
+	for _, b := range boards.Ashby {
+		ashby = append(ashby, ashby_client.NewAshbyClient(b.Token, b.Company, hc))
+	}
+	if len(ashby) > 0 {
+		fetchers = append(fetchers, services.CombineFetchers(domain.Ashby, ashby...))
+	}
+
+	return fetchers
+}
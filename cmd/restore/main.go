@@ -0,0 +1,27 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/igorrize/htmxjb/db"
+)
+
+// restore swaps a snapshot in as the live database. Stop the server first.
+func main() {
+	from := flag.String("from", "", "path to the snapshot to restore")
+	to := flag.String("db", "data/jobs.db", "path to the database to replace")
+	flag.Parse()
+
+	if *from == "" {
+		log.Fatal("🔥 -from is required")
+	}
+
+	previous, err := db.Restore(*from, *to)
+	if previous != "" {
+		log.Printf("✅ The replaced database was kept at %s", previous)
+	}
+	if err != nil {
+		log.Fatalf("🔥 restore failed: %s", err)
+	}
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Board is a company's public job board on an applicant tracking system.
+// Token is the board name in its URL, e.g. "acme" for
+// boards.greenhouse.io/acme.
+type Board struct {
+	Token   string `json:"token"`
+	Company string `json:"company"`
+}
+
+// ATSBoards lists the boards to read per applicant tracking system.
+type ATSBoards struct {
+	Greenhouse []Board `json:"greenhouse"`
+	Lever      []Board `json:"lever"`
+	Ashby      []Board `json:"ashby"`
+}
+
+// LoadATSBoards reads the board lists from a JSON file. Boards without a
+// company are named after their token.
+func LoadATSBoards(path string) (ATSBoards, error) {
+	var boards ATSBoards
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return boards, fmt.Errorf("failed to read ats boards: %w", err)
+	}
+	if err := json.Unmarshal(data, &boards); err != nil {
+		return boards, fmt.Errorf("failed to parse ats boards %s: %w", path, err)
+	}
+
+	for name, list := range map[string][]Board{
+		"greenhouse": boards.Greenhouse,
+		"lever":      boards.Lever,
+		"ashby":      boards.Ashby,
+	} {
+		for i := range list {
+			list[i].Token = strings.TrimSpace(list[i].Token)
+			if list[i].Token == "" || strings.Contains(list[i].Token, "/") {
+				return boards, fmt.Errorf("%s board %d in %s has an invalid token %q", name, i+1, path, list[i].Token)
+			}
+			if list[i].Company == "" {
+				list[i].Company = list[i].Token
+			}
+		}
+	}
+
+	return boards, nil
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+)
+
+// CareerPage is a company careers page scraped for JobPosting JSON-LD.
+// Selectors are only used when the page has none.
+type CareerPage struct {
+	Name      string         `json:"name"`
+	URL       string         `json:"url"`
+	Company   string         `json:"company"`
+	Selectors *PageSelectors `json:"selectors"`
+}
+
+// PageSelectors are CSS selectors for one posting on a careers page and
+// its fields within it.
+type PageSelectors struct {
+	Job         string `json:"job"`
+	Title       string `json:"title"`
+	Link        string `json:"link"`
+	Location    string `json:"location"`
+	Description string `json:"description"`
+	Type        string `json:"type"`
+}
+
+// LoadCareerPages reads the careers pages list from a JSON file. Pages
+// without a name are named after their host.
+func LoadCareerPages(path string) ([]CareerPage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read career pages: %w", err)
+	}
+
+	var pages []CareerPage
+	if err := json.Unmarshal(data, &pages); err != nil {
+		return nil, fmt.Errorf("failed to parse career pages %s: %w", path, err)
+	}
+
+	for i := range pages {
+		u, err := url.Parse(pages[i].URL)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("career page %d in %s has an invalid url %q", i+1, path, pages[i].URL)
+		}
+		if pages[i].Name == "" {
+			pages[i].Name = u.Host
+		}
+	}
+
+	return pages, nil
+}
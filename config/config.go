@@ -0,0 +1,228 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Config holds runtime settings read from the environment.
+type Config struct {
+	DBName string
+
+	AdminUser     string
+	AdminPassword string
+
+	BackupDir      string
+	BackupInterval time.Duration
+	BackupRetain   int
+
+	IngestInterval    time.Duration
+	IndeedTTL         time.Duration
+	LinkedinTTL       time.Duration
+	CsvTTL            time.Duration
+	CareersTTL        time.Duration
+	ATSTTL            time.Duration
+	ArchiveAfter      time.Duration
+	PurgeAfter        time.Duration
+	RetentionInterval time.Duration
+
+	SalaryBaseCurrency string
+	SalaryRates        string
+
+	SkillsFile string
+
+	ModeratedSources string
+	TrustedSources   string
+	TrustedCompanies string
+
+	SpamFilter        bool
+	SpamRulesFile     string
+	SpamReviewAt      float64
+	SpamRejectAt      float64
+	SpamTrainInterval time.Duration
+
+	EmployerSessionTTL time.Duration
+
+	PaymentProvider      string
+	AllowFakePayments    bool
+	PaymentWebhookSecret string
+	FeatureCurrency      string
+	PinPriceCents        int
+	PinDuration          time.Duration
+	HighlightPriceCents  int
+	HighlightDuration    time.Duration
+
+	BaseURL        string
+	UploadDir      string
+	MaxResumeBytes int
+	SMTPAddr       string
+	SMTPUser       string
+	SMTPPassword   string
+	MailFrom       string
+
+	SeekerProfileTTL time.Duration
+	SimilarInterval  time.Duration
+
+	HTTPTimeout      time.Duration
+	HTTPMaxRetries   int
+	HTTPRateInterval time.Duration
+
+	RapidAPIKey        string
+	SearchProfilesFile string
+	IndeedPollInterval time.Duration
+	IndeedTimeout      time.Duration
+
+	CareerPagesFile string
+	CrawlUserAgent  string
+	CrawlDelay      time.Duration
+
+	ATSBoardsFile string
+
+	CSVFile      string
+	CSVHasHeader bool
+	CSVWorkers   int
+}
+
+func Load() Config {
+	return Config{
+		DBName: getEnv("DB_NAME", "jobs.db"),
+
+		AdminUser:     getEnv("ADMIN_USER", ""),
+		AdminPassword: getEnv("ADMIN_PASSWORD", ""),
+
+		BackupDir:      getEnv("BACKUP_DIR", "data/backups"),
+		BackupInterval: getDuration("BACKUP_INTERVAL", 24*time.Hour),
+		BackupRetain:   getInt("BACKUP_RETAIN", 7),
+
+		IngestInterval:    getDuration("INGEST_INTERVAL", 6*time.Hour),
+		IndeedTTL:         getDuration("JOB_TTL_INDEED", 30*24*time.Hour),
+		LinkedinTTL:       getDuration("JOB_TTL_LINKEDIN", 30*24*time.Hour),
+		CsvTTL:            getDuration("JOB_TTL_CSV", 60*24*time.Hour),
+		CareersTTL:        getDuration("JOB_TTL_CAREERS", 30*24*time.Hour),
+		ATSTTL:            getDuration("JOB_TTL_ATS", 30*24*time.Hour),
+		ArchiveAfter:      getDuration("ARCHIVE_AFTER", 14*24*time.Hour),
+		PurgeAfter:        getDuration("PURGE_AFTER", 180*24*time.Hour),
+		RetentionInterval: getDuration("RETENTION_INTERVAL", time.Hour),
+
+		SalaryBaseCurrency: getEnv("SALARY_BASE_CURRENCY", "USD"),
+		SalaryRates:        getEnv("SALARY_RATES", ""),
+
+		SkillsFile: getEnv("SKILLS_FILE", ""),
+
+		ModeratedSources: getEnv("MODERATED_SOURCES", ""),
+		TrustedSources:   getEnv("TRUSTED_SOURCES", ""),
+		TrustedCompanies: getEnv("TRUSTED_COMPANIES", ""),
+
+		SpamFilter:        getBool("SPAM_FILTER", false),
+		SpamRulesFile:     getEnv("SPAM_RULES_FILE", ""),
+		SpamReviewAt:      getFloat("SPAM_REVIEW_AT", 0.5),
+		SpamRejectAt:      getFloat("SPAM_REJECT_AT", 0.9),
+		SpamTrainInterval: getDuration("SPAM_TRAIN_INTERVAL", 24*time.Hour),
+
+		EmployerSessionTTL: getDuration("EMPLOYER_SESSION_TTL", 30*24*time.Hour),
+
+		PaymentProvider:      getEnv("PAYMENT_PROVIDER", ""),
+		AllowFakePayments:    getBool("ALLOW_FAKE_PAYMENTS", false),
+		PaymentWebhookSecret: getEnv("PAYMENT_WEBHOOK_SECRET", ""),
+		FeatureCurrency:      getEnv("FEATURE_CURRENCY", "USD"),
+		PinPriceCents:        getInt("PIN_PRICE_CENTS", 9900),
+		PinDuration:          getDuration("PIN_DURATION", 30*24*time.Hour),
+		HighlightPriceCents:  getInt("HIGHLIGHT_PRICE_CENTS", 4900),
+		HighlightDuration:    getDuration("HIGHLIGHT_DURATION", 30*24*time.Hour),
+
+		BaseURL:        getEnv("BASE_URL", "http://localhost:8080"),
+		UploadDir:      getEnv("UPLOAD_DIR", "data/uploads"),
+		MaxResumeBytes: getInt("MAX_RESUME_BYTES", 5<<20),
+		SMTPAddr:       getEnv("SMTP_ADDR", ""),
+		SMTPUser:       getEnv("SMTP_USER", ""),
+		SMTPPassword:   getEnv("SMTP_PASSWORD", ""),
+		MailFrom:       getEnv("MAIL_FROM", "jobs@localhost"),
+
+		SeekerProfileTTL: getDuration("SEEKER_PROFILE_TTL", 30*24*time.Hour),
+		SimilarInterval:  getDuration("SIMILAR_INTERVAL", time.Hour),
+
+		HTTPTimeout:      getDuration("HTTP_TIMEOUT", 30*time.Second),
+		HTTPMaxRetries:   getInt("HTTP_MAX_RETRIES", 3),
+		HTTPRateInterval: getDuration("HTTP_RATE_INTERVAL", 200*time.Millisecond),
+
+		RapidAPIKey:        getEnv("RAPIDAPI_KEY", ""),
+		SearchProfilesFile: getEnv("SEARCH_PROFILES_FILE", ""),
+		IndeedPollInterval: getDuration("INDEED_POLL_INTERVAL", 10*time.Second),
+		IndeedTimeout:      getDuration("INDEED_TIMEOUT", 5*time.Minute),
+
+		CareerPagesFile: getEnv("CAREER_PAGES_FILE", ""),
+		CrawlUserAgent:  getEnv("CRAWL_USER_AGENT", "htmxjb-bot/1.0"),
+		CrawlDelay:      getDuration("CRAWL_DELAY", 2*time.Second),
+
+		ATSBoardsFile: getEnv("ATS_BOARDS_FILE", ""),
+
+		CSVFile:      getEnv("CSV_FILE", ""),
+		CSVHasHeader: getBool("CSV_HAS_HEADER", true),
+		CSVWorkers:   getInt("CSV_WORKERS", 4),
+	}
+}
+
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
+
+func getInt(key string, fallback int) int {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("🔥 invalid %s=%q, using %d", key, value, fallback)
+		return fallback
+	}
+	return n
+}
+
+func getFloat(key string, fallback float64) float64 {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Printf("🔥 invalid %s=%q, using %g", key, value, fallback)
+		return fallback
+	}
+	return f
+}
+
+func getBool(key string, fallback bool) bool {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("🔥 invalid %s=%q, using %t", key, value, fallback)
+		return fallback
+	}
+	return b
+}
+
+func getDuration(key string, fallback time.Duration) time.Duration {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("🔥 invalid %s=%q, using %s", key, value, fallback)
+		return fallback
+	}
+	return d
+}
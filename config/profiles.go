@@ -0,0 +1,57 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// SearchProfile is one saved search against an API source. Sources ignore
+// fields they do not support.
+type SearchProfile struct {
+	Name     string `json:"name"`
+	Query    string `json:"query"`
+	Location string `json:"location"`
+	Country  string `json:"country"`
+	JobType  string `json:"job_type"`
+	Sort     string `json:"sort"`
+	Radius   int    `json:"radius"`
+	FromDays int    `json:"from_days"`
+	MaxRows  int    `json:"max_rows"`
+	MaxPages int    `json:"max_pages"`
+}
+
+// SearchProfiles lists the searches run for each source on every ingest.
+type SearchProfiles struct {
+	Indeed   []SearchProfile `json:"indeed"`
+	Linkedin []SearchProfile `json:"linkedin"`
+}
+
+// LoadSearchProfiles reads search profiles from a JSON file. Profiles
+// without a name are named after their source and position.
+func LoadSearchProfiles(path string) (SearchProfiles, error) {
+	var profiles SearchProfiles
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return profiles, fmt.Errorf("failed to read search profiles: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return profiles, fmt.Errorf("failed to parse search profiles %s: %w", path, err)
+	}
+
+	nameProfiles("indeed", profiles.Indeed)
+	nameProfiles("linkedin", profiles.Linkedin)
+
+	return profiles, nil
+}
+
+func nameProfiles(source string, profiles []SearchProfile) {
+	for i := range profiles {
+		if profiles[i].Name == "" {
+			profiles[i].Name = source + "-" + strconv.Itoa(i+1)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const snapshotPrefix = "jobs-"
+
+// Backup writes a consistent copy of the database to destPath using
+// VACUUM INTO. It runs on the read pool, so ingestion keeps writing while
+// the snapshot is taken.
+func (s *SQLiteStore) Backup(destPath string) error {
+	if _, err := os.Stat(destPath); err == nil {
+		return fmt.Errorf("backup destination %s already exists", destPath)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	if _, err := s.ReadDb.Exec("VACUUM INTO ?", destPath); err != nil {
+		return fmt.Errorf("failed to back up database: %w", err)
+	}
+
+	return nil
+}
+
+// ReadSchemaVersion opens the database file at path read-only and returns
+// its schema version after checking that it is an intact jobs database.
+func ReadSchemaVersion(path string) (int, error) {
+	if _, err := os.Stat(path); err != nil {
+		return 0, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+
+	conn, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?mode=ro", path))
+	if err != nil {
+		return 0, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer conn.Close()
+
+	var integrity string
+	if err := conn.QueryRow("PRAGMA quick_check").Scan(&integrity); err != nil {
+		return 0, fmt.Errorf("failed to check snapshot integrity: %w", err)
+	}
+	if integrity != "ok" {
+		return 0, fmt.Errorf("snapshot failed integrity check: %s", integrity)
+	}
+
+	var tables int
+	if err := conn.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name IN ('jobs', 'migrations')").Scan(&tables); err != nil {
+		return 0, fmt.Errorf("failed to inspect snapshot: %w", err)
+	}
+	if tables != 2 {
+		return 0, fmt.Errorf("snapshot is not a jobs database")
+	}
+
+	var version int
+	if err := conn.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read snapshot schema version: %w", err)
+	}
+
+	return version, nil
+}
+
+// Restore replaces the database at dbPath with the snapshot at
+// snapshotPath. The server must not be running. Snapshots from a newer
+// schema than this build are rejected; older ones are migrated on the next
+// Open. The database it replaces is moved aside, with its WAL, to the path
+// it returns, so a wrong restore can be undone; there is none if dbPath
+// did not exist.
+func Restore(snapshotPath, dbPath string) (string, error) {
+	version, err := ReadSchemaVersion(snapshotPath)
+	if err != nil {
+		return "", err
+	}
+	if version == 0 || version > SchemaVersion() {
+		return "", fmt.Errorf("snapshot schema version %d is not supported (current %d)", version, SchemaVersion())
+	}
+
+	tmpPath := dbPath + ".restore"
+	if err := copyFile(snapshotPath, tmpPath); err != nil {
+		return "", fmt.Errorf("failed to stage snapshot: %w", err)
+	}
+
+	var previous string
+	if _, err := os.Stat(dbPath); err == nil {
+		previous = dbPath + ".pre-restore-" + time.Now().UTC().Format("20060102T150405.000Z")
+	}
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		if previous == "" {
+			err = os.Remove(dbPath + suffix)
+		} else {
+			err = os.Rename(dbPath+suffix, previous+suffix)
+		}
+		if err != nil && !os.IsNotExist(err) {
+			os.Remove(tmpPath)
+			return previous, fmt.Errorf("failed to move %s aside: %w", dbPath+suffix, err)
+		}
+	}
+
+	if err := os.Rename(tmpPath, dbPath); err != nil {
+		os.Remove(tmpPath)
+		return previous, fmt.Errorf("failed to swap in snapshot: %w", err)
+	}
+
+	log.Printf("✅ Restored %s from %s (schema version %d)", dbPath, snapshotPath, version)
+
+	return previous, nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0640)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// Snapshotter takes timestamped snapshots into Dir and keeps only the
+// Retain most recent ones.
+type Snapshotter struct {
+	Store    *SQLiteStore
+	Dir      string
+	Interval time.Duration
+	Retain   int
+
+	mu sync.Mutex
+}
+
+func NewSnapshotter(store *SQLiteStore, dir string, interval time.Duration, retain int) *Snapshotter {
+	return &Snapshotter{
+		Store:    store,
+		Dir:      dir,
+		Interval: interval,
+		Retain:   retain,
+	}
+}
+
+// Snapshot takes a snapshot now, prunes old ones and returns the path of
+// the new file.
+func (sn *Snapshotter) Snapshot() (string, error) {
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+
+	name := snapshotPrefix + time.Now().UTC().Format("20060102T150405.000Z") + ".db"
+	path := filepath.Join(sn.Dir, name)
+
+	if err := sn.Store.Backup(path); err != nil {
+		return "", err
+	}
+
+	if err := sn.prune(); err != nil {
+		log.Printf("🔥 failed to prune snapshots: %s", err)
+	}
+
+	return path, nil
+}
+
+// List returns snapshot file names, newest first.
+func (sn *Snapshotter) List() ([]string, error) {
+	entries, err := os.ReadDir(sn.Dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), snapshotPrefix) || filepath.Ext(entry.Name()) != ".db" {
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+
+	// Timestamps are fixed-width UTC, so lexical order is chronological.
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+
+	return names, nil
+}
+
+func (sn *Snapshotter) prune() error {
+	if sn.Retain <= 0 {
+		return nil
+	}
+
+	names, err := sn.List()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names[min(sn.Retain, len(names)):] {
+		if err := os.Remove(filepath.Join(sn.Dir, name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Run takes a snapshot every Interval until ctx is cancelled.
+func (sn *Snapshotter) Run(ctx context.Context) {
+	if sn.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(sn.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			path, err := sn.Snapshot()
+			if err != nil {
+				log.Printf("🔥 scheduled snapshot failed: %s", err)
+				continue
+			}
+			log.Printf("✅ Snapshot written to %s", path)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "jobs.db")
+
+	store, err := Open(dbPath)
+	require.NoError(t, err)
+
+	_, err = store.Exec("INSERT INTO jobs (external_id, title, description, type, source) VALUES (?, ?, ?, ?, ?)", "1", "Before backup", "", 0, 2)
+	require.NoError(t, err)
+
+	snapshot := filepath.Join(dir, "backups", "snapshot.db")
+	require.NoError(t, store.Backup(snapshot))
+	assert.Error(t, store.Backup(snapshot), "existing snapshot must not be overwritten")
+
+	_, err = store.Exec("INSERT INTO jobs (external_id, title, description, type, source) VALUES (?, ?, ?, ?, ?)", "2", "After backup", "", 0, 2)
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	version, err := ReadSchemaVersion(snapshot)
+	require.NoError(t, err)
+	assert.Equal(t, SchemaVersion(), version)
+
+	previous, err := Restore(snapshot, dbPath)
+	require.NoError(t, err)
+	assert.Contains(t, previous, dbPath+".pre-restore-")
+
+	store, err = Open(dbPath)
+	require.NoError(t, err)
+	defer store.Close()
+
+	var count int
+	require.NoError(t, store.QueryRow("SELECT COUNT(*) FROM jobs").Scan(&count))
+	assert.Equal(t, 1, count)
+
+	// The replaced database is kept, down to its last write.
+	old, err := Open(previous)
+	require.NoError(t, err)
+	defer old.Close()
+	require.NoError(t, old.QueryRow("SELECT COUNT(*) FROM jobs").Scan(&count))
+	assert.Equal(t, 2, count)
+}
+
+func TestRestoreRejectsForeignFile(t *testing.T) {
+	dir := t.TempDir()
+	bogus := filepath.Join(dir, "bogus.db")
+	require.NoError(t, os.WriteFile(bogus, []byte("not a database"), 0644))
+
+	_, err := Restore(bogus, filepath.Join(dir, "jobs.db"))
+	assert.Error(t, err)
+}
+
+func TestSnapshotterRetention(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := Open(filepath.Join(dir, "jobs.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	sn := NewSnapshotter(store, filepath.Join(dir, "backups"), time.Hour, 2)
+	for i := 0; i < 3; i++ {
+		_, err := sn.Snapshot()
+		require.NoError(t, err)
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	names, err := sn.List()
+	require.NoError(t, err)
+	assert.Len(t, names, 2)
+}
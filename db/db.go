@@ -1,128 +1,267 @@
 package db
 
 import (
+	"container/list"
 	"database/sql"
 	"fmt"
 	_ "github.com/mattn/go-sqlite3"
 	"log"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sync"
 )
 
+const (
+	// BusyTimeoutMs is how long a connection waits on a locked database
+	// before giving up with SQLITE_BUSY.
+	BusyTimeoutMs = 5000
+)
+
+// SQLiteStore keeps a single writer connection and a separate pool of
+// read-only connections. With WAL enabled readers never block on the
+// writer, so page loads keep working while an ingest is running.
 type SQLiteStore struct {
-	Db *sql.DB
+	Db     *sql.DB
+	ReadDb *sql.DB
+
+	writeStmts *stmtCache
+	readStmts  *stmtCache
 }
 
 type Store interface {
 	Query(query string, args ...interface{}) (*sql.Rows, error)
 	QueryRow(query string, args ...interface{}) *sql.Row
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	WithTx(fn func(tx *sql.Tx) error) error
 	Close() error
 }
 
-func NewStore(dbName string) (SQLiteStore, error) {
+func NewStore(dbName string) (*SQLiteStore, error) {
 	if err := os.MkdirAll("data", 0755); err != nil {
-		return SQLiteStore{}, fmt.Errorf("failed to create data directory: %w", err)
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
 	}
 
-	dbPath := filepath.Join("data", dbName)
+	return Open(filepath.Join("data", dbName))
+}
 
-	Db, err := getConnection(dbPath)
+// Open opens the database at dbPath, applies connection pragmas and runs
+// pending migrations.
+func Open(dbPath string) (*SQLiteStore, error) {
+	Db, err := getConnection(writerDSN(dbPath), 1)
 	if err != nil {
-		return SQLiteStore{}, err
+		return nil, err
 	}
 
 	if err := createMigrations(dbPath, Db); err != nil {
-		return SQLiteStore{}, err
+		Db.Close()
+		return nil, err
 	}
 
-	return SQLiteStore{
-		Db,
+	ReadDb, err := getConnection(readerDSN(dbPath), runtime.NumCPU())
+	if err != nil {
+		Db.Close()
+		return nil, err
+	}
+
+	return &SQLiteStore{
+		Db:         Db,
+		ReadDb:     ReadDb,
+		writeStmts: newStmtCache(Db),
+		readStmts:  newStmtCache(ReadDb),
 	}, nil
 }
 
+// Query runs a read query on the read pool.
+func (s *SQLiteStore) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	stmt, release, err := s.readStmts.prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return stmt.Query(args...)
+}
+
+// QueryRow runs a single-row read query on the read pool.
+func (s *SQLiteStore) QueryRow(query string, args ...interface{}) *sql.Row {
+	stmt, release, err := s.readStmts.prepare(query)
+	if err != nil {
+		// Let database/sql report the prepare error through Row.Scan.
+		return s.ReadDb.QueryRow(query, args...)
+	}
+	defer release()
+	return stmt.QueryRow(args...)
+}
+
+// Exec runs a write statement on the writer connection.
+func (s *SQLiteStore) Exec(query string, args ...interface{}) (sql.Result, error) {
+	stmt, release, err := s.writeStmts.prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return stmt.Exec(args...)
+}
+
+// WithTx runs fn inside a write transaction. The transaction is committed
+// if fn returns nil and rolled back otherwise.
+func (s *SQLiteStore) WithTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.Db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
 func (s *SQLiteStore) Close() error {
 	if s.Db == nil {
 		return fmt.Errorf("database connection is not initialized")
 	}
+
+	s.readStmts.close()
+	s.writeStmts.close()
+
+	if s.ReadDb != nil {
+		if err := s.ReadDb.Close(); err != nil {
+			return err
+		}
+	}
 	return s.Db.Close()
 }
 
-func getConnection(dbName string) (*sql.DB, error) {
-	var (
-		err error
-		db  *sql.DB
+func writerDSN(dbPath string) string {
+	return fmt.Sprintf(
+		"file:%s?_journal_mode=WAL&_busy_timeout=%d&_foreign_keys=on&_synchronous=NORMAL&_txlock=immediate",
+		dbPath, BusyTimeoutMs,
 	)
+}
 
-	if db != nil {
-		return db, nil
-	}
+func readerDSN(dbPath string) string {
+	return fmt.Sprintf(
+		"file:%s?mode=ro&_busy_timeout=%d&_foreign_keys=on",
+		dbPath, BusyTimeoutMs,
+	)
+}
 
-	db, err = sql.Open("sqlite3", dbName)
+func getConnection(dsn string, maxConns int) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("🔥 failed to connect to the database: %s", err)
 	}
 
+	db.SetMaxOpenConns(maxConns)
+	db.SetMaxIdleConns(maxConns)
+	db.SetConnMaxIdleTime(0)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("🔥 failed to connect to the database: %s", err)
+	}
+
 	log.Println("🚀 Connected Successfully to the Database")
 
 	return db, nil
 }
 
-func createMigrations(dbName string, db *sql.DB) error {
-	_, err := db.Exec(`
-		CREATE TABLE IF NOT EXISTS migrations (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			name TEXT NOT NULL UNIQUE,
-			executed_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		);
-	`)
+// maxCachedStmts bounds each pool's statement cache. Queries built per
+// request, such as IN lists, would otherwise keep a prepared statement
+// for every shape they take for the life of the process.
+const maxCachedStmts = 128
+
+// stmtCache keeps prepared statements keyed by their SQL text so hot
+// queries are parsed once per pool instead of on every call. The least
+// recently used statement is closed once the cache is full.
+type stmtCache struct {
+	mu    sync.Mutex
+	db    *sql.DB
+	size  int
+	stmts map[string]*list.Element
+	// lru holds *cachedStmt, the most recently used first.
+	lru *list.List
+}
+
+// cachedStmt counts the callers still using a statement, so eviction
+// never closes one between prepare and its query.
+type cachedStmt struct {
+	query   string
+	stmt    *sql.Stmt
+	users   int
+	evicted bool
+}
+
+func newStmtCache(db *sql.DB) *stmtCache {
+	return &stmtCache{
+		db:    db,
+		size:  maxCachedStmts,
+		stmts: make(map[string]*list.Element),
+		lru:   list.New(),
+	}
+}
+
+// prepare returns the statement for query and a func the caller runs once
+// it has started the query. Rows already opened keep a closed statement
+// alive until they are closed.
+func (c *stmtCache) prepare(query string) (*sql.Stmt, func(), error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.stmts[query]; ok {
+		c.lru.MoveToFront(e)
+		cs := e.Value.(*cachedStmt)
+		cs.users++
+		return cs.stmt, c.releaser(cs), nil
+	}
+
+	stmt, err := c.db.Prepare(query)
 	if err != nil {
-		return fmt.Errorf("failed to create migrations table: %w", err)
-	}
-
-	migrations := []struct {
-		name string
-		stmt string
-	}{
-		{
-			name: "create_jobs_table",
-			stmt: `
-				CREATE TABLE IF NOT EXISTS jobs (
-					id INTEGER PRIMARY KEY AUTOINCREMENT,
-					type INTEGER NOT NULL,
-					title VARCHAR(64) NOT NULL,
-          source INTEGER NOT NULL,
-          external_id INTEGER NOT NULL,
-					description VARCHAR(255) NULL,
-					created_at DATETIME default CURRENT_TIMESTAMP);`,
-		},
-		{
-			name: "add_updated_at_column_to_jobs",
-			stmt: `
-				ALTER TABLE jobs ADD COLUMN updated_at DATETIME default CURRENT_TIMESTAMP;`,
-		},
-	}
-
-	for _, migration := range migrations {
-		var count int
-		err := db.QueryRow("SELECT COUNT(*) FROM migrations WHERE name = ?", migration.name).Scan(&count)
-		if err != nil {
-			return fmt.Errorf("failed to check migration status: %w", err)
+		return nil, nil, err
+	}
+	cs := &cachedStmt{query: query, stmt: stmt, users: 1}
+	c.stmts[query] = c.lru.PushFront(cs)
+
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		old := oldest.Value.(*cachedStmt)
+		delete(c.stmts, old.query)
+		old.evicted = true
+		if old.users == 0 {
+			old.stmt.Close()
 		}
+	}
 
-		if count == 0 {
-			_, err := db.Exec(migration.stmt)
-			if err != nil {
-				return fmt.Errorf("failed to execute migration %s: %w", migration.name, err)
-			}
+	return stmt, c.releaser(cs), nil
+}
 
-			_, err = db.Exec("INSERT INTO migrations (name) VALUES (?)", migration.name)
-			if err != nil {
-				return fmt.Errorf("failed to record migration %s: %w", migration.name, err)
-			}
+func (c *stmtCache) releaser(cs *cachedStmt) func() {
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
 
-			log.Printf("✅ Migration %s executed successfully", migration.name)
+		cs.users--
+		if cs.evicted && cs.users == 0 {
+			cs.stmt.Close()
 		}
 	}
+}
 
-	return nil
+func (c *stmtCache) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for query, e := range c.stmts {
+		e.Value.(*cachedStmt).stmt.Close()
+		delete(c.stmts, query)
+	}
+	c.lru.Init()
 }
@@ -0,0 +1,128 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+const (
+	importRows      = 100_000
+	importBatchSize = 1_000
+)
+
+func openBenchStore(b *testing.B) *SQLiteStore {
+	b.Helper()
+
+	store, err := Open(filepath.Join(b.TempDir(), "bench.db"))
+	if err != nil {
+		b.Fatalf("failed to open store: %v", err)
+	}
+	b.Cleanup(func() { store.Close() })
+
+	return store
+}
+
+// importJobs writes n rows in batched write transactions, the same way an
+// ingestion run would.
+func importJobs(store *SQLiteStore, n int) error {
+	for start := 0; start < n; start += importBatchSize {
+		err := store.WithTx(func(tx *sql.Tx) error {
+			stmt, err := tx.Prepare("INSERT INTO jobs (external_id, title, description, type, source) VALUES (?, ?, ?, ?, ?)")
+			if err != nil {
+				return err
+			}
+			defer stmt.Close()
+
+			for i := start; i < start+importBatchSize && i < n; i++ {
+				if _, err := stmt.Exec(i, fmt.Sprintf("Job %d", i), "Benchmark job", 0, 2); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readPage(store *SQLiteStore) error {
+	rows, err := store.Query("SELECT id, title, description FROM jobs ORDER BY created_at DESC LIMIT 50")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id                 int64
+			title, description string
+		)
+		if err := rows.Scan(&id, &title, &description); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func BenchmarkImport(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		store := openBenchStore(b)
+		b.StartTimer()
+
+		if err := importJobs(store, importRows); err != nil {
+			b.Fatalf("import failed: %v", err)
+		}
+	}
+}
+
+func BenchmarkReads(b *testing.B) {
+	store := openBenchStore(b)
+	if err := importJobs(store, importRows); err != nil {
+		b.Fatalf("import failed: %v", err)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := readPage(store); err != nil {
+				b.Errorf("read failed: %v", err)
+				return
+			}
+		}
+	})
+}
+
+// BenchmarkReadsDuringImport measures page reads from parallel readers
+// while a 100k row import holds the writer connection.
+func BenchmarkReadsDuringImport(b *testing.B) {
+	store := openBenchStore(b)
+
+	var importing atomic.Bool
+	importing.Store(true)
+	importErr := make(chan error, 1)
+	go func() {
+		defer importing.Store(false)
+		importErr <- importJobs(store, importRows)
+	}()
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := readPage(store); err != nil {
+				b.Errorf("read failed while importing=%v: %v", importing.Load(), err)
+				return
+			}
+		}
+	})
+	b.StopTimer()
+
+	if err := <-importErr; err != nil {
+		b.Fatalf("import failed: %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package db
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStmtCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "jobs.db")
+	store, err := Open(dbPath)
+	require.NoError(t, err)
+	defer store.Close()
+
+	_, err = store.Exec("INSERT INTO jobs (external_id, title, description, type, source) VALUES (?, ?, ?, ?, ?)", "1", "Go dev", "", 0, 2)
+	require.NoError(t, err)
+
+	// The cache gets a pool of its own so rows held open below do not
+	// starve the queries that evict their statement.
+	pool, err := getConnection(readerDSN(dbPath), 4)
+	require.NoError(t, err)
+	defer pool.Close()
+	cache := newStmtCache(pool)
+	cache.size = 2
+	defer cache.close()
+
+	query := func(n int) string {
+		return "SELECT title FROM jobs WHERE id IN (?" + strings.Repeat(", ?", n-1) + ")"
+	}
+
+	// Rows opened before their statement is evicted stay readable.
+	stmt, release, err := cache.prepare(query(1))
+	require.NoError(t, err)
+	open, err := stmt.Query(1)
+	release()
+	require.NoError(t, err)
+
+	for n := 2; n <= 5; n++ {
+		stmt, release, err := cache.prepare(query(n))
+		require.NoError(t, err)
+		args := make([]interface{}, n)
+		for i := range args {
+			args[i] = 1
+		}
+		var title string
+		require.NoError(t, stmt.QueryRow(args...).Scan(&title))
+		release()
+		assert.Equal(t, "Go dev", title)
+	}
+	assert.Len(t, cache.stmts, 2)
+	assert.Equal(t, 2, cache.lru.Len())
+
+	require.True(t, open.Next())
+	var title string
+	require.NoError(t, open.Scan(&title))
+	assert.Equal(t, "Go dev", title)
+	require.NoError(t, open.Close())
+
+	// A statement in use is only closed once its caller is done with it.
+	stmt, release, err = cache.prepare(query(1))
+	require.NoError(t, err)
+	for n := 2; n <= 3; n++ {
+		_, r, err := cache.prepare(query(n + 4))
+		require.NoError(t, err)
+		r()
+	}
+	require.NoError(t, stmt.QueryRow(1).Scan(&title))
+	release()
+	_, err = stmt.Query(1)
+	assert.Error(t, err, "evicted statements are closed once released")
+}
+
+func TestMigrationsDropDuplicateExternalIDs(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "jobs.db")
+
+	// A database from before the unique index, with a job stored twice.
+	old, err := getConnection(writerDSN(dbPath), 1)
+	require.NoError(t, err)
+	_, err = old.Exec(`CREATE TABLE migrations (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL UNIQUE,
+		executed_at DATETIME DEFAULT CURRENT_TIMESTAMP)`)
+	require.NoError(t, err)
+	for _, m := range migrations[:2] {
+		_, err = old.Exec(m.stmt)
+		require.NoError(t, err)
+		_, err = old.Exec("INSERT INTO migrations (name) VALUES (?)", m.name)
+		require.NoError(t, err)
+	}
+	for _, job := range [][2]string{{"1", "Old copy"}, {"1", "New copy"}, {"2", "Other job"}} {
+		_, err = old.Exec("INSERT INTO jobs (external_id, title, description, type, source) VALUES (?, ?, ?, ?, ?)", job[0], job[1], "", 0, 2)
+		require.NoError(t, err)
+	}
+	require.NoError(t, old.Close())
+
+	store, err := Open(dbPath)
+	require.NoError(t, err)
+	defer store.Close()
+
+	rows, err := store.Query("SELECT title FROM jobs ORDER BY id")
+	require.NoError(t, err)
+	defer rows.Close()
+	var titles []string
+	for rows.Next() {
+		var title string
+		require.NoError(t, rows.Scan(&title))
+		titles = append(titles, title)
+	}
+	assert.Equal(t, []string{"New copy", "Other job"}, titles)
+
+	version, err := ReadSchemaVersion(dbPath)
+	require.NoError(t, err)
+	assert.Equal(t, SchemaVersion(), version)
+}
+
+func TestFailedMigrationLeavesNoTrace(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "jobs.db")
+	store, err := Open(dbPath)
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	saved := migrations
+	defer func() { migrations = saved }()
+	migrations = append(migrations[:len(migrations):len(migrations)], struct {
+		name string
+		stmt string
+	}{
+		name: "broken",
+		stmt: `
+			ALTER TABLE jobs ADD COLUMN broken TEXT NULL;
+			CREATE INDEX idx_broken ON no_such_table (id);`,
+	})
+
+	_, err = Open(dbPath)
+	require.Error(t, err)
+
+	// The column added before the failure was rolled back, so the next
+	// attempt fails the same way instead of on a duplicate column.
+	_, err = Open(dbPath)
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "duplicate column")
+
+	version, err := ReadSchemaVersion(dbPath)
+	require.NoError(t, err)
+	assert.Equal(t, len(saved), version)
+}
@@ -0,0 +1,329 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// migrations are applied in order and never edited once released. The
+// schema version stored in PRAGMA user_version is the number of entries.
+var migrations = []struct {
+	name string
+	stmt string
+}{
+	{
+		name: "create_jobs_table",
+		stmt: `
+			CREATE TABLE IF NOT EXISTS jobs (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				type INTEGER NOT NULL,
+				title VARCHAR(64) NOT NULL,
+          source INTEGER NOT NULL,
+          external_id INTEGER NOT NULL,
+				description VARCHAR(255) NULL,
+				created_at DATETIME default CURRENT_TIMESTAMP);`,
+	},
+	{
+		name: "add_updated_at_column_to_jobs",
+		stmt: `
+			ALTER TABLE jobs ADD COLUMN updated_at DATETIME default CURRENT_TIMESTAMP;`,
+	},
+	{
+		name: "add_lifecycle_columns_to_jobs",
+		stmt: `
+			ALTER TABLE jobs ADD COLUMN status INTEGER NOT NULL DEFAULT 0;
+			ALTER TABLE jobs ADD COLUMN last_seen_at DATETIME NULL;
+			ALTER TABLE jobs ADD COLUMN expires_at DATETIME NULL;
+			ALTER TABLE jobs ADD COLUMN closed_at DATETIME NULL;
+			UPDATE jobs SET last_seen_at = COALESCE(updated_at, created_at);
+			-- Keep the newest of rows sharing an external id so the index below can be built.
+			DELETE FROM jobs WHERE id NOT IN (SELECT MAX(id) FROM jobs GROUP BY source, external_id);
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_source_external_id ON jobs (source, external_id);
+			CREATE INDEX IF NOT EXISTS idx_jobs_status_expires_at ON jobs (status, expires_at);`,
+	},
+	{
+		name: "add_salary_columns_to_jobs",
+		stmt: `
+			ALTER TABLE jobs ADD COLUMN salary_text TEXT NULL;
+			ALTER TABLE jobs ADD COLUMN salary_min REAL NULL;
+			ALTER TABLE jobs ADD COLUMN salary_max REAL NULL;
+			ALTER TABLE jobs ADD COLUMN salary_currency VARCHAR(3) NULL;
+			ALTER TABLE jobs ADD COLUMN salary_period INTEGER NOT NULL DEFAULT 0;
+			ALTER TABLE jobs ADD COLUMN salary_annual_min REAL NULL;
+			ALTER TABLE jobs ADD COLUMN salary_annual_max REAL NULL;
+			CREATE INDEX IF NOT EXISTS idx_jobs_salary_annual_max ON jobs (salary_annual_max);`,
+	},
+	{
+		name: "add_location_columns_to_jobs",
+		stmt: `
+			ALTER TABLE jobs ADD COLUMN location_text TEXT NULL;
+			ALTER TABLE jobs ADD COLUMN city TEXT NULL;
+			ALTER TABLE jobs ADD COLUMN region TEXT NULL;
+			ALTER TABLE jobs ADD COLUMN country_code VARCHAR(2) NULL;
+			ALTER TABLE jobs ADD COLUMN latitude REAL NULL;
+			ALTER TABLE jobs ADD COLUMN longitude REAL NULL;
+			ALTER TABLE jobs ADD COLUMN is_remote INTEGER NOT NULL DEFAULT 0;
+			CREATE INDEX IF NOT EXISTS idx_jobs_latitude_longitude ON jobs (latitude, longitude);`,
+	},
+	{
+		name: "add_employment_type_to_jobs",
+		stmt: `
+			ALTER TABLE jobs ADD COLUMN employment_type INTEGER NOT NULL DEFAULT 0;
+			ALTER TABLE jobs ADD COLUMN type_text TEXT NULL;`,
+	},
+	{
+		name: "add_tags_tables",
+		stmt: `
+			CREATE TABLE IF NOT EXISTS tags (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				name TEXT NOT NULL UNIQUE COLLATE NOCASE
+			);
+			CREATE TABLE IF NOT EXISTS job_tags (
+				job_id INTEGER NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+				tag_id INTEGER NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+				PRIMARY KEY (job_id, tag_id)
+			);
+			CREATE INDEX IF NOT EXISTS idx_job_tags_tag_id ON job_tags (tag_id);`,
+	},
+	{
+		name: "add_description_html_to_jobs",
+		stmt: `
+			ALTER TABLE jobs ADD COLUMN description_html TEXT NULL;`,
+	},
+	{
+		name: "add_department_to_jobs",
+		stmt: `
+			ALTER TABLE jobs ADD COLUMN department TEXT NULL;`,
+	},
+	{
+		name: "add_ingestion_runs_tables",
+		stmt: `
+			CREATE TABLE IF NOT EXISTS ingestion_runs (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				source INTEGER NOT NULL,
+				status VARCHAR(16) NOT NULL,
+				started_at DATETIME NOT NULL,
+				finished_at DATETIME NULL,
+				fetched INTEGER NOT NULL DEFAULT 0,
+				added INTEGER NOT NULL DEFAULT 0,
+				updated INTEGER NOT NULL DEFAULT 0,
+				closed INTEGER NOT NULL DEFAULT 0,
+				rejected INTEGER NOT NULL DEFAULT 0,
+				error TEXT NULL);
+			CREATE INDEX IF NOT EXISTS idx_ingestion_runs_source_started_at ON ingestion_runs (source, started_at);
+			CREATE TABLE IF NOT EXISTS ingestion_rejects (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				run_id INTEGER NOT NULL REFERENCES ingestion_runs (id) ON DELETE CASCADE,
+				external_id TEXT NULL,
+				title TEXT NULL,
+				reason TEXT NOT NULL,
+				payload TEXT NULL);
+			CREATE INDEX IF NOT EXISTS idx_ingestion_rejects_run_id ON ingestion_rejects (run_id);`,
+	},
+	{
+		name: "add_review_columns_to_jobs",
+		stmt: `
+			ALTER TABLE jobs ADD COLUMN rejection_reason TEXT NULL;
+			ALTER TABLE jobs ADD COLUMN reviewed_at DATETIME NULL;
+			ALTER TABLE jobs ADD COLUMN edited_at DATETIME NULL;`,
+	},
+	{
+		name: "add_spam_columns_to_jobs",
+		stmt: `
+			ALTER TABLE jobs ADD COLUMN spam_score REAL NULL;
+			ALTER TABLE jobs ADD COLUMN spam_reasons TEXT NULL;`,
+	},
+	{
+		name: "add_companies_tables",
+		stmt: `
+			CREATE TABLE IF NOT EXISTS companies (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				slug TEXT NOT NULL UNIQUE,
+				name TEXT NOT NULL,
+				website TEXT NULL,
+				description TEXT NULL,
+				logo_url TEXT NULL,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				updated_at DATETIME DEFAULT CURRENT_TIMESTAMP);
+			CREATE TABLE IF NOT EXISTS company_aliases (
+				normalized_name TEXT PRIMARY KEY,
+				name TEXT NOT NULL,
+				company_id INTEGER NOT NULL REFERENCES companies (id) ON DELETE CASCADE);
+			CREATE INDEX IF NOT EXISTS idx_company_aliases_company_id ON company_aliases (company_id);
+			ALTER TABLE jobs ADD COLUMN company_id INTEGER NULL REFERENCES companies (id) ON DELETE SET NULL;
+			CREATE INDEX IF NOT EXISTS idx_jobs_company_id_status ON jobs (company_id, status);`,
+	},
+	{
+		name: "add_employer_posting_tables",
+		stmt: `
+			CREATE TABLE IF NOT EXISTS employers (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				email TEXT NOT NULL UNIQUE,
+				name TEXT NOT NULL,
+				password_hash TEXT NOT NULL,
+				company_id INTEGER NULL REFERENCES companies (id) ON DELETE SET NULL,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP);
+			CREATE TABLE IF NOT EXISTS employer_sessions (
+				token_hash TEXT PRIMARY KEY,
+				employer_id INTEGER NOT NULL REFERENCES employers (id) ON DELETE CASCADE,
+				expires_at DATETIME NOT NULL);
+			CREATE INDEX IF NOT EXISTS idx_employer_sessions_employer_id ON employer_sessions (employer_id);
+			ALTER TABLE jobs ADD COLUMN employer_id INTEGER NULL REFERENCES employers (id) ON DELETE SET NULL;
+			ALTER TABLE jobs ADD COLUMN publish_at DATETIME NULL;
+			CREATE INDEX IF NOT EXISTS idx_jobs_employer_id ON jobs (employer_id);
+			CREATE INDEX IF NOT EXISTS idx_jobs_status_publish_at ON jobs (status, publish_at);
+			CREATE TABLE IF NOT EXISTS job_revisions (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				job_id INTEGER NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+				employer_id INTEGER NULL REFERENCES employers (id) ON DELETE SET NULL,
+				field TEXT NOT NULL,
+				old_value TEXT NULL,
+				new_value TEXT NULL,
+				changed_at DATETIME NOT NULL);
+			CREATE INDEX IF NOT EXISTS idx_job_revisions_job_id ON job_revisions (job_id, changed_at);`,
+	},
+	{
+		name: "add_billing_tables",
+		stmt: `
+			ALTER TABLE jobs ADD COLUMN pinned_until DATETIME NULL;
+			ALTER TABLE jobs ADD COLUMN highlighted_until DATETIME NULL;
+			CREATE TABLE IF NOT EXISTS orders (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				employer_id INTEGER NOT NULL REFERENCES employers (id) ON DELETE CASCADE,
+				job_id INTEGER NULL REFERENCES jobs (id) ON DELETE SET NULL,
+				feature TEXT NOT NULL,
+				label TEXT NOT NULL,
+				amount_cents INTEGER NOT NULL,
+				currency TEXT NOT NULL,
+				duration_hours INTEGER NOT NULL,
+				status TEXT NOT NULL,
+				provider TEXT NOT NULL,
+				provider_ref TEXT NULL,
+				feature_until DATETIME NULL,
+				created_at DATETIME NOT NULL,
+				paid_at DATETIME NULL,
+				UNIQUE (provider, provider_ref));
+			CREATE INDEX IF NOT EXISTS idx_orders_employer_id ON orders (employer_id, created_at);
+			CREATE TABLE IF NOT EXISTS invoices (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				number TEXT NOT NULL UNIQUE,
+				order_id INTEGER NOT NULL UNIQUE REFERENCES orders (id) ON DELETE CASCADE,
+				employer_id INTEGER NOT NULL REFERENCES employers (id) ON DELETE CASCADE,
+				description TEXT NOT NULL,
+				amount_cents INTEGER NOT NULL,
+				currency TEXT NOT NULL,
+				issued_at DATETIME NOT NULL);
+			CREATE TABLE IF NOT EXISTS payment_events (
+				provider TEXT NOT NULL,
+				event_id TEXT NOT NULL,
+				order_id INTEGER NULL REFERENCES orders (id) ON DELETE SET NULL,
+				type TEXT NOT NULL,
+				received_at DATETIME NOT NULL,
+				PRIMARY KEY (provider, event_id));`,
+	},
+	{
+		name: "add_applications_table",
+		stmt: `
+			CREATE TABLE IF NOT EXISTS applications (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				job_id INTEGER NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+				name TEXT NOT NULL,
+				email TEXT NOT NULL,
+				cover_letter TEXT NOT NULL,
+				resume_key TEXT NOT NULL,
+				resume_name TEXT NOT NULL,
+				resume_type TEXT NOT NULL,
+				resume_size INTEGER NOT NULL,
+				created_at DATETIME NOT NULL);
+			CREATE INDEX IF NOT EXISTS idx_applications_job_id ON applications (job_id, created_at);`,
+	},
+	{
+		name: "add_seeker_profiles_table",
+		stmt: `
+			CREATE TABLE IF NOT EXISTS seeker_profiles (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				token_hash TEXT NOT NULL UNIQUE,
+				resume_name TEXT NOT NULL,
+				resume_text TEXT NOT NULL,
+				skills TEXT NOT NULL,
+				created_at DATETIME NOT NULL,
+				expires_at DATETIME NOT NULL);
+			CREATE INDEX IF NOT EXISTS idx_seeker_profiles_expires_at ON seeker_profiles (expires_at);`,
+	},
+	{
+		name: "add_similar_jobs_table",
+		stmt: `
+			CREATE TABLE IF NOT EXISTS similar_jobs (
+				job_id INTEGER NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+				similar_id INTEGER NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+				score REAL NOT NULL,
+				PRIMARY KEY (job_id, similar_id));`,
+	},
+}
+
+func createMigrations(dbName string, db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS migrations (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE,
+			executed_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	for i, migration := range migrations {
+		var count int
+		err := db.QueryRow("SELECT COUNT(*) FROM migrations WHERE name = ?", migration.name).Scan(&count)
+		if err != nil {
+			return fmt.Errorf("failed to check migration status: %w", err)
+		}
+
+		if count == 0 {
+			if err := runMigration(db, i); err != nil {
+				return err
+			}
+			log.Printf("✅ Migration %s executed successfully", migration.name)
+		}
+	}
+
+	if _, err := db.Exec(fmt.Sprintf("PRAGMA user_version = %d", SchemaVersion())); err != nil {
+		return fmt.Errorf("failed to set schema version: %w", err)
+	}
+
+	return nil
+}
+
+// runMigration applies migrations[i] in a transaction, so a migration
+// that fails halfway leaves nothing behind to trip over on the next start.
+func runMigration(db *sql.DB, i int) error {
+	migration := migrations[i]
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %s: %w", migration.name, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(migration.stmt); err != nil {
+		return fmt.Errorf("failed to execute migration %s: %w", migration.name, err)
+	}
+	if _, err := tx.Exec("INSERT INTO migrations (name) VALUES (?)", migration.name); err != nil {
+		return fmt.Errorf("failed to record migration %s: %w", migration.name, err)
+	}
+	if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+		return fmt.Errorf("failed to set schema version: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %s: %w", migration.name, err)
+	}
+	return nil
+}
+
+// SchemaVersion is the schema version this build migrates databases to.
+func SchemaVersion() int {
+	return len(migrations)
+}
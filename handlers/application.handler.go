@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/igorrize/htmxjb/services"
+	"github.com/igorrize/htmxjb/views/employer_views"
+	"github.com/igorrize/htmxjb/views/job_views"
+	"github.com/labstack/echo/v4"
+)
+
+type ApplicationService interface {
+	Apply(ctx context.Context, jobID int, a services.Application, resume services.Resume) (services.Application, error)
+	Applications(employerID, jobID int) ([]services.Application, error)
+	Resume(ctx context.Context, employerID, applicationID int) (services.Application, io.ReadCloser, error)
+}
+
+// maxApplicationBody caps application requests: the resume plus room for
+// the other fields.
+const maxApplicationBody = 1 << 20
+
+var errBadForm = errors.New("the form could not be read")
+
+type ApplicationHandler struct {
+	ApplicationService ApplicationService
+	JobService         JobService
+	PostingService     PostingService
+	MaxResumeBytes     int64
+}
+
+func NewApplicationHandler(as ApplicationService, js JobService, ps PostingService, maxResumeBytes int64) *ApplicationHandler {
+	return &ApplicationHandler{
+		ApplicationService: as,
+		JobService:         js,
+		PostingService:     ps,
+		MaxResumeBytes:     maxResumeBytes,
+	}
+}
+
+func (ah *ApplicationHandler) applyFormHandler(c echo.Context) error {
+	job, err := ah.opening(c)
+	if err != nil {
+		return jobError(c, err)
+	}
+
+	return renderView(c, job_views.JobIndex(printer(c).T("Apply for %s", job.Title), job_views.ApplyForm(job, services.Application{}, "")))
+}
+
+func (ah *ApplicationHandler) applyHandler(c echo.Context) error {
+	job, err := ah.opening(c)
+	if err != nil {
+		return jobError(c, err)
+	}
+
+	var (
+		form   services.Application
+		resume services.Resume
+	)
+	err = parseApplication(c, ah.MaxResumeBytes)
+	if err == nil {
+		form = services.Application{
+			Name:        c.FormValue("name"),
+			Email:       c.FormValue("email"),
+			CoverLetter: c.FormValue("cover_letter"),
+		}
+		resume, err = readResume(c, ah.MaxResumeBytes)
+	}
+	if err == nil {
+		_, err = ah.ApplicationService.Apply(c.Request().Context(), job.ID, form, resume)
+	}
+	if errors.Is(err, errBadForm) {
+		return c.String(http.StatusBadRequest, printer(c).Error(err))
+	}
+	if errors.Is(err, services.ErrApplicantNameRequired) ||
+		errors.Is(err, services.ErrEmailInvalid) ||
+		errors.Is(err, services.ErrCoverLetterTooLong) ||
+		errors.Is(err, services.ErrResumeRequired) ||
+		errors.Is(err, services.ErrResumeType) ||
+		errors.Is(err, services.ErrResumeTooLarge) {
+		c.Response().WriteHeader(http.StatusUnprocessableEntity)
+		return renderView(c, job_views.JobIndex(printer(c).T("Apply for %s", job.Title), job_views.ApplyForm(job, form, printer(c).Error(err))))
+	}
+	if errors.Is(err, services.ErrNotAcceptingApplications) {
+		return c.String(http.StatusConflict, printer(c).Error(err))
+	}
+	if err != nil {
+		return jobError(c, err)
+	}
+
+	return renderView(c, job_views.JobIndex(printer(c).T("Application sent"), job_views.ApplicationSent(job)))
+}
+
+// inboxHandler lists the applications to one of the employer's postings.
+func (ah *ApplicationHandler) inboxHandler(c echo.Context) error {
+	p, err := ownPosting(c, ah.PostingService)
+	if err != nil {
+		return jobError(c, err)
+	}
+
+	applications, err := ah.ApplicationService.Applications(currentEmployer(c).ID, p.ID)
+	if err != nil {
+		return jobError(c, err)
+	}
+
+	return renderView(c, employer_views.EmployerIndex(printer(c).T("Applications"), employer_views.Inbox(p, applications)))
+}
+
+// resumeHandler downloads an applicant's resume. It is always sent as an
+// attachment so the browser never renders it on the board's origin.
+func (ah *ApplicationHandler) resumeHandler(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.String(http.StatusNotFound, printer(c).Error(services.ErrApplicationNotFound))
+	}
+
+	a, r, err := ah.ApplicationService.Resume(c.Request().Context(), currentEmployer(c).ID, id)
+	if errors.Is(err, services.ErrApplicationNotFound) {
+		return c.String(http.StatusNotFound, printer(c).Error(err))
+	}
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	defer r.Close()
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": a.ResumeName}))
+	header.Set(echo.HeaderXContentTypeOptions, "nosniff")
+	header.Set("Cache-Control", "private, no-store")
+
+	return c.Stream(http.StatusOK, a.ResumeType, r)
+}
+
+// opening is the job in the URL, if candidates apply to it on the board.
+func (ah *ApplicationHandler) opening(c echo.Context) (services.Job, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return services.Job{}, services.ErrJobNotFound
+	}
+
+	job, err := ah.JobService.GetJob(id)
+	if err != nil {
+		return services.Job{}, err
+	}
+	if !job.AcceptsApplications {
+		return services.Job{}, services.ErrJobNotFound
+	}
+
+	return job, nil
+}
+
+// parseApplication reads the multipart form into memory, refusing bodies
+// much larger than the biggest resume allowed.
+func parseApplication(c echo.Context, maxResume int64) error {
+	req := c.Request()
+	limit := maxResume + maxApplicationBody
+	req.Body = http.MaxBytesReader(c.Response(), req.Body, limit)
+
+	err := req.ParseMultipartForm(limit)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return services.ErrResumeTooLarge
+	}
+	if err != nil {
+		return errBadForm
+	}
+	return nil
+}
+
+// readResume reads the uploaded resume, reading at most one byte more than
+// max so the service sees that an oversized file is too large.
+func readResume(c echo.Context, max int64) (services.Resume, error) {
+	header, err := c.FormFile("resume")
+	if errors.Is(err, http.ErrMissingFile) {
+		return services.Resume{}, services.ErrResumeRequired
+	}
+	if err != nil {
+		return services.Resume{}, err
+	}
+
+	f, err := header.Open()
+	if err != nil {
+		return services.Resume{}, err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, max+1))
+	if err != nil {
+		return services.Resume{}, err
+	}
+
+	return services.Resume{Name: header.Filename, Data: data}, nil
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+	"path/filepath"
+
+	"github.com/labstack/echo/v4"
+)
+
+type BackupService interface {
+	Snapshot() (string, error)
+	List() ([]string, error)
+}
+
+type BackupHandler struct {
+	BackupService BackupService
+}
+
+func NewBackupHandler(bs BackupService) *BackupHandler {
+	return &BackupHandler{
+		BackupService: bs,
+	}
+}
+
+// createBackupHandler takes a fresh snapshot and sends it as a download.
+func (bh *BackupHandler) createBackupHandler(c echo.Context) error {
+	path, err := bh.BackupService.Snapshot()
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.Attachment(path, filepath.Base(path))
+}
+
+func (bh *BackupHandler) listBackupsHandler(c echo.Context) error {
+	names, err := bh.BackupService.List()
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, names)
+}
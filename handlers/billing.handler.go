@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/igorrize/htmxjb/services"
+	"github.com/igorrize/htmxjb/services/payments"
+	"github.com/igorrize/htmxjb/views/employer_views"
+	"github.com/labstack/echo/v4"
+)
+
+type BillingService interface {
+	Plans() []services.Plan
+	Checkout(ctx context.Context, employerID, jobID int, feature services.Feature, returnURL string) (string, error)
+	Orders(employerID int) ([]services.Order, error)
+	Invoice(employerID int, number string) (services.Invoice, error)
+	HandleEvent(event payments.Event) error
+}
+
+// maxWebhookBody caps the size of webhook requests read into memory.
+const maxWebhookBody = 64 << 10
+
+type BillingHandler struct {
+	BillingService BillingService
+	PostingService PostingService
+	Provider       payments.Provider
+}
+
+func NewBillingHandler(bs BillingService, ps PostingService, provider payments.Provider) *BillingHandler {
+	return &BillingHandler{
+		BillingService: bs,
+		PostingService: ps,
+		Provider:       provider,
+	}
+}
+
+// promoteHandler offers the paid features for a posting.
+func (bh *BillingHandler) promoteHandler(c echo.Context) error {
+	p, err := ownPosting(c, bh.PostingService)
+	if err != nil {
+		return jobError(c, err)
+	}
+
+	return renderView(c, employer_views.EmployerIndex(printer(c).T("Promote"), employer_views.Promote(p, bh.BillingService.Plans(), "")))
+}
+
+// checkoutHandler orders the chosen feature and sends the employer to the
+// payment provider.
+func (bh *BillingHandler) checkoutHandler(c echo.Context) error {
+	p, err := ownPosting(c, bh.PostingService)
+	if err != nil {
+		return jobError(c, err)
+	}
+
+	feature := services.Feature(c.FormValue("feature"))
+	payURL, err := bh.BillingService.Checkout(c.Request().Context(), currentEmployer(c).ID, p.ID, feature, "/employer/billing")
+	if errors.Is(err, services.ErrUnknownPlan) || errors.Is(err, services.ErrNotPromotable) {
+		return renderView(c, employer_views.EmployerIndex(printer(c).T("Promote"), employer_views.Promote(p, bh.BillingService.Plans(), printer(c).Error(err))))
+	}
+	if err != nil {
+		return jobError(c, err)
+	}
+
+	return redirect(c, payURL)
+}
+
+func (bh *BillingHandler) ordersHandler(c echo.Context) error {
+	orders, err := bh.BillingService.Orders(currentEmployer(c).ID)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return renderView(c, employer_views.EmployerIndex(printer(c).T("Billing"), employer_views.Billing(orders)))
+}
+
+func (bh *BillingHandler) invoiceHandler(c echo.Context) error {
+	invoice, err := bh.BillingService.Invoice(currentEmployer(c).ID, c.Param("number"))
+	if errors.Is(err, services.ErrInvoiceNotFound) {
+		return c.String(http.StatusNotFound, printer(c).Error(err))
+	}
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return renderView(c, employer_views.EmployerIndex(printer(c).T("Invoice %s", invoice.Number), employer_views.InvoicePage(invoice)))
+}
+
+// webhookHandler receives the payment provider's events. Anything but a
+// 2xx answer makes the provider deliver the event again later.
+func (bh *BillingHandler) webhookHandler(c echo.Context) error {
+	payload, err := io.ReadAll(io.LimitReader(c.Request().Body, maxWebhookBody))
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+
+	if status, err := bh.applyEvent(payload, c.Request().Header); err != nil {
+		return c.String(status, err.Error())
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// fakeCheckoutHandler is the fake provider's payment page.
+func (bh *BillingHandler) fakeCheckoutHandler(c echo.Context) error {
+	fake, ok := bh.Provider.(*payments.Fake)
+	if !ok {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	checkout, err := fake.Session(c.Param("ref"))
+	if err != nil {
+		return c.String(http.StatusNotFound, printer(c).Error(err))
+	}
+
+	return renderView(c, employer_views.EmployerIndex(printer(c).T("Checkout"), employer_views.FakeCheckout(c.Param("ref"), checkout)))
+}
+
+// fakeCompleteHandler settles a fake payment, delivers the event the fake
+// provider sends for it and returns the buyer to the board.
+func (bh *BillingHandler) fakeCompleteHandler(c echo.Context) error {
+	fake, ok := bh.Provider.(*payments.Fake)
+	if !ok {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	checkout, err := fake.Session(c.Param("ref"))
+	if err != nil {
+		return c.String(http.StatusNotFound, printer(c).Error(err))
+	}
+
+	payload, header, err := fake.Complete(c.Param("ref"), c.FormValue("outcome") == "pay")
+	if err != nil {
+		return c.String(http.StatusNotFound, printer(c).Error(err))
+	}
+
+	if status, err := bh.applyEvent(payload, header); err != nil {
+		return c.String(status, printer(c).Error(err))
+	}
+
+	return c.Redirect(http.StatusSeeOther, checkout.ReturnURL)
+}
+
+// applyEvent verifies and applies a webhook event, returning the status to
+// answer with if that failed.
+func (bh *BillingHandler) applyEvent(payload []byte, header http.Header) (int, error) {
+	event, err := bh.Provider.ParseEvent(payload, header)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	err = bh.BillingService.HandleEvent(event)
+	switch {
+	case errors.Is(err, services.ErrOrderNotFound):
+		return http.StatusNotFound, err
+	case errors.Is(err, services.ErrPaymentMismatch):
+		return http.StatusUnprocessableEntity, err
+	case err != nil:
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
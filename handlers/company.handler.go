@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/igorrize/htmxjb/services"
+	"github.com/igorrize/htmxjb/views/admin_views"
+	"github.com/igorrize/htmxjb/views/job_views"
+	"github.com/labstack/echo/v4"
+)
+
+type CompanyService interface {
+	Companies() ([]services.Company, error)
+	Company(id int) (services.Company, error)
+	CompanyBySlug(slug string) (services.Company, error)
+	OpenJobs(companyID int) ([]services.Job, error)
+	Update(id int, name, website, description, logoURL string) error
+	Merge(keep int, duplicates ...int) error
+}
+
+type CompanyHandler struct {
+	CompanyService CompanyService
+}
+
+func NewCompanyHandler(cs CompanyService) *CompanyHandler {
+	return &CompanyHandler{
+		CompanyService: cs,
+	}
+}
+
+// profileHandler is the public company page with its open jobs.
+func (ch *CompanyHandler) profileHandler(c echo.Context) error {
+	company, err := ch.CompanyService.CompanyBySlug(c.Param("slug"))
+	if err != nil {
+		return companyError(c, err)
+	}
+
+	jobs, err := ch.CompanyService.OpenJobs(company.ID)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return renderView(c, job_views.JobIndex(company.Name, job_views.CompanyProfile(company, jobs)))
+}
+
+func (ch *CompanyHandler) listHandler(c echo.Context) error {
+	list, err := ch.CompanyService.Companies()
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return renderView(c, admin_views.AdminIndex(printer(c).T("Companies"), admin_views.Companies(list)))
+}
+
+// companyHandler renders one company, e.g. when an edit is cancelled.
+func (ch *CompanyHandler) companyHandler(c echo.Context) error {
+	company, err := ch.company(c)
+	if err != nil {
+		return companyError(c, err)
+	}
+
+	return renderView(c, admin_views.CompanyItem(company))
+}
+
+func (ch *CompanyHandler) editFormHandler(c echo.Context) error {
+	company, err := ch.company(c)
+	if err != nil {
+		return companyError(c, err)
+	}
+
+	return renderView(c, admin_views.CompanyEditForm(company, ""))
+}
+
+func (ch *CompanyHandler) editHandler(c echo.Context) error {
+	company, err := ch.company(c)
+	if err != nil {
+		return companyError(c, err)
+	}
+
+	name, website := c.FormValue("name"), c.FormValue("website")
+	description, logoURL := c.FormValue("description"), c.FormValue("logo_url")
+
+	err = ch.CompanyService.Update(company.ID, name, website, description, logoURL)
+	if errors.Is(err, services.ErrCompanyNameRequired) ||
+		errors.Is(err, services.ErrCompanyAliasUsed) ||
+		errors.Is(err, services.ErrCompanyURL) {
+		company.Name, company.Website, company.Description, company.LogoURL = name, website, description, logoURL
+		return renderView(c, admin_views.CompanyEditForm(company, printer(c).Error(err)))
+	}
+	if err != nil {
+		return companyError(c, err)
+	}
+
+	return ch.companyHandler(c)
+}
+
+// mergeHandler folds the checked companies into the one picked to keep
+// and renders the updated list.
+func (ch *CompanyHandler) mergeHandler(c echo.Context) error {
+	form, err := c.FormParams()
+	if err != nil {
+		return c.String(http.StatusBadRequest, printer(c).Error(err))
+	}
+
+	keep, _ := strconv.Atoi(c.FormValue("keep"))
+	var ids []int
+	for _, v := range form["id"] {
+		if id, err := strconv.Atoi(v); err == nil {
+			ids = append(ids, id)
+		}
+	}
+
+	err = ch.CompanyService.Merge(keep, ids...)
+	if errors.Is(err, services.ErrNothingToMerge) {
+		return c.String(http.StatusUnprocessableEntity, printer(c).Error(err))
+	}
+	if err != nil {
+		return companyError(c, err)
+	}
+
+	list, err := ch.CompanyService.Companies()
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return renderView(c, admin_views.CompanyItems(list))
+}
+
+func (ch *CompanyHandler) company(c echo.Context) (services.Company, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return services.Company{}, services.ErrCompanyNotFound
+	}
+	return ch.CompanyService.Company(id)
+}
+
+// companyError answers with the status err calls for.
+func companyError(c echo.Context, err error) error {
+	if errors.Is(err, services.ErrCompanyNotFound) {
+		return c.String(http.StatusNotFound, printer(c).Error(err))
+	}
+	return c.String(http.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/igorrize/htmxjb/services"
+	"github.com/igorrize/htmxjb/views/employer_views"
+	"github.com/labstack/echo/v4"
+)
+
+type EmployerService interface {
+	EmployerSessions
+	SignUp(email, name, company, password string) (services.Employer, error)
+	LogIn(email, password string) (services.Employer, error)
+	StartSession(employerID int) (string, time.Time, error)
+	EndSession(token string) error
+}
+
+type EmployerHandler struct {
+	EmployerService EmployerService
+}
+
+func NewEmployerHandler(es EmployerService) *EmployerHandler {
+	return &EmployerHandler{
+		EmployerService: es,
+	}
+}
+
+func (eh *EmployerHandler) signupFormHandler(c echo.Context) error {
+	return renderView(c, employer_views.EmployerIndex(printer(c).T("Post jobs"), employer_views.SignUp(employer_views.SignUpForm{}, "")))
+}
+
+func (eh *EmployerHandler) signupHandler(c echo.Context) error {
+	form := employer_views.SignUpForm{
+		Email:   c.FormValue("email"),
+		Name:    c.FormValue("name"),
+		Company: c.FormValue("company"),
+	}
+
+	employer, err := eh.EmployerService.SignUp(form.Email, form.Name, form.Company, c.FormValue("password"))
+	if errors.Is(err, services.ErrEmailInvalid) ||
+		errors.Is(err, services.ErrEmailTaken) ||
+		errors.Is(err, services.ErrPasswordTooShort) ||
+		errors.Is(err, services.ErrEmployerNameRequired) {
+		c.Response().WriteHeader(http.StatusUnprocessableEntity)
+		return renderView(c, employer_views.EmployerIndex(printer(c).T("Post jobs"), employer_views.SignUp(form, printer(c).Error(err))))
+	}
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return eh.startSession(c, employer)
+}
+
+func (eh *EmployerHandler) loginFormHandler(c echo.Context) error {
+	return renderView(c, employer_views.EmployerIndex(printer(c).T("Log in"), employer_views.LogIn("", "")))
+}
+
+func (eh *EmployerHandler) loginHandler(c echo.Context) error {
+	email := c.FormValue("email")
+
+	employer, err := eh.EmployerService.LogIn(email, c.FormValue("password"))
+	if errors.Is(err, services.ErrInvalidLogin) {
+		c.Response().WriteHeader(http.StatusUnauthorized)
+		return renderView(c, employer_views.EmployerIndex(printer(c).T("Log in"), employer_views.LogIn(email, printer(c).Error(err))))
+	}
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return eh.startSession(c, employer)
+}
+
+func (eh *EmployerHandler) logoutHandler(c echo.Context) error {
+	if cookie, err := c.Cookie(employerCookie); err == nil {
+		if err := eh.EmployerService.EndSession(cookie.Value); err != nil {
+			return c.String(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	c.SetCookie(sessionCookie(c, "", time.Unix(0, 0)))
+	return redirect(c, "/employer/login")
+}
+
+// startSession signs the employer in and sends them to their dashboard.
+func (eh *EmployerHandler) startSession(c echo.Context, employer services.Employer) error {
+	token, expires, err := eh.EmployerService.StartSession(employer.ID)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	c.SetCookie(sessionCookie(c, token, expires))
+	return redirect(c, "/employer")
+}
+
+// sessionCookie is kept from scripts and, being SameSite=Lax, is not sent
+// with form posts from other sites.
+func sessionCookie(c echo.Context, token string, expires time.Time) *http.Cookie {
+	return &http.Cookie{
+		Name:     employerCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   c.Scheme() == "https",
+		SameSite: http.SameSiteLaxMode,
+	}
+}
@@ -1,32 +1,63 @@
 package handlers
 
 import (
+	"github.com/igorrize/htmxjb/models/domain"
 	"github.com/igorrize/htmxjb/services"
+	"github.com/igorrize/htmxjb/services/geo"
 	"github.com/igorrize/htmxjb/views/job_views"
 	"github.com/labstack/echo/v4"
 
+	"errors"
 	"github.com/a-h/templ"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
 type JobService interface {
 	GetAllJobs() ([]services.Job, error)
+	ListJobs(filter services.JobFilter) ([]services.Job, error)
+	PopularTags(limit int) ([]services.TagCount, error)
+	GetJob(id int) (services.Job, error)
+}
+
+// drawerTags is how many popular tags the filter drawer offers.
+const drawerTags = 20
+
+// Geocoder resolves a city name typed into the filter drawer.
+type Geocoder interface {
+	Lookup(name string, hints ...string) (geo.Place, bool)
 }
 
 type JobHandler struct {
 	JobService JobService
+	Geocoder   Geocoder
 }
 
-func NewJobHandler(js JobService) *JobHandler {
+func NewJobHandler(js JobService, gc Geocoder) *JobHandler {
 	return &JobHandler{
 		JobService: js,
+		Geocoder:   gc,
 	}
 }
 
 func (jh *JobHandler) jobListHandler(c echo.Context) error {
 	c.Set("ISERROR", false)
 
-	jobs, err := jh.JobService.GetAllJobs()
+	filter := jh.parseJobFilter(c)
+
+	jobs, err := jh.JobService.ListJobs(filter)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	// Filter changes from the drawer only swap the card list, which
+	// reconnects its live updates with the new filters.
+	if isHTMX(c) {
+		return renderView(c, job_views.LiveJobs(jobs, filter))
+	}
+
+	popular, err := jh.JobService.PopularTags(drawerTags)
 	if err != nil {
 		return c.String(http.StatusInternalServerError, err.Error())
 	}
@@ -34,10 +65,72 @@ func (jh *JobHandler) jobListHandler(c echo.Context) error {
 	titlePage := "Jobs List"
 	return renderView(c, job_views.JobIndex(
 		titlePage,
-		job_views.JobList(titlePage, jobs),
+		job_views.JobList(titlePage, jobs, filter, popular),
 	))
 }
 
+func (jh *JobHandler) jobDetailHandler(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.String(http.StatusNotFound, printer(c).Error(services.ErrJobNotFound))
+	}
+
+	job, err := jh.JobService.GetJob(id)
+	if errors.Is(err, services.ErrJobNotFound) {
+		return c.String(http.StatusNotFound, printer(c).Error(err))
+	}
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return renderView(c, job_views.JobIndex(job.Title, job_views.JobDetail(job)))
+}
+
+func (jh *JobHandler) parseJobFilter(c echo.Context) services.JobFilter {
+	filter := services.JobFilter{
+		NearCity: strings.TrimSpace(c.QueryParam("near")),
+		Sort:     services.JobSort(c.QueryParam("sort")),
+		Profile:  currentProfile(c),
+	}
+
+	if v, err := strconv.ParseFloat(c.QueryParam("min_salary"), 64); err == nil && v > 0 {
+		filter.MinSalary = v
+	}
+	if v, err := strconv.ParseFloat(c.QueryParam("max_salary"), 64); err == nil && v > 0 {
+		filter.MaxSalary = v
+	}
+
+	if v, err := domain.ParseJobType(c.QueryParam("workplace")); err == nil {
+		filter.Workplace = &v
+	}
+	if v, err := domain.ParseEmploymentType(c.QueryParam("employment")); err == nil {
+		filter.Employment = &v
+	}
+
+	for _, tag := range c.QueryParams()["tag"] {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			filter.Tags = append(filter.Tags, tag)
+		}
+	}
+
+	// A city that cannot be found matches nothing rather than everything,
+	// and the list says so.
+	if v, err := strconv.ParseFloat(c.QueryParam("radius_km"), 64); err == nil && v > 0 && filter.NearCity != "" {
+		filter.RadiusKm = v
+		if place, ok := jh.Geocoder.Lookup(filter.NearCity); ok {
+			filter.Near = &place.Point
+		} else {
+			filter.NearUnknown = true
+		}
+	}
+
+	return filter
+}
+
+func isHTMX(c echo.Context) bool {
+	return c.Request().Header.Get("HX-Request") == "true"
+}
+
 func renderView(c echo.Context, cmp templ.Component) error {
 	c.Response().Header().Set(echo.HeaderContentType, echo.MIMETextHTML)
 
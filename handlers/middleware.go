@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/igorrize/htmxjb/services"
+	"github.com/igorrize/htmxjb/services/i18n"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// AdminAuth guards admin routes with HTTP basic auth. With no password
+// configured every request is rejected.
+func AdminAuth(user, password string) echo.MiddlewareFunc {
+	return middleware.BasicAuth(func(u, p string, c echo.Context) (bool, error) {
+		if password == "" {
+			return false, nil
+		}
+		userOK := subtle.ConstantTimeCompare([]byte(u), []byte(user)) == 1
+		passOK := subtle.ConstantTimeCompare([]byte(p), []byte(password)) == 1
+		return userOK && passOK, nil
+	})
+}
+
+// SameOrigin rejects writes sent by other sites. Browsers resend cached
+// basic auth credentials on cross-site requests, so admin routes cannot
+// rely on them alone. Requests with neither Sec-Fetch-Site nor Origin come
+// from scripts and tools, not from a page, and are let through.
+func SameOrigin() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			switch req.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				return next(c)
+			}
+
+			if site := req.Header.Get("Sec-Fetch-Site"); site != "" {
+				if site != "same-origin" && site != "none" {
+					return c.String(http.StatusForbidden, "cross-site request refused")
+				}
+				return next(c)
+			}
+			if origin := req.Header.Get("Origin"); origin != "" {
+				u, err := url.Parse(origin)
+				if err != nil || u.Host != req.Host {
+					return c.String(http.StatusForbidden, "cross-site request refused")
+				}
+			}
+			return next(c)
+		}
+	}
+}
+
+// EmployerSessions looks up who a session cookie belongs to.
+type EmployerSessions interface {
+	SessionEmployer(token string) (services.Employer, error)
+}
+
+// employerCookie holds the session token of a signed in employer.
+const employerCookie = "employer_session"
+
+// EmployerAuth guards employer routes with the session cookie. Visitors
+// without a valid session are sent to the login page.
+func EmployerAuth(sessions EmployerSessions) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var token string
+			if cookie, err := c.Cookie(employerCookie); err == nil {
+				token = cookie.Value
+			}
+
+			employer, err := sessions.SessionEmployer(token)
+			if errors.Is(err, services.ErrSessionNotFound) {
+				return redirect(c, "/employer/login")
+			}
+			if err != nil {
+				return c.String(http.StatusInternalServerError, err.Error())
+			}
+
+			c.Set("employer", employer)
+			return next(c)
+		}
+	}
+}
+
+// currentEmployer is the employer EmployerAuth signed in.
+func currentEmployer(c echo.Context) services.Employer {
+	employer, _ := c.Get("employer").(services.Employer)
+	return employer
+}
+
+// SeekerProfiles looks up the resume profile a cookie holds.
+type SeekerProfiles interface {
+	Profile(token string) (services.SeekerProfile, error)
+}
+
+// seekerCookie holds the token of a job seeker's resume profile.
+const seekerCookie = "seeker_profile"
+
+// SeekerProfile loads the visitor's resume profile, if they uploaded one,
+// for jobs to be matched against. Visitors without one carry on as usual.
+func SeekerProfile(profiles SeekerProfiles) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			cookie, err := c.Cookie(seekerCookie)
+			if err != nil {
+				return next(c)
+			}
+
+			profile, err := profiles.Profile(cookie.Value)
+			if errors.Is(err, services.ErrSeekerProfileNotFound) {
+				return next(c)
+			}
+			if err != nil {
+				return c.String(http.StatusInternalServerError, err.Error())
+			}
+
+			c.Set("profile", &profile)
+			return next(c)
+		}
+	}
+}
+
+// currentProfile is the profile SeekerProfile loaded, or nil.
+func currentProfile(c echo.Context) *services.SeekerProfile {
+	profile, _ := c.Get("profile").(*services.SeekerProfile)
+	return profile
+}
+
+// localeCookie remembers the language a visitor picked.
+const localeCookie = "lang"
+
+// localeCookieAge is how long a picked language is remembered.
+const localeCookieAge = 365 * 24 * time.Hour
+
+// Locale picks the language pages are shown in: the lang query parameter,
+// which is then remembered in a cookie, else that cookie, else the
+// browser's Accept-Language. Templates find it in the request context.
+func Locale(catalog *i18n.Catalog) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var tags []string
+			if lang := c.QueryParam("lang"); lang != "" {
+				if locale, ok := catalog.Match(lang); ok {
+					c.SetCookie(&http.Cookie{
+						Name:     localeCookie,
+						Value:    string(locale),
+						Path:     "/",
+						Expires:  time.Now().Add(localeCookieAge),
+						HttpOnly: true,
+						Secure:   c.Scheme() == "https",
+						SameSite: http.SameSiteLaxMode,
+					})
+				}
+				tags = append(tags, lang)
+			}
+			if cookie, err := c.Cookie(localeCookie); err == nil {
+				tags = append(tags, cookie.Value)
+			}
+			tags = append(tags, i18n.ParseAcceptLanguage(c.Request().Header.Get("Accept-Language"))...)
+
+			locale := catalog.Negotiate(tags...)
+			c.Response().Header().Set("Content-Language", string(locale))
+			// Cached pages differ by language too.
+			c.Response().Header().Add("Vary", "Accept-Language, Cookie")
+
+			req := c.Request()
+			ctx := i18n.WithQuery(i18n.NewContext(req.Context(), catalog.Printer(locale)), req.URL.Query())
+			c.SetRequest(req.WithContext(ctx))
+			return next(c)
+		}
+	}
+}
+
+// printer writes messages in the language Locale picked.
+func printer(c echo.Context) *i18n.Printer {
+	return i18n.FromContext(c.Request().Context())
+}
+
+// redirect sends the browser to path, with a full page load for htmx
+// requests.
+func redirect(c echo.Context, path string) error {
+	if isHTMX(c) {
+		c.Response().Header().Set("HX-Redirect", path)
+		return c.NoContent(http.StatusNoContent)
+	}
+	return c.Redirect(http.StatusSeeOther, path)
+}
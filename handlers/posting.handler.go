@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/igorrize/htmxjb/models/domain"
+	"github.com/igorrize/htmxjb/services"
+	"github.com/igorrize/htmxjb/views/employer_views"
+	"github.com/labstack/echo/v4"
+)
+
+type PostingService interface {
+	Postings(employerID int) ([]services.Posting, error)
+	Posting(employerID, id int) (services.Posting, error)
+	CreateDraft(employer services.Employer) (services.Posting, error)
+	Save(employerID int, p services.Posting) error
+	Publish(employerID, id int, publishAt, expiresAt time.Time) error
+	Close(employerID, id int) error
+	History(employerID, id int) ([]services.Revision, error)
+}
+
+// Layouts of the go-live and expiry inputs on the review step.
+const (
+	publishAtLayout = "2006-01-02T15:04"
+	expiresAtLayout = "2006-01-02"
+)
+
+var errPostingDate = errors.New("dates must be given as shown in the date picker")
+
+type PostingHandler struct {
+	PostingService PostingService
+	// Billing shows links to paid features, which need a payment provider.
+	Billing bool
+}
+
+func NewPostingHandler(ps PostingService) *PostingHandler {
+	return &PostingHandler{
+		PostingService: ps,
+	}
+}
+
+// dashboardHandler lists the signed in employer's postings.
+func (ph *PostingHandler) dashboardHandler(c echo.Context) error {
+	employer := currentEmployer(c)
+
+	postings, err := ph.PostingService.Postings(employer.ID)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return renderView(c, employer_views.EmployerIndex(printer(c).T("Your jobs"), employer_views.Dashboard(employer, postings, ph.Billing)))
+}
+
+// newHandler starts a draft and opens it in the wizard.
+func (ph *PostingHandler) newHandler(c echo.Context) error {
+	p, err := ph.PostingService.CreateDraft(currentEmployer(c))
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return redirect(c, employer_views.PostingPath(p, ""))
+}
+
+// wizardHandler renders a step of the posting wizard, the whole page
+// unless htmx only asks for the wizard.
+func (ph *PostingHandler) wizardHandler(c echo.Context) error {
+	p, err := ph.posting(c)
+	if err != nil {
+		return jobError(c, err)
+	}
+
+	step := c.QueryParam("step")
+	if !employer_views.IsPostingStep(step) {
+		step = employer_views.PostingSteps[0].Name
+	}
+
+	return ph.renderWizard(c, p, step, "")
+}
+
+// saveStepHandler saves the fields of one wizard step and moves on to the
+// step the employer asked for, the next one by default.
+func (ph *PostingHandler) saveStepHandler(c echo.Context) error {
+	p, err := ph.posting(c)
+	if err != nil {
+		return jobError(c, err)
+	}
+
+	step := c.Param("step")
+	if !employer_views.IsPostingStep(step) {
+		return c.String(http.StatusNotFound, printer(c).T("unknown step"))
+	}
+	applyStep(c, &p, step)
+
+	err = ph.PostingService.Save(currentEmployer(c).ID, p)
+	if errors.Is(err, services.ErrPostingClosed) {
+		return ph.renderWizard(c, p, step, printer(c).Error(err))
+	}
+	if err != nil {
+		return jobError(c, err)
+	}
+
+	if p, err = ph.posting(c); err != nil {
+		return jobError(c, err)
+	}
+
+	next := c.FormValue("next")
+	if !employer_views.IsPostingStep(next) {
+		next = employer_views.NextPostingStep(step)
+	}
+
+	return ph.renderWizard(c, p, next, "")
+}
+
+// previewHandler renders the description as the job page will show it.
+func (ph *PostingHandler) previewHandler(c echo.Context) error {
+	return renderView(c, employer_views.DescriptionPreview(c.FormValue("description")))
+}
+
+// publishHandler puts the posting live or schedules it, then returns to
+// the dashboard.
+func (ph *PostingHandler) publishHandler(c echo.Context) error {
+	p, err := ph.posting(c)
+	if err != nil {
+		return jobError(c, err)
+	}
+
+	publishAt, expiresAt, err := publishDates(c)
+	if err == nil {
+		err = ph.PostingService.Publish(currentEmployer(c).ID, p.ID, publishAt, expiresAt)
+	}
+	var incomplete *services.IncompleteError
+	if errors.As(err, &incomplete) {
+		t := printer(c)
+		problems := make([]string, len(incomplete.Problems))
+		for i, problem := range incomplete.Problems {
+			problems[i] = t.T(problem)
+		}
+		return ph.renderWizard(c, p, "review", t.T("the posting is not ready to publish: add %s", strings.Join(problems, ", ")))
+	}
+	if errors.Is(err, errPostingDate) ||
+		errors.Is(err, services.ErrPostingIncomplete) ||
+		errors.Is(err, services.ErrExpiryBeforeLive) ||
+		errors.Is(err, services.ErrPostingClosed) {
+		return ph.renderWizard(c, p, "review", printer(c).Error(err))
+	}
+	if err != nil {
+		return jobError(c, err)
+	}
+
+	return redirect(c, "/employer")
+}
+
+// closeHandler takes a posting down and renders its updated dashboard row.
+func (ph *PostingHandler) closeHandler(c echo.Context) error {
+	p, err := ph.posting(c)
+	if err != nil {
+		return jobError(c, err)
+	}
+
+	err = ph.PostingService.Close(currentEmployer(c).ID, p.ID)
+	if errors.Is(err, services.ErrPostingClosed) {
+		return c.String(http.StatusConflict, printer(c).Error(err))
+	}
+	if err != nil {
+		return jobError(c, err)
+	}
+
+	if p, err = ph.posting(c); err != nil {
+		return jobError(c, err)
+	}
+
+	return renderView(c, employer_views.PostingItem(p, ph.Billing))
+}
+
+func (ph *PostingHandler) historyHandler(c echo.Context) error {
+	p, err := ph.posting(c)
+	if err != nil {
+		return jobError(c, err)
+	}
+
+	history, err := ph.PostingService.History(currentEmployer(c).ID, p.ID)
+	if err != nil {
+		return jobError(c, err)
+	}
+
+	return renderView(c, employer_views.EmployerIndex(printer(c).T("Posting history"), employer_views.History(p, history)))
+}
+
+func (ph *PostingHandler) renderWizard(c echo.Context, p services.Posting, step, problem string) error {
+	if isHTMX(c) {
+		return renderView(c, employer_views.PostingWizard(p, step, problem))
+	}
+	return renderView(c, employer_views.EmployerIndex(printer(c).T("Edit posting"), employer_views.PostingPage(p, step, problem)))
+}
+
+func (ph *PostingHandler) posting(c echo.Context) (services.Posting, error) {
+	return ownPosting(c, ph.PostingService)
+}
+
+// ownPosting is the posting in the URL, if the signed in employer owns it.
+func ownPosting(c echo.Context, ps PostingService) (services.Posting, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return services.Posting{}, services.ErrJobNotFound
+	}
+	return ps.Posting(currentEmployer(c).ID, id)
+}
+
+// applyStep copies the fields of a wizard step's form into p.
+func applyStep(c echo.Context, p *services.Posting, step string) {
+	switch step {
+	case "details":
+		p.Title = c.FormValue("title")
+		p.Department = c.FormValue("department")
+		p.Employment, _ = domain.ParseEmploymentType(c.FormValue("employment"))
+		p.Workplace, _ = domain.ParseJobType(c.FormValue("workplace"))
+	case "description":
+		p.Description = c.FormValue("description")
+	case "location":
+		p.Location = c.FormValue("location")
+		p.Salary = c.FormValue("salary")
+	}
+}
+
+// publishDates reads the go-live time and expiry date, both in server
+// time. The posting expires at the end of its expiry date. Blank dates are
+// zero.
+func publishDates(c echo.Context) (time.Time, time.Time, error) {
+	var publishAt, expiresAt time.Time
+
+	if v := strings.TrimSpace(c.FormValue("publish_at")); v != "" {
+		t, err := time.ParseInLocation(publishAtLayout, v, time.Local)
+		if err != nil {
+			return publishAt, expiresAt, errPostingDate
+		}
+		publishAt = t
+	}
+
+	if v := strings.TrimSpace(c.FormValue("expires_at")); v != "" {
+		t, err := time.ParseInLocation(expiresAtLayout, v, time.Local)
+		if err != nil {
+			return publishAt, expiresAt, errPostingDate
+		}
+		expiresAt = t.AddDate(0, 0, 1)
+	}
+
+	return publishAt, expiresAt, nil
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/igorrize/htmxjb/services"
+	"github.com/igorrize/htmxjb/views/job_views"
+	"github.com/labstack/echo/v4"
+)
+
+type SeekerProfileService interface {
+	Upload(resume services.Resume) (string, services.SeekerProfile, error)
+	Profile(token string) (services.SeekerProfile, error)
+	Forget(token string) error
+}
+
+type ResumeHandler struct {
+	SeekerProfileService SeekerProfileService
+	MaxResumeBytes       int64
+}
+
+func NewResumeHandler(ss SeekerProfileService, maxResumeBytes int64) *ResumeHandler {
+	return &ResumeHandler{
+		SeekerProfileService: ss,
+		MaxResumeBytes:       maxResumeBytes,
+	}
+}
+
+func (rh *ResumeHandler) resumeFormHandler(c echo.Context) error {
+	return renderView(c, job_views.JobIndex(printer(c).T("Your resume"), job_views.ResumeUpload(currentProfile(c), "")))
+}
+
+// uploadHandler reads the resume into a profile and shows the jobs that
+// fit it best.
+func (rh *ResumeHandler) uploadHandler(c echo.Context) error {
+	err := parseApplication(c, rh.MaxResumeBytes)
+	var resume services.Resume
+	if err == nil {
+		resume, err = readResume(c, rh.MaxResumeBytes)
+	}
+
+	var (
+		token   string
+		expires time.Time
+	)
+	if err == nil {
+		var profile services.SeekerProfile
+		token, profile, err = rh.SeekerProfileService.Upload(resume)
+		expires = profile.ExpiresAt
+	}
+	if errors.Is(err, errBadForm) {
+		return c.String(http.StatusBadRequest, printer(c).Error(err))
+	}
+	if errors.Is(err, services.ErrResumeRequired) ||
+		errors.Is(err, services.ErrResumeType) ||
+		errors.Is(err, services.ErrResumeTooLarge) ||
+		errors.Is(err, services.ErrResumeUnreadable) {
+		c.Response().WriteHeader(http.StatusUnprocessableEntity)
+		return renderView(c, job_views.JobIndex(printer(c).T("Your resume"), job_views.ResumeUpload(currentProfile(c), printer(c).Error(err))))
+	}
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	c.SetCookie(profileCookie(c, token, expires))
+	return redirect(c, "/?sort="+string(services.SortRecommended))
+}
+
+// forgetHandler deletes the seeker's profile.
+func (rh *ResumeHandler) forgetHandler(c echo.Context) error {
+	if cookie, err := c.Cookie(seekerCookie); err == nil {
+		if err := rh.SeekerProfileService.Forget(cookie.Value); err != nil {
+			return c.String(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	c.SetCookie(profileCookie(c, "", time.Unix(0, 0)))
+	return redirect(c, "/resume")
+}
+
+// profileCookie holds the token of the seeker's resume profile. Like the
+// employer session cookie it is kept from scripts and other sites' posts.
+func profileCookie(c echo.Context, token string, expires time.Time) *http.Cookie {
+	return &http.Cookie{
+		Name:     seekerCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   c.Scheme() == "https",
+		SameSite: http.SameSiteLaxMode,
+	}
+}
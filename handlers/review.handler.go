@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/igorrize/htmxjb/services"
+	"github.com/igorrize/htmxjb/views/admin_views"
+	"github.com/labstack/echo/v4"
+)
+
+type ReviewService interface {
+	PendingJobs() ([]services.ReviewJob, error)
+	PendingJob(id int) (services.ReviewJob, error)
+	Approve(ids ...int) (int64, error)
+	Reject(reason string, ids ...int) (int64, error)
+	Edit(id int, title, description string) error
+}
+
+type ReviewHandler struct {
+	ReviewService ReviewService
+}
+
+func NewReviewHandler(rs ReviewService) *ReviewHandler {
+	return &ReviewHandler{
+		ReviewService: rs,
+	}
+}
+
+func (rh *ReviewHandler) queueHandler(c echo.Context) error {
+	jobs, err := rh.ReviewService.PendingJobs()
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return renderView(c, admin_views.AdminIndex(printer(c).T("Review queue"), admin_views.ReviewQueue(jobs)))
+}
+
+// jobHandler renders one queued job, e.g. when an edit is cancelled.
+func (rh *ReviewHandler) jobHandler(c echo.Context) error {
+	job, err := rh.pendingJob(c)
+	if err != nil {
+		return jobError(c, err)
+	}
+
+	return renderView(c, admin_views.ReviewItem(job))
+}
+
+func (rh *ReviewHandler) editFormHandler(c echo.Context) error {
+	job, err := rh.pendingJob(c)
+	if err != nil {
+		return jobError(c, err)
+	}
+
+	return renderView(c, admin_views.ReviewEditForm(job, ""))
+}
+
+func (rh *ReviewHandler) editHandler(c echo.Context) error {
+	job, err := rh.pendingJob(c)
+	if err != nil {
+		return jobError(c, err)
+	}
+
+	err = rh.ReviewService.Edit(job.ID, c.FormValue("title"), c.FormValue("description"))
+	if errors.Is(err, services.ErrTitleRequired) {
+		job.Title, job.Description = c.FormValue("title"), c.FormValue("description")
+		return renderView(c, admin_views.ReviewEditForm(job, printer(c).Error(err)))
+	}
+	if err != nil {
+		return jobError(c, err)
+	}
+
+	return rh.jobHandler(c)
+}
+
+// approveHandler and rejectHandler answer with an empty body so htmx
+// removes the job from the queue.
+func (rh *ReviewHandler) approveHandler(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.String(http.StatusNotFound, printer(c).Error(services.ErrJobNotFound))
+	}
+
+	if _, err := rh.ReviewService.Approve(id); err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (rh *ReviewHandler) rejectHandler(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.String(http.StatusNotFound, printer(c).Error(services.ErrJobNotFound))
+	}
+
+	_, err = rh.ReviewService.Reject(rejectionReason(c), id)
+	if errors.Is(err, services.ErrReasonRequired) {
+		return c.String(http.StatusUnprocessableEntity, printer(c).Error(err))
+	}
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// bulkHandler approves or rejects every checked job and renders what is
+// left of the queue.
+func (rh *ReviewHandler) bulkHandler(c echo.Context) error {
+	form, err := c.FormParams()
+	if err != nil {
+		return c.String(http.StatusBadRequest, printer(c).Error(err))
+	}
+
+	var ids []int
+	for _, v := range form["id"] {
+		if id, err := strconv.Atoi(v); err == nil {
+			ids = append(ids, id)
+		}
+	}
+
+	switch c.FormValue("action") {
+	case "approve":
+		_, err = rh.ReviewService.Approve(ids...)
+	case "reject":
+		_, err = rh.ReviewService.Reject(rejectionReason(c), ids...)
+	default:
+		return c.String(http.StatusBadRequest, printer(c).T("unknown action"))
+	}
+	if errors.Is(err, services.ErrReasonRequired) {
+		return c.String(http.StatusUnprocessableEntity, printer(c).Error(err))
+	}
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	jobs, err := rh.ReviewService.PendingJobs()
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return renderView(c, admin_views.ReviewItems(jobs))
+}
+
+func (rh *ReviewHandler) pendingJob(c echo.Context) (services.ReviewJob, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return services.ReviewJob{}, services.ErrJobNotFound
+	}
+	return rh.ReviewService.PendingJob(id)
+}
+
+// jobError answers with the status err calls for.
+func jobError(c echo.Context, err error) error {
+	if errors.Is(err, services.ErrJobNotFound) {
+		return c.String(http.StatusNotFound, printer(c).Error(err))
+	}
+	return c.String(http.StatusInternalServerError, err.Error())
+}
+
+// rejectionReason joins the chosen reason with the moderator's note.
+func rejectionReason(c echo.Context) string {
+	reason := strings.TrimSpace(c.FormValue("reason"))
+	note := strings.TrimSpace(c.FormValue("note"))
+	if reason != "" && note != "" {
+		return reason + ": " + note
+	}
+	return reason + note
+}
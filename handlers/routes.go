@@ -1,9 +1,72 @@
 package handlers
 
 import (
+	"github.com/igorrize/htmxjb/services/payments"
 	"github.com/labstack/echo/v4"
 )
 
-func SetupRoutes(e *echo.Echo, jh *JobHandler) {
-	e.GET("/", jh.jobListHandler)
+func SetupRoutes(e *echo.Echo, jh *JobHandler, bh *BackupHandler, sh *SourceHandler, rh *ReviewHandler, ch *CompanyHandler, eh *EmployerHandler, ph *PostingHandler, bl *BillingHandler, ah *ApplicationHandler, rs *ResumeHandler, sm *SimilarHandler, st *StreamHandler, adminAuth echo.MiddlewareFunc) {
+	profile := SeekerProfile(rs.SeekerProfileService)
+	e.GET("/", jh.jobListHandler, profile)
+	e.GET("/jobs/stream", st.streamHandler, profile)
+	e.GET("/jobs/:id", jh.jobDetailHandler)
+	e.GET("/jobs/:id/similar", sm.similarHandler)
+	e.GET("/jobs/:id/apply", ah.applyFormHandler)
+	e.POST("/jobs/:id/apply", ah.applyHandler)
+	e.GET("/companies/:slug", ch.profileHandler)
+	e.GET("/resume", rs.resumeFormHandler, profile)
+	e.POST("/resume", rs.uploadHandler, profile)
+	e.POST("/resume/forget", rs.forgetHandler)
+
+	e.GET("/employer/signup", eh.signupFormHandler)
+	e.POST("/employer/signup", eh.signupHandler)
+	e.GET("/employer/login", eh.loginFormHandler)
+	e.POST("/employer/login", eh.loginHandler)
+	e.POST("/employer/logout", eh.logoutHandler)
+
+	// Without a payment provider there is no billing handler and paid
+	// features are off.
+	if bl != nil {
+		e.POST("/payments/webhook", bl.webhookHandler)
+		if _, ok := bl.Provider.(*payments.Fake); ok {
+			e.GET("/payments/fake/:ref", bl.fakeCheckoutHandler)
+			e.POST("/payments/fake/:ref", bl.fakeCompleteHandler)
+		}
+	}
+
+	employer := e.Group("/employer", EmployerAuth(eh.EmployerService))
+	employer.GET("", ph.dashboardHandler)
+	employer.POST("/jobs", ph.newHandler)
+	employer.POST("/preview", ph.previewHandler)
+	employer.GET("/jobs/:id", ph.wizardHandler)
+	employer.POST("/jobs/:id/steps/:step", ph.saveStepHandler)
+	employer.POST("/jobs/:id/publish", ph.publishHandler)
+	employer.POST("/jobs/:id/close", ph.closeHandler)
+	employer.GET("/jobs/:id/history", ph.historyHandler)
+	employer.GET("/jobs/:id/applications", ah.inboxHandler)
+	employer.GET("/applications/:id/resume", ah.resumeHandler)
+	if bl != nil {
+		employer.GET("/jobs/:id/promote", bl.promoteHandler)
+		employer.POST("/jobs/:id/promote", bl.checkoutHandler)
+		employer.GET("/billing", bl.ordersHandler)
+		employer.GET("/invoices/:number", bl.invoiceHandler)
+	}
+
+	admin := e.Group("/admin", SameOrigin(), adminAuth)
+	admin.GET("/backups", bh.listBackupsHandler)
+	admin.POST("/backups", bh.createBackupHandler)
+	admin.GET("/sources", sh.sourcesHandler)
+	admin.GET("/sources/runs/:id", sh.runDetailHandler)
+	admin.GET("/review", rh.queueHandler)
+	admin.POST("/review/bulk", rh.bulkHandler)
+	admin.GET("/review/:id", rh.jobHandler)
+	admin.PUT("/review/:id", rh.editHandler)
+	admin.GET("/review/:id/edit", rh.editFormHandler)
+	admin.POST("/review/:id/approve", rh.approveHandler)
+	admin.POST("/review/:id/reject", rh.rejectHandler)
+	admin.GET("/companies", ch.listHandler)
+	admin.POST("/companies/merge", ch.mergeHandler)
+	admin.GET("/companies/:id", ch.companyHandler)
+	admin.PUT("/companies/:id", ch.editHandler)
+	admin.GET("/companies/:id/edit", ch.editFormHandler)
 }
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/igorrize/htmxjb/services"
+	"github.com/igorrize/htmxjb/views/job_views"
+	"github.com/labstack/echo/v4"
+)
+
+type SimilarService interface {
+	Similar(jobID int) ([]services.Job, error)
+}
+
+// similarMaxAge is how long browsers may reuse a similar jobs panel. The
+// lists only change when they are recomputed in the background.
+const similarMaxAge = "300"
+
+type SimilarHandler struct {
+	SimilarService SimilarService
+}
+
+func NewSimilarHandler(ss SimilarService) *SimilarHandler {
+	return &SimilarHandler{
+		SimilarService: ss,
+	}
+}
+
+// similarHandler renders the similar jobs panel the detail page loads.
+func (sh *SimilarHandler) similarHandler(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.String(http.StatusNotFound, printer(c).Error(services.ErrJobNotFound))
+	}
+
+	jobs, err := sh.SimilarService.Similar(id)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	c.Response().Header().Set("Cache-Control", "public, max-age="+similarMaxAge)
+	return renderView(c, job_views.SimilarJobs(jobs))
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/igorrize/htmxjb/clients/http_client"
+	"github.com/igorrize/htmxjb/services"
+	"github.com/igorrize/htmxjb/views/admin_views"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// healthWindow is how far back error rates and added jobs are shown.
+	healthWindow = 14 * 24 * time.Hour
+	// recentRuns is how many runs the dashboard lists.
+	recentRuns = 25
+)
+
+type RunHistoryService interface {
+	Health(since, now time.Time) ([]services.SourceHealth, error)
+	Recent(limit int) ([]services.IngestionRun, error)
+	Run(id int64) (services.IngestionRun, error)
+}
+
+// QuotaReporter reports the API quota each host last sent.
+type QuotaReporter interface {
+	Quotas() map[string]http_client.Quota
+}
+
+type SourceHandler struct {
+	History RunHistoryService
+	Quotas  QuotaReporter
+}
+
+func NewSourceHandler(rh RunHistoryService, qr QuotaReporter) *SourceHandler {
+	return &SourceHandler{
+		History: rh,
+		Quotas:  qr,
+	}
+}
+
+func (sh *SourceHandler) sourcesHandler(c echo.Context) error {
+	now := time.Now()
+
+	health, err := sh.History.Health(now.Add(-healthWindow), now)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	runs, err := sh.History.Recent(recentRuns)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	title := "Sources"
+	return renderView(c, admin_views.AdminIndex(title, admin_views.Sources(health, runs, sh.Quotas.Quotas())))
+}
+
+func (sh *SourceHandler) runDetailHandler(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.String(http.StatusNotFound, printer(c).Error(services.ErrRunNotFound))
+	}
+
+	run, err := sh.History.Run(id)
+	if errors.Is(err, services.ErrRunNotFound) {
+		return c.String(http.StatusNotFound, printer(c).Error(err))
+	}
+	if err != nil {
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+
+	return renderView(c, admin_views.AdminIndex(printer(c).T("Run #%s", c.Param("id")), admin_views.RunDetail(run)))
+}
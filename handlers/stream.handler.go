@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/igorrize/htmxjb/services"
+	"github.com/igorrize/htmxjb/services/events"
+	"github.com/igorrize/htmxjb/views/job_views"
+	"github.com/labstack/echo/v4"
+)
+
+// JobEvents hands out subscriptions to newly listed jobs.
+type JobEvents interface {
+	Subscribe(buffer int) (<-chan events.JobCreated, func())
+}
+
+// FilteredJobs looks up a new job as the visitor's list would show it.
+type FilteredJobs interface {
+	FilteredJob(id int, filter services.JobFilter) (services.Job, error)
+}
+
+const (
+	// streamBuffer is how many new jobs may queue for a slow client
+	// before it misses some.
+	streamBuffer = 32
+	// streamHeartbeat keeps idle connections from being cut by proxies.
+	streamHeartbeat = 30 * time.Second
+	// jobCreatedEvent names the server-sent event carrying a new card.
+	jobCreatedEvent = "job-created"
+)
+
+type StreamHandler struct {
+	JobHandler   *JobHandler
+	FilteredJobs FilteredJobs
+	Events       JobEvents
+}
+
+func NewStreamHandler(jh *JobHandler, fj FilteredJobs, ev JobEvents) *StreamHandler {
+	return &StreamHandler{
+		JobHandler:   jh,
+		FilteredJobs: fj,
+		Events:       ev,
+	}
+}
+
+// streamHandler sends the cards of newly listed jobs that match the
+// filters in the query string, as server-sent events, until the client
+// goes away.
+func (sh *StreamHandler) streamHandler(c echo.Context) error {
+	filter := sh.JobHandler.parseJobFilter(c)
+	created, unsubscribe := sh.Events.Subscribe(streamBuffer)
+	defer unsubscribe()
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	count := 0
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(res, ": ping\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		case e, ok := <-created:
+			if !ok {
+				return nil
+			}
+
+			job, err := sh.FilteredJobs.FilteredJob(e.JobID, filter)
+			if errors.Is(err, services.ErrJobNotFound) {
+				continue
+			}
+			if err != nil {
+				log.Printf("🔥 failed to load new job %d: %s", e.JobID, err)
+				continue
+			}
+
+			count++
+			var card bytes.Buffer
+			if err := job_views.LiveJob(job, count).Render(c.Request().Context(), &card); err != nil {
+				log.Printf("🔥 failed to render new job %d: %s", e.JobID, err)
+				continue
+			}
+			if err := writeEvent(res, jobCreatedEvent, card.String()); err != nil {
+				return nil
+			}
+			res.Flush()
+		}
+	}
+}
+
+// writeEvent writes one server-sent event, splitting data over as many
+// data lines as it has lines.
+func writeEvent(res *echo.Response, name, data string) error {
+	var b strings.Builder
+	b.WriteString("event: " + name + "\n")
+	for _, line := range strings.Split(data, "\n") {
+		b.WriteString("data: " + strings.TrimSuffix(line, "\r") + "\n")
+	}
+	b.WriteString("\n")
+
+	_, err := res.Write([]byte(b.String()))
+	return err
+}
@@ -1,5 +1,10 @@
 package domain
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 type JobSource int
 
@@ -7,8 +12,16 @@ const (
 	Indeed JobSource = iota
 	LinkedIn
 	Csv
+	CareerPage
+	Greenhouse
+	Lever
+	Ashby
+	// Direct jobs are posted by employers on the board itself.
+	Direct
 )
 
+var jobSources = []JobSource{Indeed, LinkedIn, Csv, CareerPage, Greenhouse, Lever, Ashby, Direct}
+
 func (js JobSource) String() string {
 	switch js {
 	case Indeed:
@@ -17,39 +30,172 @@ func (js JobSource) String() string {
 		return "linkedin"
 	case Csv:
 		return "csv"
+	case CareerPage:
+		return "careers"
+	case Greenhouse:
+		return "greenhouse"
+	case Lever:
+		return "lever"
+	case Ashby:
+		return "ashby"
+	case Direct:
+		return "direct"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseJobSource is the inverse of JobSource.String.
+func ParseJobSource(s string) (JobSource, error) {
+	for _, source := range jobSources {
+		if source.String() == s {
+			return source, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown job source %q", s)
+}
+
+type JobStatus int
+
+const (
+	Active JobStatus = iota
+	Closed
+	Archived
+	// Pending jobs wait for a moderator and Rejected ones were turned
+	// down. Neither is listed publicly.
+	Pending
+	Rejected
+	// Draft postings are still being written by their employer and
+	// Scheduled ones go live at their publish time.
+	Draft
+	Scheduled
+)
+
+func (js JobStatus) String() string {
+	switch js {
+	case Active:
+		return "active"
+	case Closed:
+		return "closed"
+	case Archived:
+		return "archived"
+	case Pending:
+		return "pending"
+	case Rejected:
+		return "rejected"
+	case Draft:
+		return "draft"
+	case Scheduled:
+		return "scheduled"
 	default:
 		return "unknown"
 	}
 }
 
-type JobType int
+type SalaryPeriod int
 
 const (
-	Remote JobType = iota
-	Onsite
-	Hybrid
+	UnknownPeriod SalaryPeriod = iota
+	Hourly
+	Daily
+	Weekly
+	Monthly
+	Yearly
 )
 
-func (jt JobType) String() string {
-	switch jt {
-	case Remote:
-		return "remote"
-	case Onsite:
-		return "onsite"
-	case Hybrid:
-		return "hybrid"
+func (sp SalaryPeriod) String() string {
+	switch sp {
+	case Hourly:
+		return "hourly"
+	case Daily:
+		return "daily"
+	case Weekly:
+		return "weekly"
+	case Monthly:
+		return "monthly"
+	case Yearly:
+		return "yearly"
 	default:
 		return "unknown"
 	}
 }
 
+// Salary is a pay range as advertised, plus the same range converted to a
+// yearly amount in the base currency for filtering and sorting.
+type Salary struct {
+	Raw       string
+	Min       float64
+	Max       float64
+	Currency  string
+	Period    SalaryPeriod
+	AnnualMin float64
+	AnnualMax float64
+}
+
+// Location is where a job is based. Raw keeps the text the source sent.
+type Location struct {
+	Raw         string
+	City        string
+	Region      string
+	CountryCode string
+	Latitude    float64
+	Longitude   float64
+	Remote      bool
+}
+
+func (l Location) HasCoordinates() bool {
+	return l.Latitude != 0 || l.Longitude != 0
+}
+
+// String formats the location for display.
+func (l Location) String() string {
+	var parts []string
+	for _, part := range []string{l.City, l.Region, l.CountryCode} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	place := strings.Join(parts, ", ")
+	if place == "" && !l.Remote {
+		place = l.Raw
+	}
+
+	switch {
+	case l.Remote && place != "":
+		return "Remote (" + place + ")"
+	case l.Remote:
+		return "Remote"
+	default:
+		return place
+	}
+}
+
 type Job struct {
-	ID          int64
-	ExternalID  string
-	Title       string
-	Description string
-	Type        JobType
-	Source      JobSource
+	ID              int64
+	ExternalID      string
+	Title           string
+	Description     string
+	DescriptionHTML string
+	Type            JobType
+	Employment      EmploymentType
+	TypeText        string
+	Company         string
+	CompanyWebsite  string
+	CompanyLogo     string
+	CompanyID       int64 // set once the job is linked to a company profile
+	Department      string
+	URL             string
+	Source          JobSource
+	Status          JobStatus
+	Salary          Salary
+	Location        Location
+	Tags            []string
+	RejectionReason string  // why a job stored as Rejected was turned down
+	SpamScore       float64 // from 0 to 1, explained by SpamReasons
+	SpamReasons     []string
+	LastSeenAt      time.Time
+	ExpiresAt       time.Time
 }
 
 // ID          int       `json:"id"`
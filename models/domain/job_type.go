@@ -0,0 +1,125 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// JobType is the workplace arrangement of a job.
+type JobType int
+
+const (
+	Remote JobType = iota
+	Onsite
+	Hybrid
+	UnknownJobType
+)
+
+var jobTypes = []JobType{Remote, Onsite, Hybrid}
+
+func (jt JobType) String() string {
+	switch jt {
+	case Remote:
+		return "remote"
+	case Onsite:
+		return "onsite"
+	case Hybrid:
+		return "hybrid"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseJobType is the inverse of JobType.String.
+func ParseJobType(s string) (JobType, error) {
+	for _, jt := range jobTypes {
+		if jt.String() == s {
+			return jt, nil
+		}
+	}
+	return UnknownJobType, fmt.Errorf("unknown job type %q", s)
+}
+
+func (jt *JobType) Scan(src interface{}) error {
+	n, err := scanEnum(src, int(UnknownJobType), func(s string) (int, error) {
+		v, err := ParseJobType(s)
+		return int(v), err
+	})
+	*jt = JobType(n)
+	return err
+}
+
+func (jt JobType) Value() (driver.Value, error) {
+	return int64(jt), nil
+}
+
+// EmploymentType is the contract a job is offered on.
+type EmploymentType int
+
+const (
+	UnknownEmployment EmploymentType = iota
+	FullTime
+	PartTime
+	Contract
+	Internship
+	Temporary
+)
+
+var employmentTypes = []EmploymentType{FullTime, PartTime, Contract, Internship, Temporary}
+
+func (et EmploymentType) String() string {
+	switch et {
+	case FullTime:
+		return "full-time"
+	case PartTime:
+		return "part-time"
+	case Contract:
+		return "contract"
+	case Internship:
+		return "internship"
+	case Temporary:
+		return "temporary"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseEmploymentType is the inverse of EmploymentType.String.
+func ParseEmploymentType(s string) (EmploymentType, error) {
+	for _, et := range employmentTypes {
+		if et.String() == s {
+			return et, nil
+		}
+	}
+	return UnknownEmployment, fmt.Errorf("unknown employment type %q", s)
+}
+
+func (et *EmploymentType) Scan(src interface{}) error {
+	n, err := scanEnum(src, int(UnknownEmployment), func(s string) (int, error) {
+		v, err := ParseEmploymentType(s)
+		return int(v), err
+	})
+	*et = EmploymentType(n)
+	return err
+}
+
+func (et EmploymentType) Value() (driver.Value, error) {
+	return int64(et), nil
+}
+
+// scanEnum reads an enum stored either as its integer value or as its
+// String form. NULL scans as unknown.
+func scanEnum(src interface{}, unknown int, parse func(string) (int, error)) (int, error) {
+	switch v := src.(type) {
+	case int64:
+		return int(v), nil
+	case string:
+		return parse(v)
+	case []byte:
+		return parse(string(v))
+	case nil:
+		return unknown, nil
+	default:
+		return unknown, fmt.Errorf("cannot scan %T into enum", src)
+	}
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobTypeRoundTrip(t *testing.T) {
+	for _, jt := range jobTypes {
+		parsed, err := ParseJobType(jt.String())
+		assert.NoError(t, err)
+		assert.Equal(t, jt, parsed)
+
+		value, err := jt.Value()
+		assert.NoError(t, err)
+
+		var scanned JobType
+		assert.NoError(t, scanned.Scan(value))
+		assert.Equal(t, jt, scanned)
+	}
+
+	_, err := ParseJobType("underwater")
+	assert.Error(t, err)
+}
+
+func TestEmploymentTypeRoundTrip(t *testing.T) {
+	for _, et := range employmentTypes {
+		parsed, err := ParseEmploymentType(et.String())
+		assert.NoError(t, err)
+		assert.Equal(t, et, parsed)
+
+		value, err := et.Value()
+		assert.NoError(t, err)
+
+		var scanned EmploymentType
+		assert.NoError(t, scanned.Scan(value))
+		assert.Equal(t, et, scanned)
+
+		assert.NoError(t, scanned.Scan([]byte(et.String())))
+		assert.Equal(t, et, scanned)
+	}
+
+	var scanned EmploymentType
+	assert.NoError(t, scanned.Scan(nil))
+	assert.Equal(t, UnknownEmployment, scanned)
+}
+
+func TestParseJobSource(t *testing.T) {
+	for _, source := range jobSources {
+		parsed, err := ParseJobSource(source.String())
+		assert.NoError(t, err)
+		assert.Equal(t, source, parsed)
+	}
+
+	_, err := ParseJobSource("newspaper")
+	assert.Error(t, err)
+}
@@ -0,0 +1,57 @@
+package responses
+
+// AshbyResponse is the Ashby job board API response,
+// GET /posting-api/job-board/{name}?includeCompensation=true. Fields tagged
+// omitempty are not sent for every job.
+type AshbyResponse struct {
+	APIVersion string     `json:"apiVersion"`
+	Jobs       []AshbyJob `json:"jobs"`
+}
+
+type AshbyJob struct {
+	ID                 string                   `json:"id"`
+	Title              string                   `json:"title"`
+	Department         string                   `json:"department"`
+	Team               string                   `json:"team"`
+	EmploymentType     string                   `json:"employmentType"`
+	Location           string                   `json:"location"`
+	SecondaryLocations []AshbySecondaryLocation `json:"secondaryLocations,omitempty"`
+	Address            *AshbyAddress            `json:"address,omitempty"`
+	IsRemote           bool                     `json:"isRemote"`
+	WorkplaceType      string                   `json:"workplaceType,omitempty"`
+	IsListed           bool                     `json:"isListed"`
+	PublishedAt        string                   `json:"publishedAt"`
+	JobURL             string                   `json:"jobUrl"`
+	ApplyURL           string                   `json:"applyUrl"`
+	DescriptionHTML    string                   `json:"descriptionHtml"`
+	DescriptionPlain   string                   `json:"descriptionPlain"`
+	Compensation       *AshbyCompensation       `json:"compensation,omitempty"`
+}
+
+type AshbySecondaryLocation struct {
+	Location string        `json:"location"`
+	Address  *AshbyAddress `json:"address,omitempty"`
+}
+
+type AshbyAddress struct {
+	PostalAddress struct {
+		AddressLocality string `json:"addressLocality,omitempty"`
+		AddressRegion   string `json:"addressRegion,omitempty"`
+		AddressCountry  string `json:"addressCountry,omitempty"`
+	} `json:"postalAddress"`
+}
+
+type AshbyCompensation struct {
+	CompensationTierSummary string                       `json:"compensationTierSummary,omitempty"`
+	SummaryComponents       []AshbyCompensationComponent `json:"summaryComponents"`
+}
+
+// AshbyCompensationComponent is one part of the pay, such as the salary or
+// equity. Interval reads like "1 YEAR".
+type AshbyCompensationComponent struct {
+	CompensationType string   `json:"compensationType"`
+	Interval         string   `json:"interval"`
+	CurrencyCode     string   `json:"currencyCode,omitempty"`
+	MinValue         *float64 `json:"minValue"`
+	MaxValue         *float64 `json:"maxValue"`
+}
@@ -0,0 +1,55 @@
+package responses
+
+import "encoding/json"
+
+// GreenhouseResponse is a page of the Greenhouse job board API,
+// GET /v1/boards/{token}/jobs?content=true. Fields tagged omitempty are not
+// sent by every board.
+type GreenhouseResponse struct {
+	Jobs []GreenhouseJob `json:"jobs"`
+	Meta struct {
+		Total int `json:"total"`
+	} `json:"meta"`
+}
+
+type GreenhouseJob struct {
+	ID             int64              `json:"id"`
+	InternalJobID  int64              `json:"internal_job_id"`
+	Title          string             `json:"title"`
+	UpdatedAt      string             `json:"updated_at"`
+	FirstPublished string             `json:"first_published,omitempty"`
+	RequisitionID  *string            `json:"requisition_id"`
+	Location       GreenhouseLocation `json:"location"`
+	AbsoluteURL    string             `json:"absolute_url"`
+	CompanyName    string             `json:"company_name,omitempty"`
+	// Content is the description markup, HTML-escaped once more.
+	Content     string                 `json:"content"`
+	Departments []GreenhouseDepartment `json:"departments"`
+	Offices     []GreenhouseOffice     `json:"offices"`
+	Metadata    []GreenhouseMetadata   `json:"metadata"`
+}
+
+type GreenhouseLocation struct {
+	Name string `json:"name"`
+}
+
+type GreenhouseDepartment struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	ParentID *int64 `json:"parent_id,omitempty"`
+}
+
+type GreenhouseOffice struct {
+	ID       int64   `json:"id"`
+	Name     string  `json:"name"`
+	Location *string `json:"location"`
+}
+
+// GreenhouseMetadata is a custom field. Value is a string, a list or null
+// depending on ValueType.
+type GreenhouseMetadata struct {
+	ID        int64           `json:"id"`
+	Name      string          `json:"name"`
+	Value     json.RawMessage `json:"value"`
+	ValueType string          `json:"value_type"`
+}
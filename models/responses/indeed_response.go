@@ -15,6 +15,11 @@ type IndeedData struct {
     Scraper IndeedScraperParams `json:"scraper"`
 }
 
+// IndeedScraperRequest is the body that starts a scrape job.
+type IndeedScraperRequest struct {
+    Scraper IndeedScraperParams `json:"scraper"`
+}
+
 type IndeedScraperParams struct {
     Query     string `json:"query"`
     Location  string `json:"location"`
@@ -23,6 +28,7 @@ type IndeedScraperParams struct {
     Sort      string `json:"sort"`
     FromDays  string `json:"fromDays"`
     Country   string `json:"country"`
+    MaxRows   int    `json:"maxRows,omitempty"`
 }
 
 type IndeedReturnValue struct {
@@ -0,0 +1,215 @@
+package responses
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// JobPosting is a schema.org JobPosting as career pages embed it in
+// JSON-LD. Sites differ in whether they send a value, a list or a nested
+// object, so the field types below accept each form.
+type JobPosting struct {
+	Type               SchemaStrings      `json:"@type"`
+	Title              string             `json:"title"`
+	Description        string             `json:"description"`
+	DatePosted         string             `json:"datePosted"`
+	ValidThrough       string             `json:"validThrough"`
+	EmploymentType     SchemaStrings      `json:"employmentType"`
+	HiringOrganization SchemaOrganization `json:"hiringOrganization"`
+	JobLocation        SchemaPlaces       `json:"jobLocation"`
+	JobLocationType    SchemaStrings      `json:"jobLocationType"`
+	BaseSalary         *SchemaAmount      `json:"baseSalary"`
+	Identifier         SchemaIdentifier   `json:"identifier"`
+	URL                string             `json:"url"`
+}
+
+// SchemaStrings is a text value or a list of them.
+type SchemaStrings []string
+
+func (s *SchemaStrings) UnmarshalJSON(data []byte) error {
+	var list []string
+	if err := json.Unmarshal(data, &list); err == nil {
+		*s = list
+		return nil
+	}
+
+	var one string
+	if err := json.Unmarshal(data, &one); err != nil {
+		return err
+	}
+	if one != "" {
+		*s = SchemaStrings{one}
+	}
+	return nil
+}
+
+// Has reports whether the list has value, ignoring case.
+func (s SchemaStrings) Has(value string) bool {
+	for _, v := range s {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// SchemaNumber is a number that some sites send as a string.
+type SchemaNumber float64
+
+func (n *SchemaNumber) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		f, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(s), ",", ""), 64)
+		if err != nil {
+			return nil
+		}
+		*n = SchemaNumber(f)
+		return nil
+	}
+
+	var f float64
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+	*n = SchemaNumber(f)
+	return nil
+}
+
+// SchemaOrganization is an Organization or just its name.
+type SchemaOrganization struct {
+	Name   string      `json:"name"`
+	SameAs string      `json:"sameAs"`
+	URL    string      `json:"url"`
+	Logo   SchemaImage `json:"logo"`
+}
+
+// SchemaImage is an ImageObject or just its URL.
+type SchemaImage struct {
+	URL string `json:"url"`
+}
+
+func (i *SchemaImage) UnmarshalJSON(data []byte) error {
+	var url string
+	if err := json.Unmarshal(data, &url); err == nil {
+		i.URL = url
+		return nil
+	}
+
+	type image SchemaImage
+	return json.Unmarshal(data, (*image)(i))
+}
+
+func (o *SchemaOrganization) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		o.Name = name
+		return nil
+	}
+
+	type organization SchemaOrganization
+	return json.Unmarshal(data, (*organization)(o))
+}
+
+// SchemaPlaces is a Place or a list of them.
+type SchemaPlaces []SchemaPlace
+
+func (p *SchemaPlaces) UnmarshalJSON(data []byte) error {
+	var list []SchemaPlace
+	if err := json.Unmarshal(data, &list); err == nil {
+		*p = list
+		return nil
+	}
+
+	var one SchemaPlace
+	if err := json.Unmarshal(data, &one); err != nil {
+		return err
+	}
+	*p = SchemaPlaces{one}
+	return nil
+}
+
+type SchemaPlace struct {
+	Address SchemaAddress `json:"address"`
+	Geo     struct {
+		Latitude  SchemaNumber `json:"latitude"`
+		Longitude SchemaNumber `json:"longitude"`
+	} `json:"geo"`
+}
+
+type SchemaAddress struct {
+	AddressCountry  SchemaCountry `json:"addressCountry"`
+	AddressLocality string        `json:"addressLocality"`
+	AddressRegion   string        `json:"addressRegion"`
+	StreetAddress   string        `json:"streetAddress"`
+}
+
+// SchemaCountry is a country code or name, or a Country with a name.
+type SchemaCountry string
+
+func (c *SchemaCountry) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*c = SchemaCountry(name)
+		return nil
+	}
+
+	var country struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &country); err != nil {
+		return err
+	}
+	*c = SchemaCountry(country.Name)
+	return nil
+}
+
+// SchemaAmount is a MonetaryAmount.
+type SchemaAmount struct {
+	Currency string      `json:"currency"`
+	Value    SchemaValue `json:"value"`
+}
+
+// SchemaValue is a QuantitativeValue or a bare amount.
+type SchemaValue struct {
+	Value    SchemaNumber `json:"value"`
+	MinValue SchemaNumber `json:"minValue"`
+	MaxValue SchemaNumber `json:"maxValue"`
+	UnitText string       `json:"unitText"`
+}
+
+func (v *SchemaValue) UnmarshalJSON(data []byte) error {
+	var amount SchemaNumber
+	if err := json.Unmarshal(data, &amount); err == nil {
+		v.Value = amount
+		return nil
+	}
+
+	type value SchemaValue
+	return json.Unmarshal(data, (*value)(v))
+}
+
+// SchemaIdentifier is a PropertyValue or a bare identifier.
+type SchemaIdentifier string
+
+func (id *SchemaIdentifier) UnmarshalJSON(data []byte) error {
+	var prop struct {
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(data, &prop); err == nil && len(prop.Value) > 0 {
+		data = prop.Value
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*id = SchemaIdentifier(s)
+		return nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return nil
+	}
+	*id = SchemaIdentifier(n.String())
+	return nil
+}
@@ -0,0 +1,47 @@
+package responses
+
+// LeverResponse is a page of the Lever postings API,
+// GET /v0/postings/{site}?mode=json. Fields tagged omitempty are not sent
+// for every posting.
+type LeverResponse []LeverPosting
+
+type LeverPosting struct {
+	ID               string            `json:"id"`
+	Text             string            `json:"text"`
+	HostedURL        string            `json:"hostedUrl"`
+	ApplyURL         string            `json:"applyUrl"`
+	CreatedAt        int64             `json:"createdAt"`
+	Categories       LeverCategories   `json:"categories"`
+	Description      string            `json:"description"`
+	DescriptionPlain string            `json:"descriptionPlain"`
+	Lists            []LeverList       `json:"lists"`
+	Additional       string            `json:"additional"`
+	AdditionalPlain  string            `json:"additionalPlain"`
+	WorkplaceType    string            `json:"workplaceType,omitempty"`
+	Country          string            `json:"country,omitempty"`
+	SalaryRange      *LeverSalaryRange `json:"salaryRange,omitempty"`
+}
+
+type LeverCategories struct {
+	Commitment   string   `json:"commitment,omitempty"`
+	Department   string   `json:"department,omitempty"`
+	Location     string   `json:"location,omitempty"`
+	Team         string   `json:"team,omitempty"`
+	AllLocations []string `json:"allLocations,omitempty"`
+}
+
+// LeverList is a titled section of the description; Content is a run of
+// <li> elements.
+type LeverList struct {
+	Text    string `json:"text"`
+	Content string `json:"content"`
+}
+
+// LeverSalaryRange has an interval such as "per-year-salary" or
+// "per-hour-wage".
+type LeverSalaryRange struct {
+	Min      float64 `json:"min"`
+	Max      float64 `json:"max"`
+	Currency string  `json:"currency"`
+	Interval string  `json:"interval"`
+}
@@ -0,0 +1,61 @@
+package responses
+
+// LinkedinResponse is one page of the LinkedIn Job Search API, a plain
+// JSON array of postings. Fields tagged omitempty are not sent for every
+// posting; the rest are required by the client contract tests.
+type LinkedinResponse []LinkedinJob
+
+type LinkedinJob struct {
+	ID               string          `json:"id"`
+	Title            string          `json:"title"`
+	Organization     string          `json:"organization"`
+	OrganizationURL  string          `json:"organization_url"`
+	URL              string          `json:"url"`
+	DatePosted       string          `json:"date_posted"`
+	DateValidThrough *string         `json:"date_validthrough"`
+	EmploymentType   []string        `json:"employment_type"`
+	LocationType     *string         `json:"location_type"`
+	LocationsRaw     []LinkedinPlace `json:"locations_raw"`
+	LocationsDerived []string        `json:"locations_derived"`
+	CitiesDerived    []string        `json:"cities_derived"`
+	RegionsDerived   []string        `json:"regions_derived"`
+	CountriesDerived []string        `json:"countries_derived"`
+	LatsDerived      []float64       `json:"lats_derived"`
+	LngsDerived      []float64       `json:"lngs_derived"`
+	RemoteDerived    bool            `json:"remote_derived"`
+	SalaryRaw        *LinkedinSalary `json:"salary_raw"`
+	DescriptionText  string          `json:"description_text"`
+	DescriptionHTML  string          `json:"description_html"`
+	Seniority        string          `json:"seniority,omitempty"`
+}
+
+// LinkedinPlace is a schema.org Place.
+type LinkedinPlace struct {
+	Type      string          `json:"@type"`
+	Address   LinkedinAddress `json:"address"`
+	Latitude  float64         `json:"latitude,omitempty"`
+	Longitude float64         `json:"longitude,omitempty"`
+}
+
+type LinkedinAddress struct {
+	Type            string `json:"@type"`
+	AddressCountry  string `json:"addressCountry"`
+	AddressLocality string `json:"addressLocality"`
+	AddressRegion   string `json:"addressRegion"`
+	StreetAddress   string `json:"streetAddress"`
+}
+
+// LinkedinSalary is a schema.org MonetaryAmount.
+type LinkedinSalary struct {
+	Type     string              `json:"@type"`
+	Currency string              `json:"currency"`
+	Value    LinkedinSalaryValue `json:"value"`
+}
+
+type LinkedinSalaryValue struct {
+	Type     string   `json:"@type"`
+	Value    *float64 `json:"value,omitempty"`
+	MinValue *float64 `json:"minValue,omitempty"`
+	MaxValue *float64 `json:"maxValue,omitempty"`
+	UnitText string   `json:"unitText"`
+}
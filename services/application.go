@@ -0,0 +1,326 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"htmxjb/db"
+	"htmxjb/models/domain"
+	"htmxjb/services/blob"
+	"htmxjb/services/mail"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Limits on what applicants may send.
+const (
+	defaultMaxResumeBytes = 5 << 20
+	maxCoverLetterLength  = 10000
+)
+
+var (
+	ErrApplicantNameRequired    = errors.New("your name is required")
+	ErrResumeRequired           = errors.New("a resume is required")
+	ErrResumeType               = errors.New("resumes must be PDF or DOCX files")
+	ErrResumeTooLarge           = errors.New("the resume is too large")
+	ErrCoverLetterTooLong       = fmt.Errorf("cover letters are limited to %d characters", maxCoverLetterLength)
+	ErrNotAcceptingApplications = errors.New("this job does not take applications on the board")
+	ErrApplicationNotFound      = errors.New("application not found")
+)
+
+// Resume types accepted, by file extension.
+var resumeTypes = map[string]string{
+	".pdf":  "application/pdf",
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+}
+
+// Application is a candidate's application to a posting.
+type Application struct {
+	ID          int
+	JobID       int
+	Name        string
+	Email       string
+	CoverLetter string
+	ResumeName  string
+	ResumeType  string
+	ResumeSize  int64
+	CreatedAt   time.Time
+
+	resumeKey string
+}
+
+// Resume is an uploaded resume file.
+type Resume struct {
+	Name string
+	Data []byte
+}
+
+// ApplicationService takes applications for postings and shows them to
+// the employers who own the postings. Resumes are kept in Blobs and
+// employers are told about new applications through Mailer.
+type ApplicationService struct {
+	JobStore       db.Store
+	Blobs          blob.Store
+	Mailer         mail.Mailer
+	Postings       *PostingService
+	BaseURL        string
+	MaxResumeBytes int
+	Now            func() time.Time
+}
+
+func NewApplicationService(jobStore db.Store, blobs blob.Store, mailer mail.Mailer, postings *PostingService, baseURL string) *ApplicationService {
+	return &ApplicationService{
+		JobStore:       jobStore,
+		Blobs:          blobs,
+		Mailer:         mailer,
+		Postings:       postings,
+		BaseURL:        strings.TrimRight(baseURL, "/"),
+		MaxResumeBytes: defaultMaxResumeBytes,
+		Now:            time.Now,
+	}
+}
+
+// opening is a live posting that takes applications.
+type opening struct {
+	title      string
+	employerID int
+}
+
+// Apply validates and stores an application, then emails the employer. A
+// failed email is logged; the application is kept either way.
+func (as *ApplicationService) Apply(ctx context.Context, jobID int, a Application, resume Resume) (Application, error) {
+	job, err := as.opening(jobID)
+	if err != nil {
+		return Application{}, err
+	}
+
+	a.JobID = jobID
+	a.Name = strings.TrimSpace(a.Name)
+	a.CoverLetter = strings.TrimSpace(a.CoverLetter)
+	if a.Name == "" {
+		return Application{}, ErrApplicantNameRequired
+	}
+	if a.Email, err = normalizeEmail(a.Email); err != nil {
+		return Application{}, err
+	}
+	if utf8.RuneCountInString(a.CoverLetter) > maxCoverLetterLength {
+		return Application{}, ErrCoverLetterTooLong
+	}
+
+	ext, err := checkResume(resume, as.MaxResumeBytes)
+	if err != nil {
+		return Application{}, err
+	}
+	a.ResumeName = resumeName(resume.Name, ext)
+	a.ResumeType = resumeTypes[ext]
+	a.ResumeSize = int64(len(resume.Data))
+
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return Application{}, fmt.Errorf("failed to store resume: %w", err)
+	}
+	a.resumeKey = fmt.Sprintf("resumes/%d/%s%s", jobID, hex.EncodeToString(raw), ext)
+	if err := as.Blobs.Put(ctx, a.resumeKey, bytes.NewReader(resume.Data)); err != nil {
+		return Application{}, fmt.Errorf("failed to store resume: %w", err)
+	}
+
+	a.CreatedAt = as.Now().UTC()
+	res, err := as.JobStore.Exec(
+		"INSERT INTO applications (job_id, name, email, cover_letter, resume_key, resume_name, resume_type, resume_size, created_at)"+
+			" VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		jobID, a.Name, a.Email, a.CoverLetter, a.resumeKey, a.ResumeName, a.ResumeType, a.ResumeSize, sqlTime(a.CreatedAt),
+	)
+	if err != nil {
+		if err := as.Blobs.Delete(ctx, a.resumeKey); err != nil {
+			log.Printf("🔥 failed to clean up resume %s: %v", a.resumeKey, err)
+		}
+		return Application{}, fmt.Errorf("failed to save application: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return Application{}, fmt.Errorf("failed to save application: %w", err)
+	}
+	a.ID = int(id)
+
+	if err := as.notify(ctx, job, a); err != nil {
+		log.Printf("🔥 failed to notify employer of application %d: %v", a.ID, err)
+	}
+
+	return a, nil
+}
+
+func (as *ApplicationService) opening(jobID int) (opening, error) {
+	var (
+		job        opening
+		status     domain.JobStatus
+		source     domain.JobSource
+		employerID sql.NullInt64
+	)
+	err := as.JobStore.QueryRow("SELECT title, status, source, employer_id FROM jobs WHERE id = ?", jobID).
+		Scan(&job.title, &status, &source, &employerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return opening{}, ErrJobNotFound
+	}
+	if err != nil {
+		return opening{}, fmt.Errorf("failed to get job %d: %w", jobID, err)
+	}
+
+	if status != domain.Active || source != domain.Direct || !employerID.Valid {
+		return opening{}, ErrNotAcceptingApplications
+	}
+	job.employerID = int(employerID.Int64)
+
+	return job, nil
+}
+
+// checkResume checks the resume's size and that its content matches its
+// extension, returning the extension.
+func checkResume(resume Resume, maxBytes int) (string, error) {
+	if len(resume.Data) == 0 {
+		return "", ErrResumeRequired
+	}
+	if len(resume.Data) > maxBytes {
+		return "", ErrResumeTooLarge
+	}
+
+	ext := strings.ToLower(filepath.Ext(resume.Name))
+	switch ext {
+	case ".pdf":
+		if !bytes.HasPrefix(resume.Data, []byte("%PDF-")) {
+			return "", ErrResumeType
+		}
+	case ".docx":
+		if !isDocx(resume.Data) {
+			return "", ErrResumeType
+		}
+	default:
+		return "", ErrResumeType
+	}
+
+	return ext, nil
+}
+
+// isDocx reports whether data is a zip archive holding a Word document.
+func isDocx(data []byte) bool {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return false
+	}
+	for _, f := range archive.File {
+		if f.Name == "word/document.xml" {
+			return true
+		}
+	}
+	return false
+}
+
+// resumeName is the file name shown to and downloaded by the employer,
+// stripped of directories and anything that would upset a
+// Content-Disposition header.
+func resumeName(name, ext string) string {
+	name = filepath.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < ' ' || r == '"' || r == '/' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, name)
+	if strings.TrimSuffix(strings.ToLower(name), ext) == "" {
+		return "resume" + ext
+	}
+	return name
+}
+
+func (as *ApplicationService) notify(ctx context.Context, job opening, a Application) error {
+	var email string
+	if err := as.JobStore.QueryRow("SELECT email FROM employers WHERE id = ?", job.employerID).Scan(&email); err != nil {
+		return fmt.Errorf("failed to get employer %d: %w", job.employerID, err)
+	}
+
+	title := strings.Join(strings.Fields(job.title), " ")
+	return as.Mailer.Send(ctx, mail.Message{
+		To:      email,
+		Subject: "New application for " + title,
+		Body: fmt.Sprintf(
+			"%s <%s> applied for %s.\n\nRead the application and download the resume at\n%s/employer/jobs/%d/applications\n",
+			a.Name, a.Email, title, as.BaseURL, a.JobID,
+		),
+	})
+}
+
+const applicationColumns = "a.id, a.job_id, a.name, a.email, a.cover_letter, a.resume_key, a.resume_name, a.resume_type, a.resume_size, a.created_at"
+
+func scanApplication(row interface{ Scan(...interface{}) error }) (Application, error) {
+	var a Application
+	err := row.Scan(&a.ID, &a.JobID, &a.Name, &a.Email, &a.CoverLetter, &a.resumeKey,
+		&a.ResumeName, &a.ResumeType, &a.ResumeSize, &a.CreatedAt)
+	return a, err
+}
+
+// Applications returns the applications to one of the employer's
+// postings, newest first.
+func (as *ApplicationService) Applications(employerID, jobID int) ([]Application, error) {
+	if _, err := as.Postings.Posting(employerID, jobID); err != nil {
+		return nil, err
+	}
+
+	rows, err := as.JobStore.Query(
+		"SELECT "+applicationColumns+" FROM applications a WHERE a.job_id = ? ORDER BY a.created_at DESC, a.id DESC",
+		jobID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get applications: %w", err)
+	}
+	defer rows.Close()
+
+	var applications []Application
+	for rows.Next() {
+		a, err := scanApplication(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan application: %w", err)
+		}
+		applications = append(applications, a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating applications: %w", err)
+	}
+
+	return applications, nil
+}
+
+// Resume opens the resume of an application to one of the employer's
+// postings. The caller closes it.
+func (as *ApplicationService) Resume(ctx context.Context, employerID, applicationID int) (Application, io.ReadCloser, error) {
+	row := as.JobStore.QueryRow(
+		"SELECT "+applicationColumns+" FROM applications a JOIN jobs j ON j.id = a.job_id WHERE a.id = ? AND j.employer_id = ?",
+		applicationID,
+		employerID,
+	)
+	a, err := scanApplication(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Application{}, nil, ErrApplicationNotFound
+	}
+	if err != nil {
+		return Application{}, nil, fmt.Errorf("failed to get application %d: %w", applicationID, err)
+	}
+
+	r, err := as.Blobs.Open(ctx, a.resumeKey)
+	if errors.Is(err, blob.ErrNotFound) {
+		return Application{}, nil, ErrApplicationNotFound
+	}
+	if err != nil {
+		return Application{}, nil, fmt.Errorf("failed to open resume of application %d: %w", applicationID, err)
+	}
+
+	return a, r, nil
+}
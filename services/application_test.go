@@ -0,0 +1,156 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"htmxjb/models/domain"
+	"htmxjb/services/blob"
+	"htmxjb/services/mail"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubMailer struct {
+	sent []mail.Message
+	err  error
+}
+
+func (m *stubMailer) Send(ctx context.Context, msg mail.Message) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// docx returns a Word document with one paragraph per line.
+func docx(t *testing.T, lines ...string) []byte {
+	var body bytes.Buffer
+	for _, line := range lines {
+		body.WriteString("<w:p><w:r><w:t>" + line + "</w:t></w:r></w:p>")
+	}
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	f, err := w.Create("word/document.xml")
+	require.NoError(t, err)
+	_, err = f.Write([]byte(`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` +
+		body.String() + `</w:body></w:document>`))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestApplications(t *testing.T) {
+	ctx := context.Background()
+	store := openTestStore(t)
+	clk := &clock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	es := NewEmployerService(store, NewCompanyService(store))
+	ps := NewPostingService(store, nil)
+	ps.Now = clk.Now
+	jobs := NewJobServices(Job{}, store)
+
+	disk, err := blob.NewDisk(t.TempDir())
+	require.NoError(t, err)
+	mailer := &stubMailer{}
+	as := NewApplicationService(store, disk, mailer, ps, "https://jobs.example/")
+	as.Now = clk.Now
+	as.MaxResumeBytes = 1 << 10
+
+	ann, err := es.SignUp("ann@acme.example", "Ann", "Acme", "correct horse battery")
+	require.NoError(t, err)
+	eve, err := es.SignUp("eve@evil.example", "Eve", "Evil Corp", "correct horse battery")
+	require.NoError(t, err)
+
+	p, err := ps.CreateDraft(ann)
+	require.NoError(t, err)
+	p.Title, p.Description, p.Workplace = "Go Developer", "Write code.", domain.Remote
+	require.NoError(t, ps.Save(ann.ID, p))
+
+	pdf := Resume{Name: "Bob Smith.pdf", Data: []byte("%PDF-1.7 resume")}
+	bob := Application{Name: " Bob ", Email: "Bob@Example.com", CoverLetter: "Hire me."}
+
+	_, err = as.Apply(ctx, p.ID, bob, pdf)
+	assert.ErrorIs(t, err, ErrNotAcceptingApplications, "drafts take no applications")
+
+	require.NoError(t, ps.Publish(ann.ID, p.ID, time.Time{}, time.Time{}))
+	job, err := jobs.GetJob(p.ID)
+	require.NoError(t, err)
+	assert.True(t, job.AcceptsApplications)
+
+	t.Run("validation", func(t *testing.T) {
+		cases := []struct {
+			name   string
+			a      Application
+			resume Resume
+			err    error
+		}{
+			{"no name", Application{Email: "bob@example.com"}, pdf, ErrApplicantNameRequired},
+			{"bad email", Application{Name: "Bob", Email: "bob"}, pdf, ErrEmailInvalid},
+			{"long letter", Application{Name: "Bob", Email: "bob@example.com", CoverLetter: strings.Repeat("x", maxCoverLetterLength+1)}, pdf, ErrCoverLetterTooLong},
+			{"no resume", bob, Resume{}, ErrResumeRequired},
+			{"too large", bob, Resume{Name: "cv.pdf", Data: append([]byte("%PDF-"), make([]byte, 1<<10)...)}, ErrResumeTooLarge},
+			{"wrong type", bob, Resume{Name: "cv.exe", Data: []byte("MZ")}, ErrResumeType},
+			{"renamed", bob, Resume{Name: "cv.pdf", Data: []byte("MZ not a pdf")}, ErrResumeType},
+			{"zip not docx", bob, Resume{Name: "cv.docx", Data: []byte("PK\x03\x04")}, ErrResumeType},
+		}
+		for _, c := range cases {
+			_, err := as.Apply(ctx, p.ID, c.a, c.resume)
+			assert.ErrorIs(t, err, c.err, c.name)
+		}
+	})
+
+	first, err := as.Apply(ctx, p.ID, bob, pdf)
+	require.NoError(t, err)
+	assert.Equal(t, "Bob", first.Name)
+	assert.Equal(t, "bob@example.com", first.Email)
+	assert.Equal(t, "application/pdf", first.ResumeType)
+
+	require.Len(t, mailer.sent, 1)
+	assert.Equal(t, "ann@acme.example", mailer.sent[0].To)
+	assert.Equal(t, "New application for Go Developer", mailer.sent[0].Subject)
+	assert.Contains(t, mailer.sent[0].Body, "https://jobs.example/employer/jobs/")
+
+	clk.Advance(time.Minute)
+	mailer.err = errors.New("relay down")
+	second, err := as.Apply(ctx, p.ID, Application{Name: "Cat", Email: "cat@example.com"},
+		Resume{Name: `C:\Users\cat\"cv".docx`, Data: docx(t)})
+	require.NoError(t, err, "applications are kept when the email fails")
+	assert.Equal(t, "cv.docx", second.ResumeName)
+
+	applications, err := as.Applications(ann.ID, p.ID)
+	require.NoError(t, err)
+	require.Len(t, applications, 2)
+	assert.Equal(t, second.ID, applications[0].ID, "newest first")
+	assert.Equal(t, "Hire me.", applications[1].CoverLetter)
+
+	posting, err := ps.Posting(ann.ID, p.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, posting.Applications)
+
+	a, r, err := as.Resume(ctx, ann.ID, first.ID)
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, pdf.Data, data)
+	assert.Equal(t, "Bob Smith.pdf", a.ResumeName)
+
+	t.Run("ownership", func(t *testing.T) {
+		_, err := as.Applications(eve.ID, p.ID)
+		assert.ErrorIs(t, err, ErrJobNotFound)
+		_, _, err = as.Resume(ctx, eve.ID, first.ID)
+		assert.ErrorIs(t, err, ErrApplicationNotFound)
+	})
+
+	require.NoError(t, ps.Close(ann.ID, p.ID))
+	_, err = as.Apply(ctx, p.ID, bob, pdf)
+	assert.ErrorIs(t, err, ErrNotAcceptingApplications, "closed jobs take no applications")
+}
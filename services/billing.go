@@ -0,0 +1,405 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"htmxjb/db"
+	"htmxjb/models/domain"
+	"htmxjb/services/payments"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUnknownPlan     = errors.New("unknown plan")
+	ErrNotPromotable   = errors.New("only scheduled and live postings can be promoted")
+	ErrOrderNotFound   = errors.New("order not found")
+	ErrInvoiceNotFound = errors.New("invoice not found")
+	ErrPaymentMismatch = errors.New("payment amount does not match the order")
+	errUnknownFeature  = errors.New("unknown feature")
+)
+
+// Feature is what an employer pays for on a posting.
+type Feature string
+
+const (
+	// Pin lists the posting above unpinned ones.
+	Pin Feature = "pin"
+	// Highlight makes the posting's card stand out.
+	Highlight Feature = "highlight"
+)
+
+// column is the jobs column holding the end of the feature's window.
+func (f Feature) column() (string, error) {
+	switch f {
+	case Pin:
+		return "pinned_until", nil
+	case Highlight:
+		return "highlighted_until", nil
+	default:
+		return "", errUnknownFeature
+	}
+}
+
+// Plan is a feature for sale.
+type Plan struct {
+	Feature     Feature
+	Label       string
+	AmountCents int64
+	Currency    string
+	Duration    time.Duration
+}
+
+type OrderStatus string
+
+const (
+	OrderPending OrderStatus = "pending"
+	OrderPaid    OrderStatus = "paid"
+	OrderFailed  OrderStatus = "failed"
+)
+
+// Order is a feature bought for a posting. FeatureUntil is set once it is
+// paid.
+type Order struct {
+	ID            int64
+	JobID         int
+	JobTitle      string
+	Feature       Feature
+	Label         string
+	AmountCents   int64
+	Currency      string
+	Duration      time.Duration
+	Status        OrderStatus
+	FeatureUntil  time.Time
+	CreatedAt     time.Time
+	PaidAt        time.Time
+	InvoiceNumber string
+}
+
+// Invoice is issued once for every paid order.
+type Invoice struct {
+	Number      string
+	BilledTo    string
+	Email       string
+	Description string
+	AmountCents int64
+	Currency    string
+	IssuedAt    time.Time
+}
+
+// BillingService sells features on postings through a payment provider.
+// Orders are fulfilled by the provider's webhook events, which may arrive
+// more than once; every step of fulfilment is safe to repeat.
+type BillingService struct {
+	JobStore db.Store
+	Provider payments.Provider
+	Postings *PostingService
+	Catalog  []Plan
+	Now      func() time.Time
+}
+
+func NewBillingService(jobStore db.Store, provider payments.Provider, postings *PostingService, plans []Plan) *BillingService {
+	return &BillingService{
+		JobStore: jobStore,
+		Provider: provider,
+		Postings: postings,
+		Catalog:  plans,
+		Now:      time.Now,
+	}
+}
+
+// Plans returns the features for sale.
+func (bs *BillingService) Plans() []Plan {
+	return bs.Catalog
+}
+
+// Plan returns the plan selling feature.
+func (bs *BillingService) Plan(feature Feature) (Plan, error) {
+	for _, p := range bs.Catalog {
+		if p.Feature == feature {
+			return p, nil
+		}
+	}
+	return Plan{}, ErrUnknownPlan
+}
+
+// Checkout orders a feature for one of the employer's postings and returns
+// the provider's URL to pay it at.
+func (bs *BillingService) Checkout(ctx context.Context, employerID, jobID int, feature Feature, returnURL string) (string, error) {
+	plan, err := bs.Plan(feature)
+	if err != nil {
+		return "", err
+	}
+
+	posting, err := bs.Postings.Posting(employerID, jobID)
+	if err != nil {
+		return "", err
+	}
+	if posting.Status != domain.Active && posting.Status != domain.Scheduled {
+		return "", ErrNotPromotable
+	}
+
+	res, err := bs.JobStore.Exec(
+		"INSERT INTO orders (employer_id, job_id, feature, label, amount_cents, currency, duration_hours, status, provider, created_at)"+
+			" VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		employerID,
+		jobID,
+		plan.Feature,
+		plan.Label,
+		plan.AmountCents,
+		plan.Currency,
+		int64(plan.Duration/time.Hour),
+		OrderPending,
+		bs.Provider.Name(),
+		sqlTime(bs.Now()),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to create order: %w", err)
+	}
+
+	orderID, err := res.LastInsertId()
+	if err != nil {
+		return "", fmt.Errorf("failed to create order: %w", err)
+	}
+
+	session, err := bs.Provider.Checkout(ctx, payments.Checkout{
+		OrderID:     orderID,
+		Description: plan.Label + " for " + posting.Title,
+		AmountCents: plan.AmountCents,
+		Currency:    plan.Currency,
+		ReturnURL:   returnURL,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to start %s checkout: %w", bs.Provider.Name(), err)
+	}
+
+	if _, err := bs.JobStore.Exec("UPDATE orders SET provider_ref = ? WHERE id = ?", session.Reference, orderID); err != nil {
+		return "", fmt.Errorf("failed to save checkout of order %d: %w", orderID, err)
+	}
+
+	return session.URL, nil
+}
+
+const orderColumns = "o.id, COALESCE(o.job_id, 0), COALESCE(j.title, ''), o.feature, o.label, o.amount_cents, o.currency, o.duration_hours," +
+	" o.status, o.feature_until, o.created_at, o.paid_at, COALESCE(i.number, '')"
+
+const orderFrom = " FROM orders o LEFT JOIN jobs j ON j.id = o.job_id LEFT JOIN invoices i ON i.order_id = o.id"
+
+func scanOrder(row interface{ Scan(...interface{}) error }) (Order, error) {
+	var (
+		o                    Order
+		hours                int64
+		featureUntil, paidAt sql.NullTime
+	)
+	err := row.Scan(&o.ID, &o.JobID, &o.JobTitle, &o.Feature, &o.Label, &o.AmountCents, &o.Currency, &hours,
+		&o.Status, &featureUntil, &o.CreatedAt, &paidAt, &o.InvoiceNumber)
+	if err != nil {
+		return Order{}, err
+	}
+
+	o.Duration = time.Duration(hours) * time.Hour
+	o.FeatureUntil = featureUntil.Time
+	o.PaidAt = paidAt.Time
+
+	return o, nil
+}
+
+// Orders returns the employer's orders, newest first.
+func (bs *BillingService) Orders(employerID int) ([]Order, error) {
+	rows, err := bs.JobStore.Query("SELECT "+orderColumns+orderFrom+" WHERE o.employer_id = ? ORDER BY o.created_at DESC, o.id DESC", employerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get orders: %w", err)
+	}
+	defer rows.Close()
+
+	var orders []Order
+	for rows.Next() {
+		o, err := scanOrder(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order: %w", err)
+		}
+		orders = append(orders, o)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating orders: %w", err)
+	}
+
+	return orders, nil
+}
+
+// Invoice returns one of the employer's invoices.
+func (bs *BillingService) Invoice(employerID int, number string) (Invoice, error) {
+	var inv Invoice
+	err := bs.JobStore.QueryRow(`
+    SELECT i.number, COALESCE(c.name, e.name), e.email, i.description, i.amount_cents, i.currency, i.issued_at
+    FROM invoices i
+    JOIN employers e ON e.id = i.employer_id
+    LEFT JOIN companies c ON c.id = e.company_id
+    WHERE i.number = ? AND i.employer_id = ?`,
+		number,
+		employerID,
+	).Scan(&inv.Number, &inv.BilledTo, &inv.Email, &inv.Description, &inv.AmountCents, &inv.Currency, &inv.IssuedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Invoice{}, ErrInvoiceNotFound
+	}
+	if err != nil {
+		return Invoice{}, fmt.Errorf("failed to get invoice %s: %w", number, err)
+	}
+	return inv, nil
+}
+
+// HandleEvent applies a verified webhook event. Events seen before are
+// ignored. A successful payment opens the feature's window on the posting
+// and issues the invoice; a failed one marks the order failed.
+func (bs *BillingService) HandleEvent(event payments.Event) error {
+	provider := bs.Provider.Name()
+
+	var seen bool
+	err := bs.JobStore.QueryRow(
+		"SELECT EXISTS (SELECT 1 FROM payment_events WHERE provider = ? AND event_id = ?)",
+		provider,
+		event.ID,
+	).Scan(&seen)
+	if err != nil {
+		return fmt.Errorf("failed to check payment event %s: %w", event.ID, err)
+	}
+	if seen {
+		return nil
+	}
+
+	order, err := scanOrder(bs.JobStore.QueryRow(
+		"SELECT "+orderColumns+orderFrom+" WHERE o.provider = ? AND o.provider_ref = ?",
+		provider,
+		event.Reference,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrOrderNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get order for payment %s: %w", event.Reference, err)
+	}
+
+	switch event.Type {
+	case payments.Succeeded:
+		if event.AmountCents != order.AmountCents || !strings.EqualFold(event.Currency, order.Currency) {
+			return ErrPaymentMismatch
+		}
+		if err := bs.fulfil(order); err != nil {
+			return err
+		}
+	case payments.Failed:
+		_, err := bs.JobStore.Exec("UPDATE orders SET status = ? WHERE id = ? AND status = ?", OrderFailed, order.ID, OrderPending)
+		if err != nil {
+			return fmt.Errorf("failed to mark order %d failed: %w", order.ID, err)
+		}
+	}
+
+	// Recorded last, so an event that failed halfway is applied again when
+	// the provider retries it.
+	_, err = bs.JobStore.Exec(
+		"INSERT OR IGNORE INTO payment_events (provider, event_id, order_id, type, received_at) VALUES (?, ?, ?, ?, ?)",
+		provider,
+		event.ID,
+		order.ID,
+		event.Type,
+		sqlTime(bs.Now()),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record payment event %s: %w", event.ID, err)
+	}
+
+	return nil
+}
+
+// fulfil marks the order paid, extends the posting's feature window and
+// issues the invoice. The window is fixed when the order is first marked
+// paid, so repeating fulfil changes nothing.
+func (bs *BillingService) fulfil(order Order) error {
+	column, err := order.Feature.column()
+	if err != nil {
+		return err
+	}
+	now := bs.Now()
+
+	// The order, the job's feature and the invoice change together or not
+	// at all, so a retried webhook finds the order as it was.
+	return bs.JobStore.WithTx(func(tx *sql.Tx) error {
+		if order.Status != OrderPaid {
+			until, err := windowEnd(tx, order, column, now)
+			if err != nil {
+				return err
+			}
+
+			// A late success still counts after a failure was reported.
+			res, err := tx.Exec(
+				"UPDATE orders SET status = ?, paid_at = ?, feature_until = ? WHERE id = ? AND status != ?",
+				OrderPaid,
+				sqlTime(now),
+				sqlTime(until),
+				order.ID,
+				OrderPaid,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to mark order %d paid: %w", order.ID, err)
+			}
+			if n, err := res.RowsAffected(); err == nil && n == 0 {
+				return fmt.Errorf("order %d was paid concurrently", order.ID)
+			}
+			order.FeatureUntil, order.PaidAt = until, now
+		}
+
+		if order.JobID != 0 {
+			_, err := tx.Exec(
+				"UPDATE jobs SET "+column+" = MAX(COALESCE("+column+", ''), ?) WHERE id = ?",
+				sqlTime(order.FeatureUntil),
+				order.JobID,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to apply order %d to job %d: %w", order.ID, order.JobID, err)
+			}
+		}
+
+		description := order.Label
+		if order.JobTitle != "" {
+			description += " for " + order.JobTitle
+		}
+		description += " until " + order.FeatureUntil.UTC().Format("2 Jan 2006 15:04 MST")
+
+		_, err := tx.Exec(
+			"INSERT OR IGNORE INTO invoices (number, order_id, employer_id, description, amount_cents, currency, issued_at)"+
+				" SELECT ?, id, employer_id, ?, amount_cents, currency, ? FROM orders WHERE id = ?",
+			fmt.Sprintf("INV-%d-%06d", order.PaidAt.Year(), order.ID),
+			description,
+			sqlTime(order.PaidAt),
+			order.ID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to issue invoice for order %d: %w", order.ID, err)
+		}
+
+		return nil
+	})
+}
+
+// windowEnd is when a feature bought now ends. The window starts when the
+// posting goes live or when the feature it already has runs out, whichever
+// is later.
+func windowEnd(tx *sql.Tx, order Order, column string, now time.Time) (time.Time, error) {
+	var publishAt, until sql.NullTime
+	err := tx.QueryRow("SELECT publish_at, "+column+" FROM jobs WHERE id = ?", order.JobID).Scan(&publishAt, &until)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, fmt.Errorf("failed to get feature window of job %d: %w", order.JobID, err)
+	}
+
+	start := now
+	for _, t := range []time.Time{publishAt.Time, until.Time} {
+		if t.After(start) {
+			start = t
+		}
+	}
+
+	return start.Add(order.Duration), nil
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"htmxjb/models/domain"
+	"htmxjb/services/payments"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBilling(t *testing.T) {
+	store := openTestStore(t)
+	clk := &clock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	es := NewEmployerService(store, NewCompanyService(store))
+	ps := NewPostingService(store, nil)
+	ps.Now = clk.Now
+	jobs := NewJobServices(Job{}, store)
+	jobs.Now = clk.Now
+
+	fake := payments.NewFake("secret")
+	bs := NewBillingService(store, fake, ps, []Plan{
+		{Feature: Pin, Label: "Pinned listing", AmountCents: 9900, Currency: "USD", Duration: 7 * 24 * time.Hour},
+		{Feature: Highlight, Label: "Highlighted listing", AmountCents: 4900, Currency: "USD", Duration: 14 * 24 * time.Hour},
+	})
+	bs.Now = clk.Now
+
+	ann, err := es.SignUp("ann@acme.example", "Ann", "Acme", "correct horse battery")
+	require.NoError(t, err)
+	eve, err := es.SignUp("eve@evil.example", "Eve", "Evil Corp", "correct horse battery")
+	require.NoError(t, err)
+
+	publish := func(title string) Posting {
+		p, err := ps.CreateDraft(ann)
+		require.NoError(t, err)
+		p.Title, p.Description, p.Workplace = title, "Write code.", domain.Remote
+		require.NoError(t, ps.Save(ann.ID, p))
+		require.NoError(t, ps.Publish(ann.ID, p.ID, time.Time{}, time.Time{}))
+		clk.Advance(time.Minute)
+		return p
+	}
+	older := publish("Older job")
+	newer := publish("Newer job")
+
+	// pay checks out and completes a payment the way the provider would.
+	pay := func(employerID, jobID int, feature Feature, paid bool) payments.Event {
+		url, err := bs.Checkout(context.Background(), employerID, jobID, feature, "/employer/billing")
+		require.NoError(t, err)
+
+		payload, header, err := fake.Complete(url[len("/payments/fake/"):], paid)
+		require.NoError(t, err)
+		event, err := fake.ParseEvent(payload, header)
+		require.NoError(t, err)
+
+		require.NoError(t, bs.HandleEvent(event))
+		return event
+	}
+
+	t.Run("checkout", func(t *testing.T) {
+		_, err := bs.Checkout(context.Background(), eve.ID, older.ID, Pin, "")
+		assert.ErrorIs(t, err, ErrJobNotFound)
+		_, err = bs.Checkout(context.Background(), ann.ID, older.ID, "gold", "")
+		assert.ErrorIs(t, err, ErrUnknownPlan)
+
+		draft, err := ps.CreateDraft(ann)
+		require.NoError(t, err)
+		_, err = bs.Checkout(context.Background(), ann.ID, draft.ID, Pin, "")
+		assert.ErrorIs(t, err, ErrNotPromotable)
+	})
+
+	t.Run("failed payment", func(t *testing.T) {
+		pay(ann.ID, older.ID, Pin, false)
+
+		orders, err := bs.Orders(ann.ID)
+		require.NoError(t, err)
+		require.Len(t, orders, 1)
+		assert.Equal(t, OrderFailed, orders[0].Status)
+		assert.Empty(t, orders[0].InvoiceNumber)
+
+		listed, err := jobs.GetAllJobs()
+		require.NoError(t, err)
+		assert.Equal(t, "Newer job", listed[0].Title)
+	})
+
+	t.Run("pinned", func(t *testing.T) {
+		event := pay(ann.ID, older.ID, Pin, true)
+
+		listed, err := jobs.GetAllJobs()
+		require.NoError(t, err)
+		assert.Equal(t, "Older job", listed[0].Title, "pinned jobs list first")
+		assert.True(t, listed[0].Pinned)
+		assert.False(t, listed[0].Highlighted)
+
+		posting, err := ps.Posting(ann.ID, older.ID)
+		require.NoError(t, err)
+		assert.Equal(t, clk.Now().Add(7*24*time.Hour), posting.PinnedUntil.UTC())
+
+		// Redelivered events change nothing.
+		require.NoError(t, bs.HandleEvent(event))
+		again, err := ps.Posting(ann.ID, older.ID)
+		require.NoError(t, err)
+		assert.Equal(t, posting.PinnedUntil, again.PinnedUntil)
+
+		orders, err := bs.Orders(ann.ID)
+		require.NoError(t, err)
+		require.Len(t, orders, 2)
+		assert.Equal(t, OrderPaid, orders[0].Status)
+		assert.Equal(t, "Older job", orders[0].JobTitle)
+		assert.Equal(t, "INV-2024-000002", orders[0].InvoiceNumber)
+
+		invoice, err := bs.Invoice(ann.ID, orders[0].InvoiceNumber)
+		require.NoError(t, err)
+		assert.Equal(t, "Acme", invoice.BilledTo)
+		assert.Equal(t, int64(9900), invoice.AmountCents)
+		assert.Contains(t, invoice.Description, "Pinned listing for Older job until 8 May 2024")
+
+		_, err = bs.Invoice(eve.ID, orders[0].InvoiceNumber)
+		assert.ErrorIs(t, err, ErrInvoiceNotFound)
+
+		// Buying again extends the window from where it ends.
+		pay(ann.ID, older.ID, Pin, true)
+		extended, err := ps.Posting(ann.ID, older.ID)
+		require.NoError(t, err)
+		assert.Equal(t, posting.PinnedUntil.Add(7*24*time.Hour), extended.PinnedUntil)
+
+		clk.Advance(15 * 24 * time.Hour)
+		listed, err = jobs.GetAllJobs()
+		require.NoError(t, err)
+		assert.Equal(t, "Newer job", listed[0].Title, "the pin ran out")
+		assert.False(t, listed[1].Pinned)
+	})
+
+	t.Run("mismatched payment", func(t *testing.T) {
+		url, err := bs.Checkout(context.Background(), ann.ID, newer.ID, Highlight, "")
+		require.NoError(t, err)
+
+		event := payments.Event{ID: "evt_cheap", Type: payments.Succeeded, Reference: url[len("/payments/fake/"):], AmountCents: 1, Currency: "USD"}
+		assert.ErrorIs(t, bs.HandleEvent(event), ErrPaymentMismatch)
+
+		event.AmountCents = 4900
+		require.NoError(t, bs.HandleEvent(event))
+		listed, err := jobs.GetAllJobs()
+		require.NoError(t, err)
+		assert.True(t, listed[0].Highlighted)
+
+		event.ID, event.Reference = "evt_unknown", "fake_unknown"
+		assert.ErrorIs(t, bs.HandleEvent(event), ErrOrderNotFound)
+	})
+}
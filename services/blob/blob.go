@@ -0,0 +1,22 @@
+// Package blob stores uploaded files, e.g. resumes, outside the database.
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var (
+	ErrNotFound   = errors.New("blob not found")
+	ErrInvalidKey = errors.New("invalid blob key")
+)
+
+// Store keeps blobs under slash separated keys such as
+// "resumes/12/3f9a.pdf". Keys are chosen by the caller and never come
+// from user input.
+type Store interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,94 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Disk keeps blobs as files below a directory.
+type Disk struct {
+	Dir string
+}
+
+func NewDisk(dir string) (*Disk, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create blob directory: %w", err)
+	}
+	return &Disk{Dir: dir}, nil
+}
+
+// Put writes to a temporary file first, so a failed upload never leaves a
+// partial blob under key.
+func (d *Disk) Put(ctx context.Context, key string, r io.Reader) error {
+	name, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o750); err != nil {
+		return fmt.Errorf("failed to create blob directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create blob %s: %w", key, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write blob %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write blob %s: %w", key, err)
+	}
+
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return fmt.Errorf("failed to store blob %s: %w", key, err)
+	}
+	return nil
+}
+
+func (d *Disk) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	name, err := d.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open blob %s: %w", key, err)
+	}
+	return f, nil
+}
+
+// Delete removes the blob. Deleting a missing blob is not an error.
+func (d *Disk) Delete(ctx context.Context, key string) error {
+	name, err := d.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete blob %s: %w", key, err)
+	}
+	return nil
+}
+
+// path maps key to a file below Dir, refusing keys that would leave it.
+func (d *Disk) path(key string) (string, error) {
+	if key == "" || strings.Contains(key, `\`) || path.Clean(key) != key ||
+		path.IsAbs(key) || key == ".." || strings.HasPrefix(key, "../") {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(d.Dir, filepath.FromSlash(key)), nil
+}
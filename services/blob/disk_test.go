@@ -0,0 +1,41 @@
+package blob
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisk(t *testing.T) {
+	ctx := context.Background()
+	disk, err := NewDisk(filepath.Join(t.TempDir(), "uploads"))
+	require.NoError(t, err)
+
+	require.NoError(t, disk.Put(ctx, "resumes/1/a.pdf", strings.NewReader("%PDF-1.7")))
+
+	r, err := disk.Open(ctx, "resumes/1/a.pdf")
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, "%PDF-1.7", string(data))
+
+	entries, err := os.ReadDir(filepath.Join(disk.Dir, "resumes", "1"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary files are left behind")
+
+	require.NoError(t, disk.Delete(ctx, "resumes/1/a.pdf"))
+	_, err = disk.Open(ctx, "resumes/1/a.pdf")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, disk.Delete(ctx, "resumes/1/a.pdf"), "deleting twice is fine")
+
+	for _, key := range []string{"", "../escape", "/etc/passwd", "a/../../b", "a//b", `a\b`, ".."} {
+		assert.ErrorIs(t, disk.Put(ctx, key, strings.NewReader("x")), ErrInvalidKey, key)
+	}
+}
@@ -0,0 +1,52 @@
+// Package companies normalizes employer names so the spellings sources
+// use for one company link to a single profile.
+package companies
+
+import (
+	"strings"
+	"unicode"
+)
+
+// legalSuffixes are dropped from the end of a name: "Acme, Inc." and
+// "ACME GmbH" both normalize to "acme".
+var legalSuffixes = map[string]bool{
+	"inc": true, "incorporated": true, "llc": true, "llp": true, "ltd": true, "limited": true,
+	"corp": true, "corporation": true, "co": true, "company": true, "plc": true,
+	"gmbh": true, "ag": true, "sa": true, "sas": true, "sarl": true, "bv": true, "nv": true,
+	"oy": true, "ab": true, "as": true, "srl": true, "spa": true, "pty": true, "kg": true,
+}
+
+// Normalize folds case, drops punctuation and legal suffixes and collapses
+// spaces. It returns "" for a name with nothing left.
+func Normalize(name string) string {
+	words := split(name)
+	for len(words) > 1 && legalSuffixes[words[len(words)-1]] {
+		words = words[:len(words)-1]
+	}
+	return strings.Join(words, " ")
+}
+
+// Slug is the URL form of name, e.g. "Acme Cloud, Inc." becomes
+// "acme-cloud-inc".
+func Slug(name string) string {
+	return strings.Join(split(name), "-")
+}
+
+// split lower-cases name and returns its words. "&" is kept as "and", so
+// "AT&T" matches "AT and T" but not "ATT".
+func split(name string) []string {
+	name = strings.ReplaceAll(name, "&", " and ")
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r == '\'' || r == '’' || r == '.':
+			// "O'Reilly" and "S.A." stay one word.
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Fields(b.String())
+}
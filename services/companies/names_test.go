@@ -0,0 +1,29 @@
+package companies
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	for name, want := range map[string]string{
+		"Acme":                "acme",
+		"ACME, Inc.":          "acme",
+		"  Acme   Cloud LLC ": "acme cloud",
+		"Acme Co. Ltd":        "acme",
+		"O'Reilly Media":      "oreilly media",
+		"AT&T":                "at and t",
+		"Zürich Versicherung": "zürich versicherung",
+		"Company":             "company",
+		"!!!":                 "",
+	} {
+		assert.Equal(t, want, Normalize(name), name)
+	}
+}
+
+func TestSlug(t *testing.T) {
+	assert.Equal(t, "acme-cloud-inc", Slug("Acme Cloud, Inc."))
+	assert.Equal(t, "s-a-and-co", Slug("S-A & Co"))
+	assert.Equal(t, "", Slug("  "))
+}
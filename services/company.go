@@ -0,0 +1,364 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"htmxjb/db"
+	"htmxjb/models/domain"
+	"htmxjb/services/companies"
+	"htmxjb/services/sanitize"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// companyColumns add the linked company's name and slug to a job row.
+const companyColumns = "(SELECT name FROM companies WHERE id = jobs.company_id) AS company," +
+	" (SELECT slug FROM companies WHERE id = jobs.company_id) AS company_slug"
+
+var (
+	ErrCompanyNotFound     = errors.New("company not found")
+	ErrCompanyNameRequired = errors.New("a company name is required")
+	ErrNothingToMerge      = errors.New("choose a company to keep and at least one other")
+	ErrCompanyAliasUsed    = errors.New("that name already belongs to another company")
+	ErrCompanyURL          = errors.New("website and logo must be http or https URLs")
+)
+
+// Company is an employer profile. Jobs link to it by any of its aliases,
+// the normalized names sources use for it.
+type Company struct {
+	ID          int      `json:"id"`
+	Slug        string   `json:"slug"`
+	Name        string   `json:"name"`
+	Website     string   `json:"website,omitempty"`
+	Description string   `json:"description,omitempty"`
+	LogoURL     string   `json:"logo_url,omitempty"`
+	Aliases     []string `json:"aliases,omitempty"`
+	OpenJobs    int      `json:"open_jobs"`
+}
+
+// CompanyService links ingested jobs to company profiles and maintains
+// them.
+type CompanyService struct {
+	JobStore db.Store
+}
+
+func NewCompanyService(jobStore db.Store) *CompanyService {
+	return &CompanyService{
+		JobStore: jobStore,
+	}
+}
+
+// Link sets job.CompanyID to the company job.Company names, creating the
+// company the first time it is seen. Website and logo fill in blanks on an
+// existing company but never replace what is there.
+func (cs *CompanyService) Link(job *domain.Job) error {
+	name := strings.TrimSpace(job.Company)
+	normalized := companies.Normalize(name)
+	if normalized == "" {
+		job.CompanyID = 0
+		return nil
+	}
+
+	var id int64
+	err := cs.JobStore.QueryRow(
+		"SELECT company_id FROM company_aliases WHERE normalized_name = ?",
+		normalized,
+	).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		id, err = cs.create(name, normalized, webURL(job.CompanyWebsite), webURL(job.CompanyLogo))
+		if err != nil {
+			return err
+		}
+		job.CompanyID = id
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find company %q: %w", name, err)
+	}
+
+	website, logo := webURL(job.CompanyWebsite), webURL(job.CompanyLogo)
+	if website != "" || logo != "" {
+		_, err = cs.JobStore.Exec(
+			"UPDATE companies SET website = COALESCE(website, ?), logo_url = COALESCE(logo_url, ?) WHERE id = ?",
+			nullString(website),
+			nullString(logo),
+			id,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to update company %q: %w", name, err)
+		}
+	}
+
+	job.CompanyID = id
+	return nil
+}
+
+func (cs *CompanyService) create(name, normalized, website, logo string) (int64, error) {
+	slug, err := cs.freeSlug(companies.Slug(name))
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := cs.JobStore.Exec(
+		"INSERT INTO companies (slug, name, website, logo_url) VALUES (?, ?, ?, ?)",
+		slug,
+		name,
+		nullString(website),
+		nullString(logo),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create company %q: %w", name, err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to create company %q: %w", name, err)
+	}
+
+	_, err = cs.JobStore.Exec(
+		"INSERT INTO company_aliases (normalized_name, name, company_id) VALUES (?, ?, ?)",
+		normalized,
+		name,
+		id,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create company %q: %w", name, err)
+	}
+
+	return id, nil
+}
+
+// freeSlug returns slug, or slug with the first free numeric suffix.
+func (cs *CompanyService) freeSlug(slug string) (string, error) {
+	if slug == "" {
+		slug = "company"
+	}
+
+	candidate := slug
+	for n := 2; ; n++ {
+		var taken bool
+		err := cs.JobStore.QueryRow("SELECT EXISTS (SELECT 1 FROM companies WHERE slug = ?)", candidate).Scan(&taken)
+		if err != nil {
+			return "", fmt.Errorf("failed to check company slug: %w", err)
+		}
+		if !taken {
+			return candidate, nil
+		}
+		candidate = slug + "-" + strconv.Itoa(n)
+	}
+}
+
+const companySelect = "SELECT c.id, c.slug, c.name, c.website, c.description, c.logo_url," +
+	" (SELECT GROUP_CONCAT(a.name, '|') FROM company_aliases a WHERE a.company_id = c.id)," +
+	" (SELECT COUNT(*) FROM jobs j WHERE j.company_id = c.id AND j.status = ?)" +
+	" FROM companies c"
+
+func scanCompany(row interface{ Scan(...interface{}) error }) (Company, error) {
+	var (
+		c                             Company
+		website, description, logoURL sql.NullString
+		aliases                       sql.NullString
+	)
+	if err := row.Scan(&c.ID, &c.Slug, &c.Name, &website, &description, &logoURL, &aliases, &c.OpenJobs); err != nil {
+		return Company{}, err
+	}
+
+	c.Website = website.String
+	c.Description = description.String
+	c.LogoURL = logoURL.String
+	if aliases.String != "" {
+		c.Aliases = strings.Split(aliases.String, "|")
+	}
+
+	return c, nil
+}
+
+// Companies returns every company by name, so duplicates sit together.
+func (cs *CompanyService) Companies() ([]Company, error) {
+	rows, err := cs.JobStore.Query(companySelect+" ORDER BY c.name COLLATE NOCASE, c.id", domain.Active)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get companies: %w", err)
+	}
+	defer rows.Close()
+
+	var list []Company
+	for rows.Next() {
+		c, err := scanCompany(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan company: %w", err)
+		}
+		list = append(list, c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating companies: %w", err)
+	}
+
+	return list, nil
+}
+
+func (cs *CompanyService) Company(id int) (Company, error) {
+	c, err := scanCompany(cs.JobStore.QueryRow(companySelect+" WHERE c.id = ?", domain.Active, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Company{}, ErrCompanyNotFound
+	}
+	if err != nil {
+		return Company{}, fmt.Errorf("failed to get company %d: %w", id, err)
+	}
+	return c, nil
+}
+
+func (cs *CompanyService) CompanyBySlug(slug string) (Company, error) {
+	c, err := scanCompany(cs.JobStore.QueryRow(companySelect+" WHERE c.slug = ?", domain.Active, slug))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Company{}, ErrCompanyNotFound
+	}
+	if err != nil {
+		return Company{}, fmt.Errorf("failed to get company %q: %w", slug, err)
+	}
+	return c, nil
+}
+
+// OpenJobs returns the company's open jobs, newest first.
+func (cs *CompanyService) OpenJobs(companyID int) ([]Job, error) {
+	rows, err := cs.JobStore.Query(
+		"SELECT "+jobColumns+" FROM jobs WHERE company_id = ? AND status = ? ORDER BY created_at DESC, id DESC",
+		companyID,
+		domain.Active,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get company jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []Job
+	for rows.Next() {
+		job, _, err := scanJob(rows, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		job.Excerpt = sanitize.Excerpt(job.Description, excerptLength)
+		jobs = append(jobs, job)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating company jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// Update edits a company's profile. The slug stays, so links keep
+// working; the new name also becomes an alias.
+func (cs *CompanyService) Update(id int, name, website, description, logoURL string) error {
+	name = strings.TrimSpace(name)
+	normalized := companies.Normalize(name)
+	if normalized == "" {
+		return ErrCompanyNameRequired
+	}
+
+	website, logoURL = strings.TrimSpace(website), strings.TrimSpace(logoURL)
+	if webURL(website) != website || webURL(logoURL) != logoURL {
+		return ErrCompanyURL
+	}
+
+	var owner int
+	err := cs.JobStore.QueryRow("SELECT company_id FROM company_aliases WHERE normalized_name = ?", normalized).Scan(&owner)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		owner = id
+	case err != nil:
+		return fmt.Errorf("failed to update company %d: %w", id, err)
+	case owner != id:
+		return ErrCompanyAliasUsed
+	}
+
+	res, err := cs.JobStore.Exec(
+		"UPDATE companies SET name = ?, website = ?, description = ?, logo_url = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
+		name,
+		nullString(website),
+		nullString(strings.TrimSpace(description)),
+		nullString(logoURL),
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update company %d: %w", id, err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to update company %d: %w", id, err)
+	} else if n == 0 {
+		return ErrCompanyNotFound
+	}
+
+	_, err = cs.JobStore.Exec(
+		"INSERT INTO company_aliases (normalized_name, name, company_id) VALUES (?, ?, ?) ON CONFLICT (normalized_name) DO NOTHING",
+		normalized,
+		name,
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update company %d: %w", id, err)
+	}
+
+	return nil
+}
+
+// Merge folds duplicates into the company keep: their jobs and aliases
+// move over, blanks in keep's profile are filled from them and they are
+// deleted. Each step leaves a consistent state, so a failed merge can
+// simply be run again.
+func (cs *CompanyService) Merge(keep int, duplicates ...int) error {
+	var dups []interface{}
+	for _, id := range duplicates {
+		if id != keep {
+			dups = append(dups, id)
+		}
+	}
+	if keep == 0 || len(dups) == 0 {
+		return ErrNothingToMerge
+	}
+	if _, err := cs.Company(keep); err != nil {
+		return err
+	}
+
+	in := " IN (?" + strings.Repeat(", ?", len(dups)-1) + ")"
+	moved := append([]interface{}{keep}, dups...)
+	filled := append(append(append(append([]interface{}{}, dups...), dups...), dups...), keep)
+
+	steps := []struct {
+		stmt string
+		args []interface{}
+	}{
+		{"UPDATE jobs SET company_id = ? WHERE company_id" + in, moved},
+		{"UPDATE company_aliases SET company_id = ? WHERE company_id" + in, moved},
+		{"UPDATE employers SET company_id = ? WHERE company_id" + in, moved},
+		{"UPDATE companies SET" +
+			" website = COALESCE(website, (SELECT website FROM companies d WHERE d.id" + in + " AND d.website IS NOT NULL LIMIT 1))," +
+			" description = COALESCE(description, (SELECT description FROM companies d WHERE d.id" + in + " AND d.description IS NOT NULL LIMIT 1))," +
+			" logo_url = COALESCE(logo_url, (SELECT logo_url FROM companies d WHERE d.id" + in + " AND d.logo_url IS NOT NULL LIMIT 1))," +
+			" updated_at = CURRENT_TIMESTAMP WHERE id = ?", filled},
+		{"DELETE FROM companies WHERE id" + in, dups},
+	}
+	return cs.JobStore.WithTx(func(tx *sql.Tx) error {
+		for _, step := range steps {
+			if _, err := tx.Exec(step.stmt, step.args...); err != nil {
+				return fmt.Errorf("failed to merge companies into %d: %w", keep, err)
+			}
+		}
+		return nil
+	})
+}
+
+// webURL returns raw if it is an absolute http or https URL and "" if
+// not, so a scraped "javascript:" link never reaches a page.
+func webURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ""
+	}
+	return raw
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"htmxjb/models/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompanies(t *testing.T) {
+	store := openTestStore(t)
+	retention := NewRetentionService(store, RetentionPolicy{})
+	cs := NewCompanyService(store)
+
+	fetcher := &stubFetcher{
+		source: domain.CareerPage,
+		jobs: []domain.Job{
+			{ExternalID: "a", Title: "Go Developer", Company: "Acme, Inc.", CompanyWebsite: "https://acme.example"},
+			{ExternalID: "b", Title: "SRE", Company: "ACME", CompanyLogo: "https://acme.example/logo.png"},
+			{ExternalID: "c", Title: "Designer", Company: "Acme Cloud GmbH", CompanyWebsite: "javascript:alert(1)"},
+			{ExternalID: "d", Title: "Analyst", Company: "Acme Labs"},
+			{ExternalID: "e", Title: "Intern"},
+		},
+	}
+	jobs := NewJobServices(Job{}, store)
+	ingestor := NewIngestor(jobs, retention, nil, fetcher).WithCompanies(cs)
+	require.NoError(t, ingestor.RunOnce(context.Background()))
+
+	list, err := cs.Companies()
+	require.NoError(t, err)
+	require.Len(t, list, 3)
+
+	acme, err := cs.CompanyBySlug("acme-inc")
+	require.NoError(t, err)
+	assert.Equal(t, "Acme, Inc.", acme.Name)
+	assert.Equal(t, "https://acme.example", acme.Website)
+	assert.Equal(t, "https://acme.example/logo.png", acme.LogoURL)
+	assert.Equal(t, 2, acme.OpenJobs)
+
+	open, err := cs.OpenJobs(acme.ID)
+	require.NoError(t, err)
+	require.Len(t, open, 2)
+	assert.Equal(t, "Acme, Inc.", open[0].Company)
+	assert.Equal(t, "acme-inc", open[0].CompanySlug)
+
+	_, err = cs.CompanyBySlug("nope")
+	assert.ErrorIs(t, err, ErrCompanyNotFound)
+
+	t.Run("update", func(t *testing.T) {
+		cloud, err := cs.CompanyBySlug("acme-cloud-gmbh")
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, cs.Update(cloud.ID, " ", "", "", ""), ErrCompanyNameRequired)
+		assert.ErrorIs(t, cs.Update(cloud.ID, "Acme Inc", "", "", ""), ErrCompanyAliasUsed)
+		assert.ErrorIs(t, cs.Update(404, "Nobody", "", "", ""), ErrCompanyNotFound)
+		assert.ErrorIs(t, cs.Update(cloud.ID, "Acme Cloud", "javascript:alert(1)", "", ""), ErrCompanyURL)
+		assert.Empty(t, cloud.Website)
+
+		require.NoError(t, cs.Update(cloud.ID, "Acme Cloud Platform", "https://cloud.example", "Hosting.", ""))
+		cloud, err = cs.Company(cloud.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Acme Cloud Platform", cloud.Name)
+		assert.Equal(t, "acme-cloud-gmbh", cloud.Slug)
+		assert.ElementsMatch(t, []string{"Acme Cloud GmbH", "Acme Cloud Platform"}, cloud.Aliases)
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		cloud, err := cs.CompanyBySlug("acme-cloud-gmbh")
+		require.NoError(t, err)
+		labs, err := cs.CompanyBySlug("acme-labs")
+		require.NoError(t, err)
+
+		employers := NewEmployerService(store, cs)
+		ann, err := employers.SignUp("ann@acme.example", "Ann", "Acme Labs", "correct horse battery")
+		require.NoError(t, err)
+		require.Equal(t, labs.ID, ann.CompanyID)
+
+		assert.ErrorIs(t, cs.Merge(acme.ID), ErrNothingToMerge)
+		assert.ErrorIs(t, cs.Merge(acme.ID, acme.ID), ErrNothingToMerge)
+		require.NoError(t, cs.Merge(acme.ID, cloud.ID, labs.ID))
+
+		merged, err := cs.Company(acme.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 4, merged.OpenJobs)
+		assert.Equal(t, "https://acme.example", merged.Website)
+		assert.Equal(t, "Hosting.", merged.Description)
+		assert.Len(t, merged.Aliases, 4)
+
+		_, err = cs.Company(cloud.ID)
+		assert.ErrorIs(t, err, ErrCompanyNotFound)
+
+		// Employers of a merged company now post for the one kept.
+		ann, err = employers.LogIn("ann@acme.example", "correct horse battery")
+		require.NoError(t, err)
+		assert.Equal(t, acme.ID, ann.CompanyID)
+
+		// The merged names keep linking to the company that was kept.
+		require.NoError(t, ingestor.RunOnce(context.Background()))
+		list, err := cs.Companies()
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		assert.Equal(t, 4, list[0].OpenJobs)
+	})
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"htmxjb/db"
+	"htmxjb/models/domain"
+	"net/mail"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// minPasswordLength is the shortest password an employer may choose.
+const minPasswordLength = 10
+
+var (
+	ErrEmailInvalid         = errors.New("a valid email address is required")
+	ErrEmailTaken           = errors.New("an account with that email already exists")
+	ErrPasswordTooShort     = fmt.Errorf("passwords need at least %d characters", minPasswordLength)
+	ErrEmployerNameRequired = errors.New("your name and company are required")
+	ErrInvalidLogin         = errors.New("wrong email or password")
+	ErrSessionNotFound      = errors.New("session not found or expired")
+	errEmployerNotFound     = errors.New("employer not found")
+)
+
+// dummyHash is compared against when an email is unknown, so those logins
+// take as long as wrong passwords.
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("not a real password"), bcrypt.DefaultCost)
+	return hash
+})
+
+// Employer is an account that posts jobs for its company.
+type Employer struct {
+	ID          int
+	Email       string
+	Name        string
+	CompanyID   int
+	Company     string
+	CompanySlug string
+}
+
+// EmployerService signs employers up and in. Sessions are random tokens
+// kept in a cookie; only their hashes are stored.
+type EmployerService struct {
+	JobStore   db.Store
+	Companies  *CompanyService
+	SessionTTL time.Duration
+	Now        func() time.Time
+}
+
+func NewEmployerService(jobStore db.Store, companies *CompanyService) *EmployerService {
+	return &EmployerService{
+		JobStore:   jobStore,
+		Companies:  companies,
+		SessionTTL: 30 * 24 * time.Hour,
+		Now:        time.Now,
+	}
+}
+
+// SignUp creates an employer account, linking it to the company profile
+// the company name resolves to.
+func (es *EmployerService) SignUp(email, name, company, password string) (Employer, error) {
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return Employer{}, err
+	}
+	name, company = strings.TrimSpace(name), strings.TrimSpace(company)
+	if name == "" || company == "" {
+		return Employer{}, ErrEmployerNameRequired
+	}
+	if len([]rune(password)) < minPasswordLength {
+		return Employer{}, ErrPasswordTooShort
+	}
+
+	var taken bool
+	if err := es.JobStore.QueryRow("SELECT EXISTS (SELECT 1 FROM employers WHERE email = ?)", email).Scan(&taken); err != nil {
+		return Employer{}, fmt.Errorf("failed to check employer email: %w", err)
+	}
+	if taken {
+		return Employer{}, ErrEmailTaken
+	}
+
+	link := domain.Job{Company: company}
+	if err := es.Companies.Link(&link); err != nil {
+		return Employer{}, err
+	}
+	if link.CompanyID == 0 {
+		return Employer{}, ErrEmployerNameRequired
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return Employer{}, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	res, err := es.JobStore.Exec(
+		"INSERT INTO employers (email, name, password_hash, company_id) VALUES (?, ?, ?, ?)",
+		email,
+		name,
+		string(hash),
+		link.CompanyID,
+	)
+	if err != nil {
+		return Employer{}, fmt.Errorf("failed to create employer: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return Employer{}, fmt.Errorf("failed to create employer: %w", err)
+	}
+
+	return es.employer("e.id = ?", id)
+}
+
+// LogIn checks an employer's email and password.
+func (es *EmployerService) LogIn(email, password string) (Employer, error) {
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return Employer{}, ErrInvalidLogin
+	}
+
+	var (
+		id   int
+		hash string
+	)
+	err = es.JobStore.QueryRow("SELECT id, password_hash FROM employers WHERE email = ?", email).Scan(&id, &hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		bcrypt.CompareHashAndPassword(dummyHash(), []byte(password))
+		return Employer{}, ErrInvalidLogin
+	}
+	if err != nil {
+		return Employer{}, fmt.Errorf("failed to find employer: %w", err)
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil {
+		return Employer{}, ErrInvalidLogin
+	}
+
+	return es.employer("e.id = ?", id)
+}
+
+// StartSession returns a new session token for the employer and when it
+// expires.
+func (es *EmployerService) StartSession(employerID int) (string, time.Time, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to create session: %w", err)
+	}
+	token := hex.EncodeToString(raw)
+	expires := es.Now().Add(es.SessionTTL)
+
+	_, err := es.JobStore.Exec(
+		"INSERT INTO employer_sessions (token_hash, employer_id, expires_at) VALUES (?, ?, ?)",
+		hashToken(token),
+		employerID,
+		sqlTime(expires),
+	)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return token, expires, nil
+}
+
+// SessionEmployer returns the employer signed in with token.
+func (es *EmployerService) SessionEmployer(token string) (Employer, error) {
+	if token == "" {
+		return Employer{}, ErrSessionNotFound
+	}
+
+	e, err := es.employer(
+		"e.id = (SELECT employer_id FROM employer_sessions WHERE token_hash = ? AND expires_at > ?)",
+		hashToken(token),
+		sqlTime(es.Now()),
+	)
+	if errors.Is(err, errEmployerNotFound) {
+		return Employer{}, ErrSessionNotFound
+	}
+	return e, err
+}
+
+// EndSession signs the token out. Expired sessions are cleared as well.
+func (es *EmployerService) EndSession(token string) error {
+	_, err := es.JobStore.Exec(
+		"DELETE FROM employer_sessions WHERE token_hash = ? OR expires_at <= ?",
+		hashToken(token),
+		sqlTime(es.Now()),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to end session: %w", err)
+	}
+	return nil
+}
+
+func (es *EmployerService) employer(where string, args ...interface{}) (Employer, error) {
+	var (
+		e             Employer
+		companyID     sql.NullInt64
+		company, slug sql.NullString
+	)
+	err := es.JobStore.QueryRow(
+		"SELECT e.id, e.email, e.name, e.company_id, c.name, c.slug"+
+			" FROM employers e LEFT JOIN companies c ON c.id = e.company_id WHERE "+where,
+		args...,
+	).Scan(&e.ID, &e.Email, &e.Name, &companyID, &company, &slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Employer{}, errEmployerNotFound
+	}
+	if err != nil {
+		return Employer{}, fmt.Errorf("failed to get employer: %w", err)
+	}
+
+	e.CompanyID = int(companyID.Int64)
+	e.Company = company.String
+	e.CompanySlug = slug.String
+
+	return e, nil
+}
+
+func normalizeEmail(email string) (string, error) {
+	addr, err := mail.ParseAddress(strings.TrimSpace(email))
+	if err != nil || addr.Name != "" {
+		return "", ErrEmailInvalid
+	}
+	return strings.ToLower(addr.Address), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
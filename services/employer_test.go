@@ -0,0 +1,70 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmployers(t *testing.T) {
+	store := openTestStore(t)
+	clk := &clock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	es := NewEmployerService(store, NewCompanyService(store))
+	es.Now = clk.Now
+
+	_, err := es.SignUp("not an email", "Ann", "Acme", "correct horse battery")
+	assert.ErrorIs(t, err, ErrEmailInvalid)
+	_, err = es.SignUp("ann@acme.example", "Ann", " ", "correct horse battery")
+	assert.ErrorIs(t, err, ErrEmployerNameRequired)
+	_, err = es.SignUp("ann@acme.example", "Ann", "Acme", "short")
+	assert.ErrorIs(t, err, ErrPasswordTooShort)
+
+	ann, err := es.SignUp(" Ann@Acme.example ", "Ann", "Acme, Inc.", "correct horse battery")
+	require.NoError(t, err)
+	assert.Equal(t, "ann@acme.example", ann.Email)
+	assert.Equal(t, "Acme, Inc.", ann.Company)
+	assert.Equal(t, "acme-inc", ann.CompanySlug)
+
+	_, err = es.SignUp("ANN@acme.example", "Ann", "Acme", "correct horse battery")
+	assert.ErrorIs(t, err, ErrEmailTaken)
+
+	bob, err := es.SignUp("bob@acme.example", "Bob", "ACME", "another long password")
+	require.NoError(t, err)
+	assert.Equal(t, ann.CompanyID, bob.CompanyID, "company names resolve to one profile")
+
+	_, err = es.LogIn("ann@acme.example", "wrong password")
+	assert.ErrorIs(t, err, ErrInvalidLogin)
+	_, err = es.LogIn("nobody@acme.example", "correct horse battery")
+	assert.ErrorIs(t, err, ErrInvalidLogin)
+
+	loggedIn, err := es.LogIn("ANN@acme.example", "correct horse battery")
+	require.NoError(t, err)
+	assert.Equal(t, ann, loggedIn)
+
+	t.Run("sessions", func(t *testing.T) {
+		token, expires, err := es.StartSession(ann.ID)
+		require.NoError(t, err)
+		assert.Equal(t, clk.Now().Add(es.SessionTTL), expires)
+
+		current, err := es.SessionEmployer(token)
+		require.NoError(t, err)
+		assert.Equal(t, ann.ID, current.ID)
+
+		_, err = es.SessionEmployer("forged")
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+		_, err = es.SessionEmployer("")
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+
+		require.NoError(t, es.EndSession(token))
+		_, err = es.SessionEmployer(token)
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+
+		token, _, err = es.StartSession(ann.ID)
+		require.NoError(t, err)
+		clk.Advance(es.SessionTTL + time.Minute)
+		_, err = es.SessionEmployer(token)
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+	})
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"htmxjb/models/domain"
+	"htmxjb/services/geo"
+	"htmxjb/services/mapping"
+	"htmxjb/services/salary"
+	"htmxjb/services/sanitize"
+	"htmxjb/services/tags"
+	"log"
+)
+
+// JobEnricher fills in derived fields on a fetched job before it is saved.
+type JobEnricher interface {
+	Enrich(job *domain.Job)
+}
+
+type JobEnricherFunc func(job *domain.Job)
+
+func (f JobEnricherFunc) Enrich(job *domain.Job) {
+	f(job)
+}
+
+// SalaryEnricher parses salary text the source left unparsed and converts
+// the range to yearly base-currency amounts.
+func SalaryEnricher(n *salary.Normalizer) JobEnricher {
+	return JobEnricherFunc(func(job *domain.Job) {
+		s := &job.Salary
+		if s.Raw == "" {
+			return
+		}
+
+		if s.Min == 0 && s.Max == 0 {
+			parsed, ok := salary.Parse(s.Raw, n.DefaultCurrency)
+			if !ok {
+				return
+			}
+			*s = parsed
+		}
+
+		if !n.Normalize(s) {
+			log.Printf("🔥 no exchange rate for salary currency %q", s.Currency)
+		}
+	})
+}
+
+// LocationEnricher splits location text into structured fields and
+// geocodes it with the gazetteer.
+func LocationEnricher(g *geo.Gazetteer) JobEnricher {
+	return JobEnricherFunc(func(job *domain.Job) {
+		job.Location = g.Resolve(job.Location)
+	})
+}
+
+// TypeEnricher maps the source's raw type text to employment and workplace
+// types. Jobs with no workplace in the text but a remote location are
+// treated as remote. Run it after LocationEnricher.
+func TypeEnricher(m *mapping.Mapper) JobEnricher {
+	return JobEnricherFunc(func(job *domain.Job) {
+		employment, workplace := m.Map(job.Source, job.TypeText)
+
+		if job.Employment == domain.UnknownEmployment {
+			job.Employment = employment
+		}
+
+		if workplace == domain.UnknownJobType && job.Location.Remote {
+			workplace = domain.Remote
+		}
+		job.Type = workplace
+	})
+}
+
+// DescriptionEnricher sanitizes the description markup and derives the
+// plain-text description from it when the source sent none. Run it before
+// TagEnricher.
+func DescriptionEnricher() JobEnricher {
+	return JobEnricherFunc(func(job *domain.Job) {
+		if job.DescriptionHTML == "" {
+			return
+		}
+
+		job.DescriptionHTML = sanitize.HTML(job.DescriptionHTML)
+		if job.Description == "" {
+			job.Description = sanitize.Text(job.DescriptionHTML)
+		}
+	})
+}
+
+// TagEnricher tags a job with the skills mentioned in its title and
+// description.
+func TagEnricher(x *tags.Extractor) JobEnricher {
+	return JobEnricherFunc(func(job *domain.Job) {
+		job.Tags = x.Extract(job.Title, job.Description)
+	})
+}
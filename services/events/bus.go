@@ -0,0 +1,60 @@
+package events
+
+import "sync"
+
+// JobCreated says a new job was listed.
+type JobCreated struct {
+	JobID int
+}
+
+// Bus fans events out to subscribers in the same process. Publishing
+// never blocks: a subscriber whose buffer is full misses the event, so a
+// slow client cannot hold up ingestion.
+type Bus struct {
+	mu   sync.Mutex
+	subs map[chan JobCreated]struct{}
+}
+
+func NewBus() *Bus {
+	return &Bus{subs: make(map[chan JobCreated]struct{})}
+}
+
+// Subscribe returns a channel of the events published from now on, and a
+// function that unsubscribes and closes the channel.
+func (b *Bus) Subscribe(buffer int) (<-chan JobCreated, func()) {
+	ch := make(chan JobCreated, buffer)
+
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, ch)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Publish sends e to every subscriber with room for it.
+func (b *Bus) Publish(e JobCreated) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// Subscribers returns how many subscribers there are.
+func (b *Bus) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs)
+}
@@ -0,0 +1,35 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus(t *testing.T) {
+	b := NewBus()
+	b.Publish(JobCreated{JobID: 1})
+
+	fast, unsubscribeFast := b.Subscribe(4)
+	slow, unsubscribeSlow := b.Subscribe(1)
+	assert.Equal(t, 2, b.Subscribers())
+
+	b.Publish(JobCreated{JobID: 2})
+	b.Publish(JobCreated{JobID: 3})
+
+	assert.Equal(t, JobCreated{JobID: 2}, <-fast)
+	assert.Equal(t, JobCreated{JobID: 3}, <-fast)
+	assert.Equal(t, JobCreated{JobID: 2}, <-slow)
+	assert.Empty(t, slow, "full subscribers miss events")
+
+	unsubscribeSlow()
+	unsubscribeSlow()
+	_, open := <-slow
+	assert.False(t, open)
+	assert.Equal(t, 1, b.Subscribers())
+
+	b.Publish(JobCreated{JobID: 4})
+	assert.Equal(t, JobCreated{JobID: 4}, <-fast)
+	unsubscribeFast()
+	assert.Zero(t, b.Subscribers())
+}
@@ -0,0 +1,86 @@
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+// docxParts are the parts of a Word document holding its text, body
+// first.
+var docxParts = []string{"word/document.xml", "word/header1.xml", "word/footer1.xml"}
+
+// DOCX extracts the text of a Word document, one line per paragraph.
+func DOCX(data []byte) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", ErrMalformed
+	}
+
+	files := make(map[string]*zip.File)
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+	if files[docxParts[0]] == nil {
+		return "", ErrMalformed
+	}
+
+	var out strings.Builder
+	for _, name := range docxParts {
+		f := files[name]
+		if f == nil {
+			continue
+		}
+		if err := docxText(&out, f); err != nil {
+			return "", err
+		}
+	}
+
+	return out.String(), nil
+}
+
+func docxText(out *strings.Builder, f *zip.File) error {
+	r, err := f.Open()
+	if err != nil {
+		return ErrMalformed
+	}
+	defer r.Close()
+
+	dec := xml.NewDecoder(io.LimitReader(r, maxDecoded))
+	inText := false
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return ErrMalformed
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				out.WriteByte('\t')
+			case "br", "cr":
+				out.WriteByte('\n')
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				out.WriteByte('\n')
+			}
+		case xml.CharData:
+			if inText {
+				out.Write(t)
+			}
+		}
+	}
+}
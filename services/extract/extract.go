@@ -0,0 +1,67 @@
+// Package extract pulls plain text out of resume files without calling
+// out to other programs or services.
+package extract
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrUnsupported = errors.New("only PDF and DOCX files can be read")
+	ErrNoText      = errors.New("no text could be read from the file")
+	ErrMalformed   = errors.New("the file is damaged or not what its name says")
+)
+
+// maxDecoded caps how much decompressed data one file may expand to, so a
+// small upload cannot exhaust memory.
+const maxDecoded = 50 << 20
+
+// Text extracts the text of a PDF or DOCX file, picking the format by the
+// file name's extension.
+func Text(name string, data []byte) (string, error) {
+	var (
+		text string
+		err  error
+	)
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".pdf":
+		text, err = PDF(data)
+	case ".docx":
+		text, err = DOCX(data)
+	default:
+		return "", ErrUnsupported
+	}
+	if err != nil {
+		return "", err
+	}
+
+	text = tidy(text)
+	if text == "" {
+		return "", ErrNoText
+	}
+	return text, nil
+}
+
+// tidy collapses runs of blanks within lines and drops empty lines and
+// control characters.
+func tidy(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.Map(func(r rune) rune {
+			if r == '\t' {
+				return ' '
+			}
+			if unicode.IsControl(r) || r == unicode.ReplacementChar {
+				return -1
+			}
+			return r
+		}, line)
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
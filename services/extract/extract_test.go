@@ -0,0 +1,184 @@
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildPDF numbers objects from 1 in the order given, leaving numbers of
+// empty entries unused. The cross-reference table is left out; the
+// extractor does not need it.
+func buildPDF(objects ...string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	for i, obj := range objects {
+		if obj == "" {
+			continue
+		}
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	buf.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return buf.Bytes()
+}
+
+func stream(dict, data string) string {
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data)
+}
+
+func flate(t *testing.T, data string) string {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	_, err := w.Write([]byte(data))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.String()
+}
+
+func TestPDFSimpleFont(t *testing.T) {
+	pdf := buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 6 0 R] /Count 2 /Resources << /Font << /F1 4 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 5 0 R >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		stream("", `BT /F1 12 Tf 72 720 Td (Senior Go Developer) Tj 0 -14 Td (Skills: Go,) Tj ( Kubernetes) Tj ET
+BT 72 680 Td [(Caf) -10 (\351 \(Berlin\) \\ ) -300 (Docker)] TJ ET`),
+		"<< /Type /Page /Parent 2 0 R /Contents [7 0 R] >>",
+		stream("", "BT /F1 12 Tf 1 0 0 1 72 720 Tm (Page two) Tj T* (next line) Tj ET"),
+	)
+
+	text, err := Text("resume.PDF", pdf)
+	require.NoError(t, err)
+	assert.Equal(t, "Senior Go Developer\nSkills: Go, Kubernetes\nCafé (Berlin) \\ Docker\nPage two\nnext line", text)
+}
+
+func TestPDFUnicodeFont(t *testing.T) {
+	cmap := `/CIDInit /ProcSet findresource begin
+12 dict begin begincmap
+1 begincodespacerange <0000> <FFFF> endcodespacerange
+2 beginbfchar <0003> <0020> <0010> <00E9> endbfchar
+1 beginbfrange <0020> <0039> <0041> endbfrange
+1 beginbfrange <0040> <0041> [<0052> <00660069>] endbfrange
+endcmap CMapName currentdict /CMap defineresource pop end end`
+	// Codes 0x20-0x39 map to A-Z, 0x40 to R and 0x41 to the "fi" ligature.
+	content := flate(t, "BT /F2 10 Tf 50 700 Td [<0033002400320033> -400 <0041>] TJ ET q /Fm1 Do Q")
+	form := "BT /F2 10 Tf 50 600 Td <002B00240024002300100033> Tj ET"
+	resources := "<< /Font << /F2 5 0 R >> /XObject << /Fm1 8 0 R >> >>"
+	fontDict := "<< /Type /Font /Subtype /Type0 /BaseFont /Inter /Encoding /Identity-H /ToUnicode 7 0 R >>"
+	header := fmt.Sprintf("4 0 5 %d ", len(resources)+1)
+	packed := header + resources + " " + fontDict
+
+	pdf := buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Resources 4 0 R /Contents 6 0 R >>",
+		"", "", // resources and font, packed into object 9
+		stream("/Filter /FlateDecode", content),
+		stream("", cmap),
+		stream("/Type /XObject /Subtype /Form /BBox [0 0 612 792]", form),
+		stream(fmt.Sprintf("/Type /ObjStm /N 2 /First %d /Filter /FlateDecode", len(header)), flate(t, packed)),
+	)
+
+	text, err := Text("resume.pdf", pdf)
+	require.NoError(t, err)
+	assert.Equal(t, "TEST fi\nLEEDéT", text)
+}
+
+func TestPDFWithoutText(t *testing.T) {
+	scanned := buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+		stream("", "q 612 0 0 792 0 0 cm /Im1 Do Q"),
+	)
+	_, err := Text("scan.pdf", scanned)
+	assert.ErrorIs(t, err, ErrNoText)
+
+	_, err = Text("resume.pdf", []byte("MZ\x90\x00"))
+	assert.ErrorIs(t, err, ErrMalformed)
+	_, err = Text("resume.txt", []byte("plain"))
+	assert.ErrorIs(t, err, ErrUnsupported)
+}
+
+func TestPDFTruncated(t *testing.T) {
+	pdf := buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 5 0 R >> >> /Contents 4 0 R >>",
+		stream("/Filter /FlateDecode", flate(t, "BT /F1 9 Tf [(Go) -400 (Developer)] TJ ET")),
+		"<< /Type /Font /Subtype /Type0 /ToUnicode 6 0 R >>",
+		stream("", "beginbfrange <00> <ff> <0041> endbfrange"),
+	)
+
+	for n := 0; n < len(pdf); n++ {
+		_, err := PDF(pdf[:n])
+		if err != nil {
+			assert.ErrorIs(t, err, ErrMalformed)
+		}
+	}
+}
+
+func TestPDFDeeplyNested(t *testing.T) {
+	// Nesting this deep used to overflow the stack, which recover cannot
+	// catch.
+	deep := []byte("%PDF-1.7\n1 0 obj\n" + strings.Repeat("[", 4<<20))
+	_, err := Text("resume.pdf", deep)
+	assert.ErrorIs(t, err, ErrNoText)
+
+	// Values nested too deeply are dropped; the rest of the file is read.
+	pdf := buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R /Junk "+strings.Repeat("[<<", 1<<16)+strings.Repeat(">>]", 1<<16)+" >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 /Resources << /Font << /F1 4 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 5 0 R >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		stream("", "BT /F1 12 Tf "+strings.Repeat("[", 1<<16)+strings.Repeat("]", 1<<16)+" (Go Developer) Tj ET"),
+	)
+	text, err := Text("resume.pdf", pdf)
+	require.NoError(t, err)
+	assert.Equal(t, "Go Developer", text)
+}
+
+func TestDOCX(t *testing.T) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	f, err := w.Create("word/document.xml")
+	require.NoError(t, err)
+	_, err = f.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:r><w:t>Ann</w:t></w:r><w:r><w:t xml:space="preserve"> Example</w:t></w:r></w:p>
+<w:p><w:r><w:t>Go</w:t><w:tab/><w:t>PostgreSQL &amp; Kubernetes</w:t></w:r></w:p>
+<w:p></w:p>
+<w:p><w:r><w:t>Line</w:t><w:br/><w:t>break</w:t></w:r></w:p>
+</w:body></w:document>`))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	text, err := Text("cv.docx", buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, "Ann Example\nGo PostgreSQL & Kubernetes\nLine\nbreak", text)
+
+	_, err = Text("cv.docx", []byte("PK\x03\x04 not really"))
+	assert.ErrorIs(t, err, ErrMalformed)
+	_, err = DOCX(zipWith(t, "xl/workbook.xml"))
+	assert.ErrorIs(t, err, ErrMalformed, "other Office files are not Word documents")
+}
+
+func zipWith(t *testing.T, name string) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	_, err := w.Create(name)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestTidy(t *testing.T) {
+	assert.Equal(t, "a b\nc", tidy("  a \t b \n\n\x00 \n c� "))
+	assert.Equal(t, "", strings.TrimSpace(tidy("\n \n")))
+}
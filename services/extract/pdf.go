@@ -0,0 +1,527 @@
+package extract
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// objectHeader finds "12 0 obj". Objects are found by scanning rather than
+// through the cross-reference table, which is often wrong in files saved
+// by careless tools.
+var objectHeader = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+// maxXObjectDepth bounds how deeply forms may draw other forms.
+const maxXObjectDepth = 8
+
+var (
+	errDecodeLimit = errors.New("decompressed data too large")
+	errNotStream   = errors.New("not a stream")
+	errFilter      = errors.New("unsupported stream filter")
+)
+
+type pdfObject struct {
+	value  any
+	stream []byte
+}
+
+type pdfDoc struct {
+	objects map[int]*pdfObject
+	streams map[int][]byte
+	fonts   map[int]*font
+	decoded int
+}
+
+// PDF extracts the text of a PDF file's pages. It reads text drawn with
+// simple fonts and with fonts that map their glyphs to Unicode, which
+// covers what word processors export. Scanned resumes have no text to
+// read.
+func PDF(data []byte) (text string, err error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte("%PDF-")) {
+		return "", ErrMalformed
+	}
+
+	// The parser reads untrusted input; a bug in it must not take the
+	// server down.
+	defer func() {
+		if r := recover(); r != nil {
+			text, err = "", fmt.Errorf("%w: %v", ErrMalformed, r)
+		}
+	}()
+
+	doc := &pdfDoc{
+		objects: make(map[int]*pdfObject),
+		streams: make(map[int][]byte),
+		fonts:   make(map[int]*font),
+	}
+	doc.scan(data)
+	if err := doc.expandObjectStreams(); err != nil {
+		return "", err
+	}
+
+	var out textWriter
+	for _, page := range doc.pages() {
+		for _, content := range doc.contents(page.dict["Contents"]) {
+			if err := doc.run(&out, content, page.resources, 0); err != nil {
+				return "", err
+			}
+		}
+		out.newline()
+	}
+
+	return out.String(), nil
+}
+
+// scan reads every "n g obj ... endobj" in the file. Later definitions
+// replace earlier ones, as incremental updates do.
+func (doc *pdfDoc) scan(data []byte) {
+	skipUntil := 0
+	for _, m := range objectHeader.FindAllSubmatchIndex(data, -1) {
+		if m[0] < skipUntil {
+			continue
+		}
+		num, err := strconv.Atoi(string(data[m[2]:m[3]]))
+		if err != nil {
+			continue
+		}
+
+		l := &lexer{data: data, pos: m[1]}
+		value, ok := l.object(0)
+		if !ok {
+			continue
+		}
+		obj := &pdfObject{value: value}
+
+		if dict, isDict := value.(pdfDict); isDict {
+			l.skipSpace()
+			if bytes.HasPrefix(data[l.pos:], []byte("stream")) {
+				obj.stream, skipUntil = streamData(data, l.pos+len("stream"), dict)
+			}
+		}
+		doc.objects[num] = obj
+	}
+}
+
+// streamData returns the raw bytes of the stream starting after the
+// "stream" keyword at start, and where they end.
+func streamData(data []byte, start int, dict pdfDict) ([]byte, int) {
+	if bytes.HasPrefix(data[start:], []byte("\r\n")) {
+		start += 2
+	} else if start < len(data) && (data[start] == '\n' || data[start] == '\r') {
+		start++
+	}
+
+	if n, ok := dict["Length"].(float64); ok && n >= 0 && start+int(n) <= len(data) {
+		end := start + int(n)
+		rest := bytes.TrimLeft(data[end:], " \t\r\n")
+		if bytes.HasPrefix(rest, []byte("endstream")) {
+			return data[start:end], end
+		}
+	}
+
+	end := bytes.Index(data[start:], []byte("endstream"))
+	if end < 0 {
+		return data[start:], len(data)
+	}
+	return bytes.TrimRight(data[start:start+end], "\r\n"), start + end
+}
+
+// expandObjectStreams adds the objects packed into object streams.
+func (doc *pdfDoc) expandObjectStreams() error {
+	var nums []int
+	for num, obj := range doc.objects {
+		if dict, ok := obj.value.(pdfDict); ok && dict["Type"] == pdfName("ObjStm") && obj.stream != nil {
+			nums = append(nums, num)
+		}
+	}
+	sort.Ints(nums)
+
+	for _, num := range nums {
+		obj := doc.objects[num]
+		dict := obj.value.(pdfDict)
+		data, err := doc.decode(dict, obj.stream)
+		if errors.Is(err, errDecodeLimit) {
+			return ErrMalformed
+		}
+		if err != nil {
+			continue
+		}
+
+		count, _ := dict["N"].(float64)
+		first, _ := dict["First"].(float64)
+		if int(first) > len(data) || first < 0 {
+			continue
+		}
+
+		header := &lexer{data: data[:int(first)]}
+		for i := 0; i < int(count); i++ {
+			n, ok1 := header.object(0)
+			off, ok2 := header.object(0)
+			objNum, isNum := n.(float64)
+			offset, isOff := off.(float64)
+			if !ok1 || !ok2 || !isNum || !isOff {
+				break
+			}
+			if _, defined := doc.objects[int(objNum)]; defined {
+				continue
+			}
+			at := int(first) + int(offset)
+			if at < 0 || at >= len(data) {
+				continue
+			}
+			if value, ok := (&lexer{data: data, pos: at}).object(0); ok {
+				doc.objects[int(objNum)] = &pdfObject{value: value}
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolve follows references to the value they point at.
+func (doc *pdfDoc) resolve(v any) any {
+	for i := 0; i < 32; i++ {
+		ref, ok := v.(pdfRef)
+		if !ok {
+			return v
+		}
+		obj := doc.objects[ref.num]
+		if obj == nil {
+			return nil
+		}
+		v = obj.value
+	}
+	return nil
+}
+
+func (doc *pdfDoc) dict(v any) pdfDict {
+	d, _ := doc.resolve(v).(pdfDict)
+	return d
+}
+
+// stream returns the dictionary and decoded data of the stream v refers
+// to, if it can be decoded. Decoded streams are kept, as forms may be
+// drawn many times.
+func (doc *pdfDoc) stream(v any) (pdfDict, []byte, error) {
+	ref, ok := v.(pdfRef)
+	if !ok {
+		return nil, nil, errNotStream
+	}
+	obj := doc.objects[ref.num]
+	if obj == nil || obj.stream == nil {
+		return nil, nil, errNotStream
+	}
+	dict, _ := obj.value.(pdfDict)
+	if data, ok := doc.streams[ref.num]; ok {
+		return dict, data, nil
+	}
+
+	data, err := doc.decode(dict, obj.stream)
+	if err != nil {
+		return nil, nil, err
+	}
+	doc.streams[ref.num] = data
+	return dict, data, nil
+}
+
+// decode undoes a stream's compression. Only Flate is supported; streams
+// with other filters hold images, not text.
+func (doc *pdfDoc) decode(dict pdfDict, raw []byte) ([]byte, error) {
+	var filters []any
+	switch f := doc.resolve(dict["Filter"]).(type) {
+	case nil:
+		return raw, nil
+	case pdfName:
+		filters = []any{f}
+	case pdfArray:
+		filters = f
+	}
+	if len(filters) != 1 || (doc.resolve(filters[0]) != pdfName("FlateDecode") && doc.resolve(filters[0]) != pdfName("Fl")) {
+		return nil, errFilter
+	}
+
+	r, err := zlib.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	limit := maxDecoded - doc.decoded
+	out, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+	if len(out) > limit {
+		return nil, errDecodeLimit
+	}
+	doc.decoded += len(out)
+	// Truncated streams are common; keep what could be read.
+	if err != nil && len(out) == 0 {
+		return nil, err
+	}
+	return out, nil
+}
+
+type pdfPage struct {
+	dict      pdfDict
+	resources any
+}
+
+// pages walks the page tree in reading order. Resources are inherited from
+// parent nodes.
+func (doc *pdfDoc) pages() []pdfPage {
+	var nums []int
+	for num := range doc.objects {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+
+	var root pdfDict
+	for _, num := range nums {
+		if d, ok := doc.objects[num].value.(pdfDict); ok && d["Type"] == pdfName("Catalog") {
+			root = d
+		}
+	}
+
+	var pages []pdfPage
+	visited := make(map[int]bool)
+	var walk func(node any, resources any, depth int)
+	walk = func(node any, resources any, depth int) {
+		if ref, ok := node.(pdfRef); ok {
+			if visited[ref.num] {
+				return
+			}
+			visited[ref.num] = true
+		}
+		d := doc.dict(node)
+		if d == nil || depth > maxNesting {
+			return
+		}
+		if r, ok := d["Resources"]; ok {
+			resources = r
+		}
+		if kids, ok := doc.resolve(d["Kids"]).(pdfArray); ok {
+			for _, kid := range kids {
+				walk(kid, resources, depth+1)
+			}
+			return
+		}
+		if d["Type"] == pdfName("Page") || d["Contents"] != nil {
+			pages = append(pages, pdfPage{dict: d, resources: resources})
+		}
+	}
+	if root != nil {
+		walk(root["Pages"], nil, 0)
+	}
+	if len(pages) > 0 {
+		return pages
+	}
+
+	// Without a usable page tree, take the pages in object order.
+	for _, num := range nums {
+		d, ok := doc.objects[num].value.(pdfDict)
+		if !ok || d["Type"] != pdfName("Page") {
+			continue
+		}
+		page := pdfPage{dict: d, resources: d["Resources"]}
+		for p, depth := d, 0; page.resources == nil && p != nil && depth < maxNesting; depth++ {
+			p = doc.dict(p["Parent"])
+			if p != nil {
+				page.resources = p["Resources"]
+			}
+		}
+		pages = append(pages, page)
+	}
+	return pages
+}
+
+// contents returns a page's decoded content streams.
+func (doc *pdfDoc) contents(v any) [][]byte {
+	refs := []any{v}
+	if arr, ok := doc.resolve(v).(pdfArray); ok {
+		refs = arr
+	}
+
+	var out [][]byte
+	for _, ref := range refs {
+		if _, data, err := doc.stream(ref); err == nil {
+			out = append(out, data)
+		}
+	}
+	return out
+}
+
+// run interprets a content stream, writing the text it draws to out.
+func (doc *pdfDoc) run(out *textWriter, content []byte, resources any, depth int) error {
+	var (
+		l        = &lexer{data: content}
+		operands []any
+		cur      *font
+		y        float64
+		moved    bool
+	)
+
+	show := func(s pdfString) {
+		if out.shown && y != out.lineY {
+			out.newline()
+		} else if moved {
+			out.space()
+		}
+		out.write(cur.decode(s))
+		out.shown, out.lineY, moved = true, y, false
+	}
+
+	for {
+		v, ok := l.object(0)
+		if !ok {
+			return nil
+		}
+		op, isOp := v.(pdfKeyword)
+		if !isOp {
+			if len(operands) < 64 {
+				operands = append(operands, v)
+			}
+			continue
+		}
+
+		switch op {
+		case "BT":
+			y, moved = 0, true
+		case "Td", "TD":
+			if ty, ok := number(operands, 0); ok {
+				y += ty
+			}
+			moved = true
+		case "Tm":
+			if f, ok := number(operands, 0); ok {
+				y = f
+			}
+			moved = true
+		case "T*":
+			y--
+		case "Tf":
+			if len(operands) >= 2 {
+				if name, ok := operands[len(operands)-2].(pdfName); ok {
+					cur = doc.font(resources, name)
+				}
+			}
+		case "Tj":
+			if s, ok := last(operands).(pdfString); ok {
+				show(s)
+			}
+		case "'", "\"":
+			y--
+			if s, ok := last(operands).(pdfString); ok {
+				show(s)
+			}
+		case "TJ":
+			arr, _ := last(operands).(pdfArray)
+			for _, el := range arr {
+				switch el := el.(type) {
+				case pdfString:
+					show(el)
+				case float64:
+					// Kerning wider than about a quarter of the font
+					// size separates words.
+					if el < -250 {
+						moved = true
+					}
+				}
+			}
+		case "Do":
+			if name, ok := last(operands).(pdfName); ok && depth < maxXObjectDepth {
+				if err := doc.form(out, resources, name, depth); err != nil {
+					return err
+				}
+			}
+		case "BI":
+			skipInlineImage(l)
+		}
+		operands = operands[:0]
+	}
+}
+
+// form runs the content of a form XObject drawn with Do.
+func (doc *pdfDoc) form(out *textWriter, resources any, name pdfName, depth int) error {
+	xobjects := doc.dict(doc.dict(resources)["XObject"])
+	ref := xobjects[string(name)]
+	dict, data, err := doc.stream(ref)
+	if errors.Is(err, errDecodeLimit) {
+		return ErrMalformed
+	}
+	if err != nil || dict["Subtype"] != pdfName("Form") {
+		return nil
+	}
+
+	if r, ok := dict["Resources"]; ok {
+		resources = r
+	}
+	return doc.run(out, data, resources, depth+1)
+}
+
+// skipInlineImage moves past the image data between ID and EI.
+func skipInlineImage(l *lexer) {
+	i := bytes.Index(l.data[l.pos:], []byte("ID"))
+	if i < 0 {
+		l.pos = len(l.data)
+		return
+	}
+	l.pos += i + 2
+	for {
+		j := bytes.Index(l.data[l.pos:], []byte("EI"))
+		if j < 0 {
+			l.pos = len(l.data)
+			return
+		}
+		end := l.pos + j + 2
+		if isSpace(l.data[l.pos+j-1]) && (end == len(l.data) || isSpace(l.data[end])) {
+			l.pos = end
+			return
+		}
+		l.pos = end
+	}
+}
+
+func last(operands []any) any {
+	if len(operands) == 0 {
+		return nil
+	}
+	return operands[len(operands)-1]
+}
+
+// number returns the operand i places from the end.
+func number(operands []any, i int) (float64, bool) {
+	if len(operands) <= i {
+		return 0, false
+	}
+	n, ok := operands[len(operands)-1-i].(float64)
+	return n, ok
+}
+
+// textWriter collects extracted text, keeping at most one blank between
+// words and one line break between lines.
+type textWriter struct {
+	strings.Builder
+	shown bool
+	lineY float64
+}
+
+func (w *textWriter) write(s string) {
+	w.WriteString(s)
+}
+
+func (w *textWriter) space() {
+	if s := w.String(); s != "" && !strings.HasSuffix(s, " ") && !strings.HasSuffix(s, "\n") {
+		w.WriteByte(' ')
+	}
+}
+
+func (w *textWriter) newline() {
+	if s := w.String(); s != "" && !strings.HasSuffix(s, "\n") {
+		w.WriteByte('\n')
+	}
+	w.shown = false
+}
@@ -0,0 +1,202 @@
+package extract
+
+import (
+	"strings"
+	"unicode/utf16"
+)
+
+// maxCMapRange bounds the codes one bfrange line may map.
+const maxCMapRange = 1 << 16
+
+// font turns the bytes of shown strings into text. Fonts with a ToUnicode
+// map use it; simple fonts without one are read as WinAnsi, which is what
+// almost all of them use.
+type font struct {
+	width   int
+	unicode map[uint32]string
+	// composite fonts without a ToUnicode map cannot be read.
+	unreadable bool
+}
+
+func (f *font) decode(s []byte) string {
+	if f == nil {
+		f = &font{width: 1}
+	}
+	if f.unreadable {
+		return ""
+	}
+
+	var out strings.Builder
+	for i := 0; i+f.width <= len(s); i += f.width {
+		var code uint32
+		for _, b := range s[i : i+f.width] {
+			code = code<<8 | uint32(b)
+		}
+		if text, ok := f.unicode[code]; ok {
+			out.WriteString(text)
+		} else if f.width == 1 {
+			out.WriteRune(winAnsi(s[i]))
+		}
+	}
+	return out.String()
+}
+
+// winAnsi maps a WinAnsiEncoding byte to its character. Bytes outside
+// 0x80-0x9f match Latin-1.
+func winAnsi(b byte) rune {
+	if b < 0x20 {
+		return ' '
+	}
+	if r, ok := winAnsiHigh[b]; ok {
+		return r
+	}
+	return rune(b)
+}
+
+var winAnsiHigh = map[byte]rune{
+	0x80: '€', 0x85: '…', 0x86: '†', 0x87: '‡', 0x89: '‰', 0x8a: 'Š', 0x8c: 'Œ', 0x8e: 'Ž',
+	0x91: '‘', 0x92: '’', 0x93: '“', 0x94: '”', 0x95: '•', 0x96: '–', 0x97: '—', 0x98: '˜',
+	0x99: '™', 0x9a: 'š', 0x9c: 'œ', 0x9e: 'ž', 0x9f: 'Ÿ',
+}
+
+// font returns the font a Tf operator selects from resources, loading and
+// caching it.
+func (doc *pdfDoc) font(resources any, name pdfName) *font {
+	ref := doc.dict(doc.dict(resources)["Font"])[string(name)]
+	if r, ok := ref.(pdfRef); ok {
+		if f, cached := doc.fonts[r.num]; cached {
+			return f
+		}
+		f := doc.loadFont(ref)
+		doc.fonts[r.num] = f
+		return f
+	}
+	return doc.loadFont(ref)
+}
+
+func (doc *pdfDoc) loadFont(ref any) *font {
+	d := doc.dict(ref)
+	f := &font{width: 1}
+	if d["Subtype"] == pdfName("Type0") {
+		f.width = 2
+	}
+
+	if _, data, err := doc.stream(d["ToUnicode"]); err == nil {
+		width, unicode := parseCMap(data)
+		f.unicode = unicode
+		if width > 0 {
+			f.width = width
+		}
+	}
+	f.unreadable = f.width > 1 && len(f.unicode) == 0
+
+	return f
+}
+
+// parseCMap reads the bfchar and bfrange mappings of a ToUnicode CMap,
+// returning the code width in bytes as well.
+func parseCMap(data []byte) (int, map[uint32]string) {
+	l := &lexer{data: data}
+	unicode := make(map[uint32]string)
+	width := 0
+
+	next := func() (any, bool) { return l.object(0) }
+	for {
+		v, ok := next()
+		if !ok {
+			return width, unicode
+		}
+
+		switch v {
+		case pdfKeyword("begincodespacerange"):
+			for {
+				lo, ok := next()
+				if !ok || lo == pdfKeyword("endcodespacerange") {
+					break
+				}
+				next()
+				if s, isStr := lo.(pdfString); isStr && width == 0 && len(s) > 0 && len(s) <= 4 {
+					width = len(s)
+				}
+			}
+		case pdfKeyword("beginbfchar"):
+			for {
+				src, ok := next()
+				if !ok || src == pdfKeyword("endbfchar") {
+					break
+				}
+				dst, _ := next()
+				s, isSrc := src.(pdfString)
+				d, isDst := dst.(pdfString)
+				if isSrc && isDst && len(s) <= 4 {
+					unicode[code(s)] = utf16BE(d)
+				}
+			}
+		case pdfKeyword("beginbfrange"):
+			for {
+				lo, ok := next()
+				if !ok || lo == pdfKeyword("endbfrange") {
+					break
+				}
+				hi, _ := next()
+				dst, _ := next()
+				loCode, isLo := lo.(pdfString)
+				hiCode, isHi := hi.(pdfString)
+				if !isLo || !isHi || len(loCode) > 4 || len(hiCode) > 4 {
+					continue
+				}
+				first, end := code(loCode), code(hiCode)
+				if end < first || end-first >= maxCMapRange {
+					continue
+				}
+				bfrange(unicode, first, end, dst)
+			}
+		}
+	}
+}
+
+// bfrange maps codes first to end either to consecutive characters from
+// dst or to the strings listed in dst.
+func bfrange(unicode map[uint32]string, first, end uint32, dst any) {
+	switch d := dst.(type) {
+	case pdfString:
+		units := utf16Units(d)
+		if len(units) == 0 {
+			return
+		}
+		for c := first; c <= end; c++ {
+			shifted := append([]uint16(nil), units...)
+			shifted[len(shifted)-1] += uint16(c - first)
+			unicode[c] = string(utf16.Decode(shifted))
+		}
+	case pdfArray:
+		for i, el := range d {
+			if s, ok := el.(pdfString); ok && first+uint32(i) <= end {
+				unicode[first+uint32(i)] = utf16BE(s)
+			}
+		}
+	}
+}
+
+func code(s []byte) uint32 {
+	var c uint32
+	for _, b := range s {
+		c = c<<8 | uint32(b)
+	}
+	return c
+}
+
+func utf16Units(s []byte) []uint16 {
+	units := make([]uint16, 0, len(s)/2)
+	for i := 0; i+1 < len(s); i += 2 {
+		units = append(units, uint16(s[i])<<8|uint16(s[i+1]))
+	}
+	return units
+}
+
+func utf16BE(s []byte) string {
+	if len(s)%2 == 1 {
+		return string(s)
+	}
+	return string(utf16.Decode(utf16Units(s)))
+}
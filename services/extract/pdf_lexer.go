@@ -0,0 +1,311 @@
+package extract
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strconv"
+)
+
+// PDF values, as read by the lexer. Numbers are float64, booleans bool
+// and null nil.
+type (
+	pdfName    string
+	pdfString  []byte
+	pdfKeyword string
+	pdfArray   []any
+	pdfDict    map[string]any
+	pdfRef     struct{ num, gen int }
+)
+
+// maxNesting bounds how deeply arrays and dictionaries may nest. Deeper
+// ones are skipped rather than read, so hostile files cannot exhaust the
+// stack.
+const maxNesting = 64
+
+// lexer reads PDF objects and content stream operators.
+type lexer struct {
+	data []byte
+	pos  int
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == 0
+}
+
+func isDelim(c byte) bool {
+	return bytes.IndexByte([]byte("()<>[]{}/%"), c) >= 0
+}
+
+func (l *lexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		switch {
+		case isSpace(c):
+			l.pos++
+		case c == '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// object reads the next value, or an operator as a pdfKeyword. It reports
+// false at the end of the data.
+func (l *lexer) object(depth int) (any, bool) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, false
+	}
+
+	switch c := l.data[l.pos]; {
+	case c == '(':
+		return pdfString(l.literal()), true
+	case c == '/':
+		return l.name(), true
+	case c == '<' && l.peek(1) == '<':
+		l.pos += 2
+		return l.dict(depth), true
+	case c == '<':
+		return pdfString(l.hexString()), true
+	case c == '>' && l.peek(1) == '>':
+		l.pos += 2
+		return pdfKeyword(">>"), true
+	case c == '[':
+		l.pos++
+		return l.array(depth), true
+	case c == ']' || c == '{' || c == '}' || c == ')' || c == '>':
+		l.pos++
+		return pdfKeyword(l.data[l.pos-1 : l.pos]), true
+	}
+
+	word := l.regular()
+	if n, err := strconv.ParseFloat(word, 64); err == nil {
+		return l.maybeRef(n), true
+	}
+	switch word {
+	case "true":
+		return true, true
+	case "false":
+		return false, true
+	case "null":
+		return nil, true
+	}
+	return pdfKeyword(word), true
+}
+
+func (l *lexer) peek(n int) byte {
+	if l.pos+n < len(l.data) {
+		return l.data[l.pos+n]
+	}
+	return 0
+}
+
+func (l *lexer) regular() string {
+	start := l.pos
+	for l.pos < len(l.data) && !isSpace(l.data[l.pos]) && !isDelim(l.data[l.pos]) {
+		l.pos++
+	}
+	if l.pos == start {
+		l.pos++
+	}
+	return string(l.data[start:l.pos])
+}
+
+// maybeRef turns "12 0 R" into a reference, leaving other numbers alone.
+func (l *lexer) maybeRef(n float64) any {
+	if n != float64(int(n)) || n < 0 {
+		return n
+	}
+
+	save := l.pos
+	l.skipSpace()
+	gen := l.regular()
+	l.skipSpace()
+	if g, err := strconv.Atoi(gen); err == nil && l.pos < len(l.data) && l.data[l.pos] == 'R' &&
+		(l.pos+1 == len(l.data) || isSpace(l.data[l.pos+1]) || isDelim(l.data[l.pos+1])) {
+		l.pos++
+		return pdfRef{num: int(n), gen: g}
+	}
+	l.pos = save
+	return n
+}
+
+func (l *lexer) array(depth int) pdfArray {
+	if depth >= maxNesting {
+		l.skipNested()
+		return nil
+	}
+
+	var arr pdfArray
+	for {
+		v, ok := l.object(depth + 1)
+		if !ok || v == pdfKeyword("]") {
+			return arr
+		}
+		arr = append(arr, v)
+	}
+}
+
+func (l *lexer) dict(depth int) pdfDict {
+	d := make(pdfDict)
+	if depth >= maxNesting {
+		l.skipNested()
+		return d
+	}
+
+	for {
+		k, ok := l.object(depth + 1)
+		if !ok || k == pdfKeyword(">>") {
+			return d
+		}
+		key, isName := k.(pdfName)
+		if !isName {
+			continue
+		}
+		v, ok := l.object(depth + 1)
+		if !ok || v == pdfKeyword(">>") {
+			return d
+		}
+		d[string(key)] = v
+	}
+}
+
+// skipNested moves past the end of the array or dictionary just opened,
+// counting brackets instead of recursing.
+func (l *lexer) skipNested() {
+	for open := 1; open > 0; {
+		l.skipSpace()
+		if l.pos >= len(l.data) {
+			return
+		}
+
+		switch c := l.data[l.pos]; {
+		case c == '(':
+			l.literal()
+		case c == '<' && l.peek(1) == '<':
+			l.pos += 2
+			open++
+		case c == '>' && l.peek(1) == '>':
+			l.pos += 2
+			open--
+		case c == '<':
+			l.hexString()
+		case c == '[':
+			l.pos++
+			open++
+		case c == ']':
+			l.pos++
+			open--
+		default:
+			l.pos++
+		}
+	}
+}
+
+func (l *lexer) name() pdfName {
+	l.pos++
+	start := l.pos
+	for l.pos < len(l.data) && !isSpace(l.data[l.pos]) && !isDelim(l.data[l.pos]) {
+		l.pos++
+	}
+
+	raw := l.data[start:l.pos]
+	if bytes.IndexByte(raw, '#') < 0 {
+		return pdfName(raw)
+	}
+	var out []byte
+	for i := 0; i < len(raw); i++ {
+		if raw[i] == '#' && i+2 < len(raw) {
+			if b, err := hex.DecodeString(string(raw[i+1 : i+3])); err == nil {
+				out = append(out, b[0])
+				i += 2
+				continue
+			}
+		}
+		out = append(out, raw[i])
+	}
+	return pdfName(out)
+}
+
+// literal reads a (string), undoing its escapes.
+func (l *lexer) literal() []byte {
+	l.pos++
+	depth := 1
+	var out []byte
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '\\':
+			if l.pos >= len(l.data) {
+				return out
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b':
+				out = append(out, '\b')
+			case 'f':
+				out = append(out, '\f')
+			case '\r':
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+			case '\n':
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						v = v*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					out = append(out, byte(v))
+				} else {
+					out = append(out, e)
+				}
+			}
+		case '(':
+			depth++
+			out = append(out, c)
+		case ')':
+			if depth--; depth == 0 {
+				return out
+			}
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// hexString reads a <hex string>.
+func (l *lexer) hexString() []byte {
+	l.pos++
+	var digits []byte
+	for l.pos < len(l.data) && l.data[l.pos] != '>' {
+		if c := l.data[l.pos]; !isSpace(c) {
+			digits = append(digits, c)
+		}
+		l.pos++
+	}
+	l.pos++
+
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, len(digits)/2)
+	if _, err := hex.Decode(out, digits); err != nil {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"htmxjb/models/domain"
+	"htmxjb/services/geo"
+	"strings"
+)
+
+type JobSort string
+
+const (
+	SortNewest     JobSort = "newest"
+	SortSalaryDesc JobSort = "salary_desc"
+	SortSalaryAsc  JobSort = "salary_asc"
+	// SortRecommended orders by match with Profile and needs one.
+	SortRecommended JobSort = "recommended"
+)
+
+// JobFilter narrows and orders the job list. Salary bounds are yearly
+// amounts in the base currency; a job matches when its range overlaps them.
+// With Near set, only jobs within RadiusKm of that point are returned;
+// with NearUnknown set, NearCity could not be found and no jobs are.
+// Jobs must have every one of Tags. Profile is the seeker's resume profile,
+// if they uploaded one.
+type JobFilter struct {
+	MinSalary   float64
+	MaxSalary   float64
+	NearCity    string
+	Near        *geo.Point
+	NearUnknown bool
+	RadiusKm    float64
+	Workplace   *domain.JobType
+	Employment  *domain.EmploymentType
+	Tags        []string
+	Sort        JobSort
+	Profile     *SeekerProfile
+}
+
+func (f JobFilter) where() (string, []interface{}) {
+	clauses := []string{"status IN (?, ?)"}
+	args := []interface{}{domain.Active, domain.Closed}
+
+	if f.MinSalary > 0 {
+		clauses = append(clauses, "COALESCE(salary_annual_max, salary_annual_min) >= ?")
+		args = append(args, f.MinSalary)
+	}
+	if f.MaxSalary > 0 {
+		clauses = append(clauses, "COALESCE(salary_annual_min, salary_annual_max) <= ?")
+		args = append(args, f.MaxSalary)
+	}
+
+	if f.Workplace != nil {
+		clauses = append(clauses, "type = ?")
+		args = append(args, *f.Workplace)
+	}
+	if f.Employment != nil {
+		clauses = append(clauses, "employment_type = ?")
+		args = append(args, *f.Employment)
+	}
+
+	if tags := f.tagNames(); len(tags) > 0 {
+		clauses = append(clauses, "id IN (SELECT jt.job_id FROM job_tags jt JOIN tags t ON t.id = jt.tag_id"+
+			" WHERE t.name IN (?"+strings.Repeat(", ?", len(tags)-1)+") GROUP BY jt.job_id HAVING COUNT(*) = ?)")
+		for _, tag := range tags {
+			args = append(args, tag)
+		}
+		args = append(args, len(tags))
+	}
+
+	if f.NearUnknown {
+		clauses = append(clauses, "0")
+	}
+	if f.hasRadius() {
+		box := geo.BoundingBox(*f.Near, f.RadiusKm)
+		clauses = append(clauses, "latitude BETWEEN ? AND ? AND longitude BETWEEN ? AND ?")
+		args = append(args, box.MinLat, box.MaxLat, box.MinLon, box.MaxLon)
+	}
+
+	return strings.Join(clauses, " AND "), args
+}
+
+// tagNames returns Tags without blanks and case-insensitive duplicates, so
+// the HAVING count matches.
+func (f JobFilter) tagNames() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, tag := range f.Tags {
+		key := strings.ToLower(strings.TrimSpace(tag))
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		names = append(names, strings.TrimSpace(tag))
+	}
+	return names
+}
+
+// recommended reports whether jobs are ordered by match, which is done
+// after scoring rather than in SQL.
+func (f JobFilter) recommended() bool {
+	return f.Sort == SortRecommended && f.Profile != nil
+}
+
+func (f JobFilter) hasRadius() bool {
+	return f.Near != nil && f.RadiusKm > 0
+}
+
+// matchesRadius does the exact distance check for rows that passed the
+// bounding box in SQL.
+func (f JobFilter) matchesRadius(loc domain.Location) bool {
+	if !f.hasRadius() {
+		return true
+	}
+	if !loc.HasCoordinates() {
+		return false
+	}
+	return geo.HaversineKm(*f.Near, geo.Point{Lat: loc.Latitude, Lon: loc.Longitude}) <= f.RadiusKm
+}
+
+// orderBy puts pinned jobs ahead of the others with the same status. Its
+// placeholder takes the current time.
+func (f JobFilter) orderBy() string {
+	const pinned = "status, COALESCE(pinned_until > ?, 0) DESC, "
+	switch f.Sort {
+	case SortSalaryDesc:
+		return pinned + "COALESCE(salary_annual_max, salary_annual_min) DESC NULLS LAST, created_at DESC"
+	case SortSalaryAsc:
+		return pinned + "COALESCE(salary_annual_min, salary_annual_max) ASC NULLS LAST, created_at DESC"
+	default:
+		return pinned + "created_at DESC"
+	}
+}
@@ -0,0 +1,212 @@
+package geo
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"htmxjb/models/domain"
+	"strconv"
+	"strings"
+)
+
+//go:embed cities.tsv
+var bundledCities []byte
+
+// Place is a gazetteer entry.
+type Place struct {
+	City        string
+	Region      string
+	CountryCode string
+	Point
+}
+
+// Gazetteer geocodes city names offline from a bundled table of cities.
+type Gazetteer struct {
+	places map[string][]Place
+}
+
+var remoteKeywords = []string{"remote", "anywhere", "work from home", "wfh", "удаленно", "удалённо", "удаленная", "home office"}
+
+// countryCodes maps country names seen in location text to ISO codes.
+var countryCodes = map[string]string{
+	"united states": "US", "usa": "US", "us": "US", "united states of america": "US",
+	"canada": "CA", "mexico": "MX", "brazil": "BR", "argentina": "AR", "chile": "CL",
+	"colombia": "CO", "peru": "PE",
+	"united kingdom": "GB", "uk": "GB", "england": "GB", "scotland": "GB", "great britain": "GB",
+	"ireland": "IE", "france": "FR", "germany": "DE", "deutschland": "DE",
+	"netherlands": "NL", "the netherlands": "NL", "belgium": "BE", "luxembourg": "LU",
+	"switzerland": "CH", "austria": "AT", "spain": "ES", "portugal": "PT", "italy": "IT",
+	"denmark": "DK", "sweden": "SE", "norway": "NO", "finland": "FI", "estonia": "EE",
+	"latvia": "LV", "lithuania": "LT", "poland": "PL", "czechia": "CZ", "czech republic": "CZ",
+	"hungary": "HU", "romania": "RO", "bulgaria": "BG", "serbia": "RS", "croatia": "HR",
+	"slovenia": "SI", "greece": "GR", "turkey": "TR", "türkiye": "TR",
+	"ukraine": "UA", "украина": "UA", "belarus": "BY", "беларусь": "BY",
+	"russia": "RU", "россия": "RU", "kazakhstan": "KZ", "казахстан": "KZ",
+	"uzbekistan": "UZ", "georgia": "GE", "armenia": "AM", "azerbaijan": "AZ",
+	"israel": "IL", "united arab emirates": "AE", "uae": "AE", "saudi arabia": "SA",
+	"egypt": "EG", "nigeria": "NG", "kenya": "KE", "south africa": "ZA",
+	"india": "IN", "singapore": "SG", "malaysia": "MY", "thailand": "TH", "vietnam": "VN",
+	"indonesia": "ID", "philippines": "PH", "hong kong": "HK", "taiwan": "TW", "china": "CN",
+	"south korea": "KR", "korea": "KR", "japan": "JP", "australia": "AU", "new zealand": "NZ",
+}
+
+// Bundled returns a gazetteer loaded from the cities table shipped with
+// the binary.
+func Bundled() (*Gazetteer, error) {
+	return Load(bundledCities)
+}
+
+// Load parses a tab-separated table with columns name, aliases (separated
+// by "|"), region, country code, latitude and longitude. Lines starting
+// with "#" are comments.
+func Load(data []byte) (*Gazetteer, error) {
+	g := &Gazetteer{places: make(map[string][]Place)}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Split(text, "\t")
+		if len(fields) != 6 {
+			return nil, fmt.Errorf("gazetteer line %d: want 6 fields, got %d", line, len(fields))
+		}
+
+		lat, err := strconv.ParseFloat(fields[4], 64)
+		if err != nil {
+			return nil, fmt.Errorf("gazetteer line %d: invalid latitude: %w", line, err)
+		}
+		lon, err := strconv.ParseFloat(fields[5], 64)
+		if err != nil {
+			return nil, fmt.Errorf("gazetteer line %d: invalid longitude: %w", line, err)
+		}
+
+		place := Place{
+			City:        fields[0],
+			Region:      fields[2],
+			CountryCode: fields[3],
+			Point:       Point{Lat: lat, Lon: lon},
+		}
+
+		names := []string{fields[0]}
+		if fields[1] != "" {
+			names = append(names, strings.Split(fields[1], "|")...)
+		}
+		for _, name := range names {
+			key := normalizeName(name)
+			g.places[key] = append(g.places[key], place)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read gazetteer: %w", err)
+	}
+
+	return g, nil
+}
+
+// Lookup finds a city by name. When several cities share the name, hints
+// (region or country code) pick between them; otherwise the first entry in
+// the table wins.
+func (g *Gazetteer) Lookup(name string, hints ...string) (Place, bool) {
+	candidates := g.places[normalizeName(name)]
+	if len(candidates) == 0 {
+		return Place{}, false
+	}
+
+	for _, hint := range hints {
+		hint = strings.ToUpper(strings.TrimSpace(hint))
+		if hint == "" {
+			continue
+		}
+		for _, place := range candidates {
+			if place.CountryCode == hint || strings.ToUpper(place.Region) == hint {
+				return place, true
+			}
+		}
+	}
+
+	return candidates[0], true
+}
+
+// Resolve turns free-form location text such as "Austin, TX",
+// "Berlin, Germany" or "Remote - London" into structured fields and fills
+// in coordinates from the gazetteer. Fields already set on loc are kept.
+func (g *Gazetteer) Resolve(loc domain.Location) domain.Location {
+	text := strings.TrimSpace(loc.Raw)
+	lower := strings.ToLower(text)
+
+	for _, kw := range remoteKeywords {
+		if strings.Contains(lower, kw) {
+			loc.Remote = true
+			text = strings.TrimSpace(stripFold(text, kw))
+		}
+	}
+	text = strings.Trim(text, " -–—,()/|")
+
+	var parts []string
+	for _, part := range strings.Split(text, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	if len(parts) > 0 {
+		if loc.City == "" {
+			loc.City = parts[0]
+		}
+
+		rest := parts[1:]
+		if len(rest) > 0 && loc.CountryCode == "" {
+			if code, ok := countryCode(rest[len(rest)-1]); ok {
+				loc.CountryCode = code
+				rest = rest[:len(rest)-1]
+			}
+		}
+		if len(rest) > 0 && loc.Region == "" {
+			loc.Region = rest[0]
+		}
+	}
+
+	if loc.City != "" && (!loc.HasCoordinates() || loc.CountryCode == "") {
+		if place, ok := g.Lookup(loc.City, loc.CountryCode, loc.Region); ok {
+			loc.City = place.City
+			if !loc.HasCoordinates() {
+				loc.Latitude, loc.Longitude = place.Lat, place.Lon
+			}
+			if loc.Region == "" {
+				loc.Region = place.Region
+			}
+			if loc.CountryCode == "" {
+				loc.CountryCode = place.CountryCode
+			}
+		}
+	}
+
+	return loc
+}
+
+func countryCode(name string) (string, bool) {
+	if code, ok := countryCodes[strings.ToLower(name)]; ok {
+		return code, true
+	}
+	return "", false
+}
+
+func normalizeName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+// stripFold removes the first case-insensitive occurrence of substr.
+func stripFold(s, substr string) string {
+	i := strings.Index(strings.ToLower(s), substr)
+	if i < 0 {
+		return s
+	}
+	return s[:i] + s[i+len(substr):]
+}
@@ -0,0 +1,61 @@
+package geo
+
+import "math"
+
+const EarthRadiusKm = 6371.0
+
+type Point struct {
+	Lat float64
+	Lon float64
+}
+
+// Box is a latitude/longitude rectangle used to pre-filter rows in SQL
+// before the exact distance check.
+type Box struct {
+	MinLat, MaxLat float64
+	MinLon, MaxLon float64
+}
+
+// HaversineKm returns the great-circle distance between a and b.
+func HaversineKm(a, b Point) float64 {
+	lat1, lat2 := radians(a.Lat), radians(b.Lat)
+	dLat := lat2 - lat1
+	dLon := radians(b.Lon - a.Lon)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// BoundingBox returns a box that contains every point within radiusKm of
+// center. Near the poles or the antimeridian it widens to the full
+// longitude range instead of wrapping.
+func BoundingBox(center Point, radiusKm float64) Box {
+	dLat := degrees(radiusKm / EarthRadiusKm)
+
+	box := Box{
+		MinLat: math.Max(center.Lat-dLat, -90),
+		MaxLat: math.Min(center.Lat+dLat, 90),
+		MinLon: -180,
+		MaxLon: 180,
+	}
+
+	if box.MinLat > -90 && box.MaxLat < 90 {
+		dLon := degrees(math.Asin(math.Min(1, math.Sin(radiusKm/EarthRadiusKm)/math.Cos(radians(center.Lat)))))
+		if center.Lon-dLon >= -180 && center.Lon+dLon <= 180 {
+			box.MinLon = center.Lon - dLon
+			box.MaxLon = center.Lon + dLon
+		}
+	}
+
+	return box
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func degrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
@@ -0,0 +1,82 @@
+package geo
+
+import (
+	"htmxjb/models/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHaversineKm(t *testing.T) {
+	london := Point{Lat: 51.5074, Lon: -0.1278}
+	paris := Point{Lat: 48.8566, Lon: 2.3522}
+
+	assert.InDelta(t, 344, HaversineKm(london, paris), 2)
+	assert.Zero(t, HaversineKm(london, london))
+}
+
+func TestBoundingBoxContainsRadius(t *testing.T) {
+	center := Point{Lat: 52.52, Lon: 13.405}
+	box := BoundingBox(center, 100)
+
+	for _, bearing := range []Point{
+		{Lat: center.Lat + 0.89, Lon: center.Lon},
+		{Lat: center.Lat - 0.89, Lon: center.Lon},
+		{Lat: center.Lat, Lon: center.Lon + 1.46},
+		{Lat: center.Lat, Lon: center.Lon - 1.46},
+	} {
+		require.Less(t, HaversineKm(center, bearing), 100.0)
+		assert.True(t, bearing.Lat >= box.MinLat && bearing.Lat <= box.MaxLat, "lat %v outside box", bearing)
+		assert.True(t, bearing.Lon >= box.MinLon && bearing.Lon <= box.MaxLon, "lon %v outside box", bearing)
+	}
+
+	polar := BoundingBox(Point{Lat: 89.5, Lon: 0}, 100)
+	assert.Equal(t, -180.0, polar.MinLon)
+	assert.Equal(t, 180.0, polar.MaxLon)
+}
+
+func TestResolve(t *testing.T) {
+	g, err := Bundled()
+	require.NoError(t, err)
+
+	tests := []struct {
+		raw     string
+		city    string
+		region  string
+		country string
+		remote  bool
+		coords  bool
+	}{
+		{"Austin, TX", "Austin", "TX", "US", false, true},
+		{"Berlin, Germany", "Berlin", "BE", "DE", false, true},
+		{"Cambridge, UK", "Cambridge", "ENG", "GB", false, true},
+		{"Cambridge, MA, United States", "Cambridge", "MA", "US", false, true},
+		{"Remote - London", "London", "ENG", "GB", true, true},
+		{"Москва", "Moscow", "MOW", "RU", false, true},
+		{"Remote", "", "", "", true, false},
+		{"Smalltown, Nowhere", "Smalltown", "Nowhere", "", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			loc := g.Resolve(domain.Location{Raw: tt.raw})
+
+			assert.Equal(t, tt.city, loc.City)
+			assert.Equal(t, tt.region, loc.Region)
+			assert.Equal(t, tt.country, loc.CountryCode)
+			assert.Equal(t, tt.remote, loc.Remote)
+			assert.Equal(t, tt.coords, loc.HasCoordinates())
+		})
+	}
+}
+
+func TestResolveKeepsSourceCoordinates(t *testing.T) {
+	g, err := Bundled()
+	require.NoError(t, err)
+
+	loc := g.Resolve(domain.Location{Raw: "Austin, TX", Latitude: 30.3, Longitude: -97.7})
+
+	assert.Equal(t, 30.3, loc.Latitude)
+	assert.Equal(t, "US", loc.CountryCode)
+}
@@ -0,0 +1,286 @@
+package i18n
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Locale is a language the UI is shown in, as its ISO 639-1 code.
+type Locale string
+
+const (
+	English Locale = "en"
+	Russian Locale = "ru"
+)
+
+// Source is the language messages are written in. It needs no catalog:
+// untranslated messages are shown as written.
+const Source = English
+
+//go:embed locales/*.po
+var bundledLocales embed.FS
+
+// messages are the translations of one locale, by message id.
+type messages struct {
+	singular map[string]string
+	// plural holds the forms of messages that depend on a count, in the
+	// order of the locale's plural rule.
+	plural map[string][]string
+}
+
+// Catalog holds the translations of every supported locale.
+type Catalog struct {
+	locales map[Locale]*messages
+}
+
+// Bundled returns the catalog of the translations shipped with the binary,
+// one gettext PO file per locale.
+func Bundled() (*Catalog, error) {
+	files, err := bundledLocales.ReadDir("locales")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundled locales: %w", err)
+	}
+
+	c := &Catalog{locales: map[Locale]*messages{Source: {}}}
+	for _, f := range files {
+		data, err := bundledLocales.ReadFile("locales/" + f.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundled locales: %w", err)
+		}
+		locale := Locale(strings.TrimSuffix(f.Name(), path.Ext(f.Name())))
+		if err := c.Load(locale, data); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Load adds the translations of a gettext PO file for locale. Only what
+// the UI needs is understood: comments, msgid, msgid_plural, msgstr and
+// msgstr[n], each possibly continued over several quoted lines. The header
+// entry is skipped and untranslated entries are left out.
+func (c *Catalog) Load(locale Locale, data []byte) error {
+	if _, ok := pluralRules[locale]; !ok {
+		return fmt.Errorf("no plural rule for locale %q", locale)
+	}
+	m := c.locales[locale]
+	if m == nil {
+		m = &messages{}
+		c.locales[locale] = m
+	}
+	if m.singular == nil {
+		m.singular = make(map[string]string)
+		m.plural = make(map[string][]string)
+	}
+
+	var (
+		e    poEntry
+		last *string
+		line int
+	)
+	flush := func() error {
+		if err := e.addTo(m, pluralRules[locale].forms); err != nil {
+			return fmt.Errorf("%s.po line %d: %w", locale, line, err)
+		}
+		e, last = poEntry{}, nil
+		return nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(text, `"`) {
+			if last == nil {
+				return fmt.Errorf("%s.po line %d: string outside an entry", locale, line)
+			}
+			s, err := strconv.Unquote(text)
+			if err != nil {
+				return fmt.Errorf("%s.po line %d: %w", locale, line, err)
+			}
+			*last += s
+			continue
+		}
+
+		keyword, value, ok := strings.Cut(text, " ")
+		if !ok {
+			return fmt.Errorf("%s.po line %d: want a keyword and a string", locale, line)
+		}
+		s, err := strconv.Unquote(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("%s.po line %d: %w", locale, line, err)
+		}
+
+		switch {
+		case keyword == "msgid":
+			if e.started {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+			e.started = true
+			e.id = s
+			last = &e.id
+		case keyword == "msgid_plural":
+			e.idPlural = s
+			last = &e.idPlural
+		case keyword == "msgstr":
+			e.str = append(e.str, s)
+			last = &e.str[len(e.str)-1]
+		case strings.HasPrefix(keyword, "msgstr["):
+			n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(keyword, "msgstr["), "]"))
+			if err != nil || n != len(e.str) {
+				return fmt.Errorf("%s.po line %d: want msgstr[%d]", locale, line, len(e.str))
+			}
+			e.str = append(e.str, s)
+			last = &e.str[len(e.str)-1]
+		default:
+			return fmt.Errorf("%s.po line %d: unknown keyword %q", locale, line, keyword)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read %s.po: %w", locale, err)
+	}
+	if e.started {
+		return flush()
+	}
+	return nil
+}
+
+// poEntry is one message of a PO file as it is read.
+type poEntry struct {
+	started  bool
+	id       string
+	idPlural string
+	str      []string
+}
+
+func (e poEntry) addTo(m *messages, forms int) error {
+	if e.id == "" {
+		return nil
+	}
+	if e.idPlural == "" {
+		if len(e.str) != 1 {
+			return fmt.Errorf("want one msgstr for %q", e.id)
+		}
+		if e.str[0] != "" {
+			m.singular[e.id] = e.str[0]
+		}
+		return nil
+	}
+
+	if len(e.str) != forms {
+		return fmt.Errorf("want %d plural forms for %q, got %d", forms, e.id, len(e.str))
+	}
+	for _, s := range e.str {
+		if s == "" {
+			return nil
+		}
+	}
+	m.plural[e.id] = e.str
+	return nil
+}
+
+// Locales lists the locales the catalog can show, the source first.
+func (c *Catalog) Locales() []Locale {
+	locales := make([]Locale, 0, len(c.locales))
+	for locale := range c.locales {
+		if locale != Source {
+			locales = append(locales, locale)
+		}
+	}
+	sort.Slice(locales, func(i, j int) bool { return locales[i] < locales[j] })
+	return append([]Locale{Source}, locales...)
+}
+
+// Match finds the supported locale for a language tag such as "ru" or
+// "ru-RU".
+func (c *Catalog) Match(tag string) (Locale, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	_, ok := c.locales[Locale(tag)]
+	return Locale(tag), ok
+}
+
+// Negotiate picks the first supported locale among tags, in order of
+// preference, or the source language if there is none.
+func (c *Catalog) Negotiate(tags ...string) Locale {
+	for _, tag := range tags {
+		if locale, ok := c.Match(tag); ok {
+			return locale
+		}
+	}
+	return Source
+}
+
+// ParseAcceptLanguage lists the language tags of an Accept-Language header
+// from the most to the least preferred. Tags with q=0 are left out.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > 0 {
+			tags = append(tags, weighted{tag, q})
+		}
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+	names := make([]string, len(tags))
+	for i, t := range tags {
+		names[i] = t.tag
+	}
+	return names
+}
+
+// pluralRule picks which of a locale's forms a count takes.
+type pluralRule struct {
+	forms int
+	form  func(n int) int
+}
+
+// pluralRules follow the PO Plural-Forms headers of the bundled locales.
+var pluralRules = map[Locale]pluralRule{
+	English: {forms: 2, form: func(n int) int {
+		if n == 1 {
+			return 0
+		}
+		return 1
+	}},
+	Russian: {forms: 3, form: func(n int) int {
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return 0
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 10 || n%100 >= 20):
+			return 1
+		default:
+			return 2
+		}
+	}},
+}
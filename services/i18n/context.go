@@ -0,0 +1,92 @@
+package i18n
+
+import (
+	"context"
+	"htmxjb/models/domain"
+	"net/url"
+	"time"
+)
+
+type (
+	printerKey struct{}
+	queryKey   struct{}
+)
+
+// sourcePrinter shows messages as written when a request has no locale.
+var sourcePrinter = newPrinter(Source, &messages{})
+
+// NewContext returns a copy of ctx that carries p.
+func NewContext(ctx context.Context, p *Printer) context.Context {
+	return context.WithValue(ctx, printerKey{}, p)
+}
+
+// FromContext returns the printer of the request ctx belongs to, or one
+// for the source language.
+func FromContext(ctx context.Context) *Printer {
+	if p, ok := ctx.Value(printerKey{}).(*Printer); ok {
+		return p
+	}
+	return sourcePrinter
+}
+
+// WithQuery returns a copy of ctx that carries the query of the page being
+// shown, for SwitchURL to keep.
+func WithQuery(ctx context.Context, query url.Values) context.Context {
+	return context.WithValue(ctx, queryKey{}, query)
+}
+
+// The helpers below are for templates, which have the request's context
+// at hand as ctx.
+
+// T translates msgid for the request. See Printer.T.
+func T(ctx context.Context, msgid string, args ...interface{}) string {
+	return FromContext(ctx).T(msgid, args...)
+}
+
+// N translates the message for n things for the request. See Printer.N.
+func N(ctx context.Context, n int, singular, plural string, args ...interface{}) string {
+	return FromContext(ctx).N(n, singular, plural, args...)
+}
+
+// Lang is the request's locale, for the lang attribute of pages.
+func Lang(ctx context.Context) string {
+	return string(FromContext(ctx).Locale)
+}
+
+// SwitchURL links to the page being shown in locale, keeping its filters
+// and page number.
+func SwitchURL(ctx context.Context, locale Locale) string {
+	query := url.Values{}
+	if q, ok := ctx.Value(queryKey{}).(url.Values); ok {
+		for k, v := range q {
+			query[k] = v
+		}
+	}
+	query.Set("lang", string(locale))
+	return "?" + query.Encode()
+}
+
+func Number(ctx context.Context, v float64) string {
+	return FromContext(ctx).Number(v)
+}
+
+func Money(ctx context.Context, amount float64, currency string) string {
+	return FromContext(ctx).Money(amount, currency)
+}
+
+func Salary(ctx context.Context, s domain.Salary) string {
+	return FromContext(ctx).Salary(s)
+}
+
+func Date(ctx context.Context, t time.Time) string {
+	return FromContext(ctx).Date(t)
+}
+
+func DateTime(ctx context.Context, t time.Time) string {
+	return FromContext(ctx).DateTime(t)
+}
+
+// Posted says how long ago t was, as in "posted 3 days ago".
+func Posted(ctx context.Context, t time.Time) string {
+	return FromContext(ctx).Posted(t, time.Now())
+}
@@ -0,0 +1,295 @@
+package i18n
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"htmxjb/models/domain"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundled(t *testing.T) {
+	c, err := Bundled()
+	require.NoError(t, err)
+	assert.Equal(t, []Locale{English, Russian}, c.Locales())
+
+	ru := c.Printer(Russian)
+	assert.Equal(t, Russian, ru.Locale)
+	assert.Equal(t, "Вакансии", ru.T("Jobs"))
+	assert.Equal(t, "Отклик на вакансию Go dev", ru.T("Apply for %s", "Go dev"))
+	assert.Equal(t, "Not in the catalog", ru.T("Not in the catalog"))
+
+	en := c.Printer(English)
+	assert.Equal(t, "Jobs", en.T("Jobs"))
+	assert.Equal(t, English, c.Printer("de").Locale)
+}
+
+func TestLoad(t *testing.T) {
+	c := &Catalog{locales: map[Locale]*messages{Source: {}}}
+	err := c.Load(Russian, []byte(`
+msgid ""
+msgstr ""
+"Language: ru\n"
+
+# a comment
+msgid "Hello, "
+"%s"
+msgstr "Привет, "
+"%s"
+
+msgid "Untranslated"
+msgstr ""
+
+msgid "%d job"
+msgid_plural "%d jobs"
+msgstr[0] "%d вакансия"
+msgstr[1] "%d вакансии"
+msgstr[2] "%d вакансий"
+`))
+	require.NoError(t, err)
+
+	p := c.Printer(Russian)
+	assert.Equal(t, "Привет, Ann", p.T("Hello, %s", "Ann"))
+	assert.Equal(t, "Untranslated", p.T("Untranslated"))
+	assert.Equal(t, "3 вакансии", p.N(3, "%d job", "%d jobs"))
+
+	tests := map[string]string{
+		"unknown locale":    "",
+		"too few forms":     "msgid \"a\"\nmsgid_plural \"b\"\nmsgstr[0] \"c\"\nmsgstr[1] \"d\"\n",
+		"forms out of step": "msgid \"a\"\nmsgid_plural \"b\"\nmsgstr[1] \"c\"\n",
+		"bad quoting":       "msgid \"a\nmsgstr \"b\"\n",
+		"stray string":      "\"a\"\n",
+		"unknown keyword":   "msgctxt \"a\"\n",
+	}
+	for name, po := range tests {
+		t.Run(name, func(t *testing.T) {
+			locale := Russian
+			if name == "unknown locale" {
+				locale = "xx"
+			}
+			assert.Error(t, c.Load(locale, []byte(po)))
+		})
+	}
+}
+
+func TestN(t *testing.T) {
+	c, err := Bundled()
+	require.NoError(t, err)
+	ru, en := c.Printer(Russian), c.Printer(English)
+
+	tests := []struct {
+		n      int
+		ru, en string
+	}{
+		{0, "0 новых вакансий", "0 new jobs"},
+		{1, "1 новая вакансия", "1 new job"},
+		{2, "2 новые вакансии", "2 new jobs"},
+		{5, "5 новых вакансий", "5 new jobs"},
+		{11, "11 новых вакансий", "11 new jobs"},
+		{21, "21 новая вакансия", "21 new jobs"},
+		{22, "22 новые вакансии", "22 new jobs"},
+		{112, "112 новых вакансий", "112 new jobs"},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.n), func(t *testing.T) {
+			assert.Equal(t, tt.ru, ru.N(tt.n, "%d new job", "%d new jobs"))
+			assert.Equal(t, tt.en, en.N(tt.n, "%d new job", "%d new jobs"))
+		})
+	}
+
+	assert.Equal(t, "1 of 3 runs failed", en.N(3, "%[2]d of %[1]d run failed", "%[2]d of %[1]d runs failed", 1))
+	assert.Equal(t, "1 из 3 запусков с ошибкой", ru.N(3, "%[2]d of %[1]d run failed", "%[2]d of %[1]d runs failed", 1))
+}
+
+func TestNegotiate(t *testing.T) {
+	c, err := Bundled()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"ru-RU", "ru", "en-US"}, ParseAcceptLanguage("en-US;q=0.5, ru-RU, ru;q=0.9, de;q=0, *;q=0.1"))
+	assert.Empty(t, ParseAcceptLanguage(""))
+
+	assert.Equal(t, Russian, c.Negotiate("", "de", "ru-RU"))
+	assert.Equal(t, Russian, c.Negotiate("RU_ru"))
+	assert.Equal(t, English, c.Negotiate("en-GB", "ru"))
+	assert.Equal(t, Source, c.Negotiate("de", "fr"))
+	assert.Equal(t, Source, c.Negotiate())
+}
+
+func TestNumbers(t *testing.T) {
+	c, err := Bundled()
+	require.NoError(t, err)
+	ru, en := c.Printer(Russian), c.Printer(English)
+
+	assert.Equal(t, "1,234,567", en.Number(1234567))
+	assert.Equal(t, "1\u00a0234\u00a0567", ru.Number(1234567))
+	assert.Equal(t, "1.5", en.Number(1.5))
+	assert.Equal(t, "1,5", ru.Number(1.5))
+	assert.Equal(t, "-999", en.Number(-999))
+
+	assert.Equal(t, "$99", en.Money(99, "usd"))
+	assert.Equal(t, "$1,234.50", en.Money(1234.5, "USD"))
+	assert.Equal(t, "1\u00a0234,50\u00a0$", ru.Money(1234.5, "USD"))
+	assert.Equal(t, "500\u00a0CHF", en.Money(500, "CHF"))
+	assert.Equal(t, "500", en.Money(500, ""))
+}
+
+func TestSalary(t *testing.T) {
+	c, err := Bundled()
+	require.NoError(t, err)
+	ru, en := c.Printer(Russian), c.Printer(English)
+
+	tests := []struct {
+		name   string
+		salary domain.Salary
+		en, ru string
+	}{
+		{
+			name:   "range",
+			salary: domain.Salary{Min: 60000, Max: 75000, Currency: "EUR", Period: domain.Yearly},
+			en:     "€60,000 – €75,000 a year",
+			ru:     "60\u00a0000\u00a0€ – 75\u00a0000\u00a0€ в год",
+		},
+		{
+			name:   "single amount",
+			salary: domain.Salary{Min: 40, Max: 40, Currency: "USD", Period: domain.Hourly},
+			en:     "$40 an hour",
+			ru:     "40\u00a0$ в час",
+		},
+		{
+			name:   "from",
+			salary: domain.Salary{Min: 5000, Currency: "GBP", Period: domain.Monthly},
+			en:     "from £5,000 a month",
+			ru:     "от 5\u00a0000\u00a0£ в месяц",
+		},
+		{
+			name:   "up to",
+			salary: domain.Salary{Max: 300000, Currency: "RUB"},
+			en:     "up to ₽300,000",
+			ru:     "до 300\u00a0000\u00a0₽",
+		},
+		{
+			name:   "unparsed",
+			salary: domain.Salary{Raw: "Competitive"},
+			en:     "Competitive",
+			ru:     "Competitive",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.en, en.Salary(tt.salary))
+			assert.Equal(t, tt.ru, ru.Salary(tt.salary))
+		})
+	}
+}
+
+func TestDates(t *testing.T) {
+	c, err := Bundled()
+	require.NoError(t, err)
+	ru, en := c.Printer(Russian), c.Printer(English)
+
+	at := time.Date(2024, time.May, 2, 9, 5, 0, 0, time.Local)
+	assert.Equal(t, "2 May 2024", en.Date(at))
+	assert.Equal(t, "2 мая 2024", ru.Date(at))
+	assert.Equal(t, "2 мая 2024 09:05", ru.DateTime(at))
+
+	tests := []struct {
+		ago    time.Duration
+		en, ru string
+	}{
+		{30 * time.Second, "posted just now", "опубликована только что"},
+		{time.Minute, "posted 1 minute ago", "опубликована 1 минуту назад"},
+		{5 * time.Hour, "posted 5 hours ago", "опубликована 5 часов назад"},
+		{3 * 24 * time.Hour, "posted 3 days ago", "опубликована 3 дня назад"},
+		{61 * 24 * time.Hour, "posted 2 months ago", "опубликована 2 месяца назад"},
+		{800 * 24 * time.Hour, "posted 2 years ago", "опубликована 2 года назад"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ago.String(), func(t *testing.T) {
+			assert.Equal(t, tt.en, en.Posted(at.Add(-tt.ago), at))
+			assert.Equal(t, tt.ru, ru.Posted(at.Add(-tt.ago), at))
+		})
+	}
+	assert.Empty(t, en.Posted(time.Time{}, at))
+}
+
+func TestError(t *testing.T) {
+	c, err := Bundled()
+	require.NoError(t, err)
+	ru := c.Printer(Russian)
+
+	notFound := errors.New("job not found")
+	assert.Equal(t, "вакансия не найдена", ru.Error(notFound))
+	assert.Equal(t, "вакансия не найдена", ru.Error(fmt.Errorf("failed to get job 7: %w", notFound)))
+	assert.Equal(t, "disk full", ru.Error(errors.New("disk full")))
+}
+
+func TestContext(t *testing.T) {
+	c, err := Bundled()
+	require.NoError(t, err)
+
+	assert.Equal(t, "en", Lang(context.Background()))
+	assert.Equal(t, "Jobs", T(context.Background(), "Jobs"))
+
+	ctx := NewContext(context.Background(), c.Printer(Russian))
+	assert.Equal(t, "ru", Lang(ctx))
+	assert.Equal(t, "Вакансии", T(ctx, "Jobs"))
+	assert.Equal(t, "2 отклика", N(ctx, 2, "%d applicant", "%d applicants"))
+
+	assert.Equal(t, "?lang=ru", SwitchURL(ctx, Russian))
+	query := url.Values{"q": {"go & rust"}, "page": {"3"}, "lang": {"ru"}}
+	ctx = WithQuery(ctx, query)
+	assert.Equal(t, "?lang=en&page=3&q=go+%26+rust", SwitchURL(ctx, English), "filters and page are kept")
+	assert.Equal(t, "ru", query.Get("lang"), "the request's query is left alone")
+}
+
+// TestCatalogsComplete checks every message the templates and handlers
+// pass to T or N literally has a translation in each bundled locale.
+func TestCatalogsComplete(t *testing.T) {
+	c, err := Bundled()
+	require.NoError(t, err)
+
+	str := `"((?:[^"\\]|\\.)*)"`
+	singular := regexp.MustCompile(`\bT\((?:ctx, )?` + str)
+	plural := regexp.MustCompile(`\bN\((?:ctx, )?[^,()]+(?:\([^()]*\))?, ` + str)
+
+	var files []string
+	for _, pattern := range []string{"../../views/*/*.templ", "../../handlers/*.go", "*.go"} {
+		matches, err := filepath.Glob(pattern)
+		require.NoError(t, err)
+		files = append(files, matches...)
+	}
+	require.NotEmpty(t, files)
+
+	for _, locale := range c.Locales() {
+		if locale == Source {
+			continue
+		}
+		m := c.locales[locale]
+		for _, name := range files {
+			if filepath.Base(name) == "i18n_test.go" {
+				continue
+			}
+			data, err := os.ReadFile(name)
+			require.NoError(t, err)
+			for _, match := range singular.FindAllStringSubmatch(string(data), -1) {
+				id, err := strconv.Unquote(`"` + match[1] + `"`)
+				require.NoError(t, err)
+				assert.Contains(t, m.singular, id, "%s: %s", locale, name)
+			}
+			for _, match := range plural.FindAllStringSubmatch(string(data), -1) {
+				id, err := strconv.Unquote(`"` + match[1] + `"`)
+				require.NoError(t, err)
+				assert.Contains(t, m.plural, id, "%s: %s", locale, name)
+			}
+		}
+	}
+}
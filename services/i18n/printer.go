@@ -0,0 +1,221 @@
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"htmxjb/models/domain"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Printer writes messages, numbers and dates for one locale.
+type Printer struct {
+	Locale   Locale
+	messages *messages
+	plural   pluralRule
+	format   numberFormat
+}
+
+// numberFormat is how a locale writes numbers and prices.
+type numberFormat struct {
+	group   string
+	decimal string
+	// symbolFirst puts the currency symbol before the amount, as in $100,
+	// rather than after it.
+	symbolFirst bool
+}
+
+var numberFormats = map[Locale]numberFormat{
+	English: {group: ",", decimal: ".", symbolFirst: true},
+	Russian: {group: "\u00a0", decimal: ",", symbolFirst: false},
+}
+
+// currencySymbols replace the codes of common currencies in prices.
+var currencySymbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"RUB": "₽",
+	"INR": "₹",
+	"JPY": "¥",
+}
+
+// Printer returns the printer for locale, or for the source language if
+// the catalog does not have it.
+func (c *Catalog) Printer(locale Locale) *Printer {
+	m, ok := c.locales[locale]
+	if !ok {
+		locale, m = Source, c.locales[Source]
+	}
+	return newPrinter(locale, m)
+}
+
+func newPrinter(locale Locale, m *messages) *Printer {
+	return &Printer{
+		Locale:   locale,
+		messages: m,
+		plural:   pluralRules[locale],
+		format:   numberFormats[locale],
+	}
+}
+
+// T translates msgid. With args, the translation is a format for them.
+func (p *Printer) T(msgid string, args ...interface{}) string {
+	s, ok := p.messages.singular[msgid]
+	if !ok {
+		s = msgid
+	}
+	if len(args) == 0 {
+		return s
+	}
+	return fmt.Sprintf(s, args...)
+}
+
+// N translates the message for n things, written in the source language
+// as singular and plural. The translation is a format for n followed by
+// args.
+func (p *Printer) N(n int, singular, plural string, args ...interface{}) string {
+	s := plural
+	if forms, ok := p.messages.plural[singular]; ok {
+		s = forms[p.plural.form(n)]
+	} else if pluralRules[Source].form(n) == 0 {
+		s = singular
+	}
+	return fmt.Sprintf(s, append([]interface{}{n}, args...)...)
+}
+
+// Error translates the message of err, or else of the first error it
+// wraps that has a translation. Errors without one are shown as they are.
+func (p *Printer) Error(err error) string {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if s, ok := p.messages.singular[e.Error()]; ok {
+			return s
+		}
+	}
+	return err.Error()
+}
+
+// Number writes v with thousands separators and at most two decimals.
+func (p *Printer) Number(v float64) string {
+	return p.number(v, true)
+}
+
+// number writes v with thousands separators. Fractions get two decimals,
+// with trailing zeros dropped if trim is set.
+func (p *Printer) number(v float64, trim bool) string {
+	negative := v < 0
+	if negative {
+		v = -v
+	}
+	whole, fraction, _ := strings.Cut(strconv.FormatFloat(v, 'f', 2, 64), ".")
+	if trim || fraction == "00" {
+		fraction = strings.TrimRight(fraction, "0")
+	}
+
+	var b strings.Builder
+	if negative {
+		b.WriteByte('-')
+	}
+	for i, d := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(p.format.group)
+		}
+		b.WriteRune(d)
+	}
+	if fraction != "" {
+		b.WriteString(p.format.decimal + fraction)
+	}
+	return b.String()
+}
+
+// Money writes an amount in currency, an ISO 4217 code. Amounts with
+// cents show both decimals.
+func (p *Printer) Money(amount float64, currency string) string {
+	number := p.number(amount, false)
+	if currency == "" {
+		return number
+	}
+	symbol, ok := currencySymbols[strings.ToUpper(currency)]
+	switch {
+	case ok && p.format.symbolFirst:
+		return symbol + number
+	case ok:
+		return number + "\u00a0" + symbol
+	default:
+		return number + "\u00a0" + strings.ToUpper(currency)
+	}
+}
+
+// Salary writes a pay range with its period. Salaries that could not be
+// parsed are shown as advertised.
+func (p *Printer) Salary(s domain.Salary) string {
+	var amount string
+	switch {
+	case s.Min > 0 && s.Max > 0 && s.Min != s.Max:
+		amount = p.Money(s.Min, s.Currency) + " – " + p.Money(s.Max, s.Currency)
+	case s.Min > 0 && s.Max > 0:
+		amount = p.Money(s.Min, s.Currency)
+	case s.Min > 0:
+		amount = p.T("from %s", p.Money(s.Min, s.Currency))
+	case s.Max > 0:
+		amount = p.T("up to %s", p.Money(s.Max, s.Currency))
+	default:
+		return s.Raw
+	}
+
+	switch s.Period {
+	case domain.Hourly:
+		return amount + " " + p.T("an hour")
+	case domain.Daily:
+		return amount + " " + p.T("a day")
+	case domain.Weekly:
+		return amount + " " + p.T("a week")
+	case domain.Monthly:
+		return amount + " " + p.T("a month")
+	case domain.Yearly:
+		return amount + " " + p.T("a year")
+	default:
+		return amount
+	}
+}
+
+var months = [...]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+
+// Date writes the day of t in the local time zone, such as 2 Jan 2006.
+func (p *Printer) Date(t time.Time) string {
+	t = t.Local()
+	return strconv.Itoa(t.Day()) + " " + p.T(months[t.Month()-1]) + " " + strconv.Itoa(t.Year())
+}
+
+// DateTime writes t to the minute in the local time zone.
+func (p *Printer) DateTime(t time.Time) string {
+	return p.Date(t) + " " + t.Local().Format("15:04")
+}
+
+// Posted says how long before now a job was listed, as in "posted 3 days
+// ago". It is empty if the time is not known.
+func (p *Printer) Posted(t, now time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	d := now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return p.T("posted just now")
+	case d < time.Hour:
+		return p.N(int(d/time.Minute), "posted %d minute ago", "posted %d minutes ago")
+	case d < 24*time.Hour:
+		return p.N(int(d/time.Hour), "posted %d hour ago", "posted %d hours ago")
+	}
+
+	days := int(d / (24 * time.Hour))
+	switch {
+	case days < 30:
+		return p.N(days, "posted %d day ago", "posted %d days ago")
+	case days < 365:
+		return p.N(days/30, "posted %d month ago", "posted %d months ago")
+	default:
+		return p.N(days/365, "posted %d year ago", "posted %d years ago")
+	}
+}
@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"htmxjb/models/domain"
+	"htmxjb/services/events"
+	"log"
+	"strings"
+	"time"
+)
+
+// JobFetcher is implemented by every job source client. FetchJobs returns
+// the current postings of the source, all of them unless the fetcher is a
+// PartialFetcher.
+type JobFetcher interface {
+	Source() domain.JobSource
+	FetchJobs(ctx context.Context) ([]domain.Job, error)
+}
+
+// PartialFetcher is implemented by fetchers that may return only some of
+// a source's live postings, such as the ones from a date window or the
+// first pages of a search. Complete says whether their fetches return all
+// of them. Jobs missing from an incomplete fetch may still be live, so
+// they are left to expire by TTL rather than closed.
+type PartialFetcher interface {
+	JobFetcher
+	Complete() bool
+}
+
+// fetchesAll says whether f returns every live posting of its source.
+func fetchesAll(f JobFetcher) bool {
+	partial, ok := f.(PartialFetcher)
+	return !ok || partial.Complete()
+}
+
+// CombinedFetcher runs several fetchers for one source, such as one per
+// search profile, as a single fetch. A job returned by more than one is
+// kept once. If any fetcher fails the whole fetch fails, so jobs only the
+// failed one would have returned are not closed.
+type CombinedFetcher struct {
+	source   domain.JobSource
+	fetchers []JobFetcher
+}
+
+func CombineFetchers(source domain.JobSource, fetchers ...JobFetcher) *CombinedFetcher {
+	return &CombinedFetcher{
+		source:   source,
+		fetchers: fetchers,
+	}
+}
+
+func (cf *CombinedFetcher) Source() domain.JobSource {
+	return cf.source
+}
+
+// Complete says whether every combined fetcher returns all its postings.
+func (cf *CombinedFetcher) Complete() bool {
+	for _, fetcher := range cf.fetchers {
+		if !fetchesAll(fetcher) {
+			return false
+		}
+	}
+	return true
+}
+
+func (cf *CombinedFetcher) FetchJobs(ctx context.Context) ([]domain.Job, error) {
+	var jobs []domain.Job
+	seen := make(map[string]bool)
+
+	for _, fetcher := range cf.fetchers {
+		fetched, err := fetcher.FetchJobs(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, job := range fetched {
+			if seen[job.ExternalID] {
+				continue
+			}
+			seen[job.ExternalID] = true
+			jobs = append(jobs, job)
+		}
+	}
+
+	return jobs, nil
+}
+
+type Ingestor struct {
+	Jobs       *JobServices
+	Retention  *RetentionService
+	History    *RunHistory
+	Moderation ModerationRules
+	Spam       *SpamFilter
+	Companies  *CompanyService
+	Events     *events.Bus
+	Enrichers  []JobEnricher
+	Fetchers   []JobFetcher
+}
+
+func NewIngestor(jobs *JobServices, retention *RetentionService, enrichers []JobEnricher, fetchers ...JobFetcher) *Ingestor {
+	return &Ingestor{
+		Jobs:      jobs,
+		Retention: retention,
+		Enrichers: enrichers,
+		Fetchers:  fetchers,
+	}
+}
+
+// WithHistory records every run in h for the source health dashboard.
+func (in *Ingestor) WithHistory(h *RunHistory) *Ingestor {
+	in.History = h
+	return in
+}
+
+// WithModeration holds new jobs for review as rules decide.
+func (in *Ingestor) WithModeration(rules ModerationRules) *Ingestor {
+	in.Moderation = rules
+	return in
+}
+
+// WithSpamFilter scores new jobs and holds back or rejects likely spam.
+func (in *Ingestor) WithSpamFilter(sf *SpamFilter) *Ingestor {
+	in.Spam = sf
+	return in
+}
+
+// WithCompanies links every stored job to its company profile.
+func (in *Ingestor) WithCompanies(cs *CompanyService) *Ingestor {
+	in.Companies = cs
+	return in
+}
+
+// WithEvents announces newly listed jobs on bus.
+func (in *Ingestor) WithEvents(bus *events.Bus) *Ingestor {
+	in.Events = bus
+	return in
+}
+
+// RunOnce does a full ingest of every source. Jobs that a complete source
+// no longer returns are closed; a source that fails to fetch is left
+// untouched.
+func (in *Ingestor) RunOnce(ctx context.Context) error {
+	var errs []error
+
+	for _, fetcher := range in.Fetchers {
+		if err := in.ingest(ctx, fetcher); err != nil {
+			log.Printf("🔥 ingest of %s failed: %s", fetcher.Source(), err)
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (in *Ingestor) ingest(ctx context.Context, fetcher JobFetcher) error {
+	run := &IngestionRun{Source: fetcher.Source(), StartedAt: in.Retention.Now()}
+	if in.History != nil {
+		if err := in.History.Start(run); err != nil {
+			log.Printf("🔥 %s", err)
+		}
+	}
+
+	err := in.store(ctx, fetcher, run)
+
+	run.FinishedAt = in.Retention.Now()
+	run.Status = RunSucceeded
+	if err != nil {
+		run.Status = RunFailed
+		run.Error = err.Error()
+	}
+	if in.History != nil && run.ID != 0 {
+		if err := in.History.Finish(run); err != nil {
+			log.Printf("🔥 %s", err)
+		}
+	}
+
+	return err
+}
+
+// store fetches the source's jobs, upserts the valid ones and, if the
+// fetch was complete, closes the ones that are gone, counting each outcome
+// in run.
+func (in *Ingestor) store(ctx context.Context, fetcher JobFetcher, run *IngestionRun) error {
+	source := fetcher.Source()
+
+	jobs, err := fetcher.FetchJobs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s jobs: %w", source, err)
+	}
+	run.Fetched = len(jobs)
+
+	known, err := in.Jobs.ExternalIDs(source)
+	if err != nil {
+		return err
+	}
+
+	ttl := in.Retention.Policy.TTLFor(source)
+	for i := range jobs {
+		job := &jobs[i]
+		if reason := rejectReason(job); reason != "" {
+			run.reject(*job, reason)
+			continue
+		}
+
+		job.Source = source
+		job.LastSeenAt = in.Retention.Now()
+		if ttl > 0 {
+			job.ExpiresAt = job.LastSeenAt.Add(ttl)
+		}
+		for _, enricher := range in.Enrichers {
+			enricher.Enrich(job)
+		}
+		job.Status = in.Moderation.StatusFor(*job)
+		if in.Spam != nil && !in.Moderation.Trusted(*job) {
+			in.Spam.Screen(job)
+		}
+		// Rejected spam does not get a company profile.
+		if in.Companies != nil && job.Status != domain.Rejected {
+			if err := in.Companies.Link(job); err != nil {
+				return err
+			}
+		}
+
+		if err := in.Jobs.Upsert(job); err != nil {
+			return err
+		}
+
+		if known[job.ExternalID] {
+			run.Updated++
+		} else {
+			run.Added++
+			known[job.ExternalID] = true
+			if in.Events != nil && job.Status == domain.Active {
+				in.Events.Publish(events.JobCreated{JobID: int(job.ID)})
+			}
+		}
+	}
+
+	var closed int64
+	if fetchesAll(fetcher) {
+		closed, err = in.Retention.CloseMissing(source, run.StartedAt)
+		if err != nil {
+			return err
+		}
+	}
+	run.Closed = int(closed)
+
+	if run.Rejected > 0 {
+		log.Printf("🔥 Rejected %d %s jobs", run.Rejected, source)
+	}
+	log.Printf("✅ Ingested %d %s jobs, closed %d", run.Added+run.Updated, source, closed)
+
+	return nil
+}
+
+// rejectReason says why a fetched job cannot be stored, or is empty if it
+// can.
+func rejectReason(job *domain.Job) string {
+	switch {
+	case strings.TrimSpace(job.ExternalID) == "":
+		return "missing external id"
+	case strings.TrimSpace(job.Title) == "":
+		return "missing title"
+	}
+	return ""
+}
+
+// Run ingests every interval until ctx is cancelled.
+func (in *Ingestor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			in.RunOnce(ctx)
+		}
+	}
+}
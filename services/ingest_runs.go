@@ -0,0 +1,344 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"htmxjb/db"
+	"htmxjb/models/domain"
+	"time"
+)
+
+type RunStatus string
+
+const (
+	RunRunning   RunStatus = "running"
+	RunSucceeded RunStatus = "succeeded"
+	RunFailed    RunStatus = "failed"
+)
+
+// rejectSample is how many rejected records a run keeps for inspection.
+// The rest are only counted.
+const rejectSample = 20
+
+var ErrRunNotFound = errors.New("ingestion run not found")
+
+// IngestionRun is one ingest of one source.
+type IngestionRun struct {
+	ID         int64
+	Source     domain.JobSource
+	Status     RunStatus
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Fetched    int
+	Added      int
+	Updated    int
+	Closed     int
+	Rejected   int
+	Error      string
+
+	// Rejects is a sample of the records the run skipped.
+	Rejects []RejectedRecord
+}
+
+// RejectedRecord is a fetched job that was not stored, with the reason
+// and the job as the source client mapped it.
+type RejectedRecord struct {
+	ExternalID string
+	Title      string
+	Reason     string
+	Payload    string
+}
+
+func (r IngestionRun) Duration() time.Duration {
+	if r.FinishedAt.IsZero() {
+		return 0
+	}
+	return r.FinishedAt.Sub(r.StartedAt)
+}
+
+// reject counts a skipped job, keeping it if the sample has room.
+func (r *IngestionRun) reject(job domain.Job, reason string) {
+	r.Rejected++
+	if len(r.Rejects) >= rejectSample {
+		return
+	}
+
+	payload, _ := json.MarshalIndent(job, "", "  ")
+	r.Rejects = append(r.Rejects, RejectedRecord{
+		ExternalID: job.ExternalID,
+		Title:      job.Title,
+		Reason:     reason,
+		Payload:    string(payload),
+	})
+}
+
+// SourceHealth summarises a source's recent ingestion runs.
+type SourceHealth struct {
+	Source      domain.JobSource
+	LastRun     IngestionRun
+	LastSuccess time.Time
+	Runs        int
+	Failures    int
+	Added       []DailyCount
+}
+
+// ErrorRate is the share of failed runs in the health window.
+func (h SourceHealth) ErrorRate() float64 {
+	if h.Runs == 0 {
+		return 0
+	}
+	return float64(h.Failures) / float64(h.Runs)
+}
+
+// DailyCount is how many jobs a source added on one UTC day.
+type DailyCount struct {
+	Day   time.Time
+	Count int
+}
+
+// RunHistory stores the outcome of every ingestion run.
+type RunHistory struct {
+	JobStore db.Store
+}
+
+func NewRunHistory(jobStore db.Store) *RunHistory {
+	return &RunHistory{
+		JobStore: jobStore,
+	}
+}
+
+// Start records a run as running and sets its ID.
+func (rh *RunHistory) Start(run *IngestionRun) error {
+	run.Status = RunRunning
+
+	res, err := rh.JobStore.Exec(
+		"INSERT INTO ingestion_runs (source, status, started_at) VALUES (?, ?, ?)",
+		run.Source,
+		run.Status,
+		sqlTime(run.StartedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to start %s run: %w", run.Source, err)
+	}
+
+	run.ID, err = res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to start %s run: %w", run.Source, err)
+	}
+
+	return nil
+}
+
+// Finish stores the run's outcome and its sample of rejected records.
+func (rh *RunHistory) Finish(run *IngestionRun) error {
+	_, err := rh.JobStore.Exec(`
+    UPDATE ingestion_runs
+    SET status = ?, finished_at = ?, fetched = ?, added = ?, updated = ?, closed = ?, rejected = ?, error = ?
+    WHERE id = ?
+  `,
+		run.Status,
+		sqlTime(run.FinishedAt),
+		run.Fetched,
+		run.Added,
+		run.Updated,
+		run.Closed,
+		run.Rejected,
+		nullString(run.Error),
+		run.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to finish run %d: %w", run.ID, err)
+	}
+
+	for _, r := range run.Rejects {
+		_, err := rh.JobStore.Exec(
+			"INSERT INTO ingestion_rejects (run_id, external_id, title, reason, payload) VALUES (?, ?, ?, ?, ?)",
+			run.ID,
+			nullString(r.ExternalID),
+			nullString(r.Title),
+			r.Reason,
+			nullString(r.Payload),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to record rejected job of run %d: %w", run.ID, err)
+		}
+	}
+
+	return nil
+}
+
+const runColumns = "id, source, status, started_at, finished_at, fetched, added, updated, closed, rejected, error"
+
+func scanRun(row interface{ Scan(...interface{}) error }) (IngestionRun, error) {
+	var (
+		run        IngestionRun
+		finishedAt sql.NullTime
+		runErr     sql.NullString
+	)
+	err := row.Scan(
+		&run.ID, &run.Source, &run.Status, &run.StartedAt, &finishedAt,
+		&run.Fetched, &run.Added, &run.Updated, &run.Closed, &run.Rejected, &runErr,
+	)
+	if err != nil {
+		return IngestionRun{}, err
+	}
+
+	run.FinishedAt = finishedAt.Time
+	run.Error = runErr.String
+
+	return run, nil
+}
+
+// Recent returns the latest runs of every source, newest first.
+func (rh *RunHistory) Recent(limit int) ([]IngestionRun, error) {
+	rows, err := rh.JobStore.Query("SELECT "+runColumns+" FROM ingestion_runs ORDER BY started_at DESC, id DESC LIMIT ?", limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ingestion runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []IngestionRun
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan ingestion run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating ingestion runs: %w", err)
+	}
+
+	return runs, nil
+}
+
+// Run returns a run with its rejected records.
+func (rh *RunHistory) Run(id int64) (IngestionRun, error) {
+	run, err := scanRun(rh.JobStore.QueryRow("SELECT "+runColumns+" FROM ingestion_runs WHERE id = ?", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return IngestionRun{}, ErrRunNotFound
+	}
+	if err != nil {
+		return IngestionRun{}, fmt.Errorf("failed to get ingestion run %d: %w", id, err)
+	}
+
+	rows, err := rh.JobStore.Query(
+		"SELECT external_id, title, reason, payload FROM ingestion_rejects WHERE run_id = ? ORDER BY id",
+		id,
+	)
+	if err != nil {
+		return IngestionRun{}, fmt.Errorf("failed to get rejected jobs of run %d: %w", id, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var externalID, title, payload sql.NullString
+		var r RejectedRecord
+		if err := rows.Scan(&externalID, &title, &r.Reason, &payload); err != nil {
+			return IngestionRun{}, fmt.Errorf("failed to scan rejected job: %w", err)
+		}
+		r.ExternalID, r.Title, r.Payload = externalID.String, title.String, payload.String
+		run.Rejects = append(run.Rejects, r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return IngestionRun{}, fmt.Errorf("error iterating rejected jobs: %w", err)
+	}
+
+	return run, nil
+}
+
+// Health summarises every source that has run, ordered by source. Error
+// rates and daily counts cover runs started from since to now; the last
+// run and last success look at all history.
+func (rh *RunHistory) Health(since, now time.Time) ([]SourceHealth, error) {
+	rows, err := rh.JobStore.Query(`
+    SELECT r.source,
+      (SELECT MAX(s.finished_at) FROM ingestion_runs s WHERE s.source = r.source AND s.status = ?),
+      SUM(CASE WHEN r.started_at >= ? THEN 1 ELSE 0 END),
+      SUM(CASE WHEN r.started_at >= ? AND r.status = ? THEN 1 ELSE 0 END)
+    FROM ingestion_runs r
+    GROUP BY r.source
+    ORDER BY r.source
+  `, RunSucceeded, sqlTime(since), sqlTime(since), RunFailed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source health: %w", err)
+	}
+	defer rows.Close()
+
+	var health []SourceHealth
+	for rows.Next() {
+		var h SourceHealth
+		var lastSuccess sql.NullString
+		if err := rows.Scan(&h.Source, &lastSuccess, &h.Runs, &h.Failures); err != nil {
+			return nil, fmt.Errorf("failed to scan source health: %w", err)
+		}
+		// MAX() loses the column type, so the driver returns text.
+		if lastSuccess.Valid {
+			h.LastSuccess, _ = time.Parse(sqlTimeLayout, lastSuccess.String)
+		}
+		health = append(health, h)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating source health: %w", err)
+	}
+	rows.Close()
+
+	for i := range health {
+		h := &health[i]
+
+		h.LastRun, err = scanRun(rh.JobStore.QueryRow(
+			"SELECT "+runColumns+" FROM ingestion_runs WHERE source = ? ORDER BY started_at DESC, id DESC LIMIT 1",
+			h.Source,
+		))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get last %s run: %w", h.Source, err)
+		}
+
+		h.Added, err = rh.dailyAdded(h.Source, since, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return health, nil
+}
+
+// dailyAdded returns the jobs source added per day from since to now, with
+// a zero count for days without runs.
+func (rh *RunHistory) dailyAdded(source domain.JobSource, since, now time.Time) ([]DailyCount, error) {
+	rows, err := rh.JobStore.Query(`
+    SELECT date(started_at), SUM(added)
+    FROM ingestion_runs
+    WHERE source = ? AND started_at >= ?
+    GROUP BY date(started_at)
+  `, source, sqlTime(since))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get jobs added by %s: %w", source, err)
+	}
+	defer rows.Close()
+
+	added := make(map[string]int)
+	for rows.Next() {
+		var day string
+		var count int
+		if err := rows.Scan(&day, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan jobs added: %w", err)
+		}
+		added[day] = count
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating jobs added: %w", err)
+	}
+
+	var counts []DailyCount
+	last := now.UTC().Truncate(24 * time.Hour)
+	for day := since.UTC().Truncate(24 * time.Hour); !day.After(last); day = day.AddDate(0, 0, 1) {
+		counts = append(counts, DailyCount{Day: day, Count: added[day.Format("2006-01-02")]})
+	}
+
+	return counts, nil
+}
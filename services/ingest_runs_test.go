@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"htmxjb/models/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunHistory(t *testing.T) {
+	store := openTestStore(t)
+	clk := &clock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+
+	retention := NewRetentionService(store, RetentionPolicy{})
+	retention.Now = clk.Now
+	history := NewRunHistory(store)
+
+	fetcher := &stubFetcher{
+		source: domain.Indeed,
+		jobs: []domain.Job{
+			{ExternalID: "a", Title: "Go Developer"},
+			{ExternalID: "b", Title: "  "},
+			{Title: "Rust Developer"},
+		},
+	}
+	ingestor := NewIngestor(NewJobServices(Job{}, store), retention, nil, fetcher).WithHistory(history)
+
+	require.NoError(t, ingestor.RunOnce(context.Background()))
+
+	clk.Advance(24 * time.Hour)
+	fetcher.jobs = []domain.Job{
+		{ExternalID: "a", Title: "Go Developer"},
+		{ExternalID: "c", Title: "Python Developer"},
+		{ExternalID: "d", Title: "Java Developer"},
+	}
+	require.NoError(t, ingestor.RunOnce(context.Background()))
+
+	clk.Advance(time.Hour)
+	fetcher.err = errors.New("indeed returned 503")
+	require.Error(t, ingestor.RunOnce(context.Background()))
+
+	runs, err := history.Recent(10)
+	require.NoError(t, err)
+	require.Len(t, runs, 3)
+
+	failed, second, first := runs[0], runs[1], runs[2]
+
+	assert.Equal(t, RunFailed, failed.Status)
+	assert.Contains(t, failed.Error, "indeed returned 503")
+	assert.Equal(t, clk.Now(), failed.StartedAt)
+
+	assert.Equal(t, RunSucceeded, second.Status)
+	assert.Equal(t, 3, second.Fetched)
+	assert.Equal(t, 2, second.Added)
+	assert.Equal(t, 1, second.Updated)
+	assert.Equal(t, 0, second.Rejected)
+
+	assert.Equal(t, 3, first.Fetched)
+	assert.Equal(t, 1, first.Added)
+	assert.Equal(t, 2, first.Rejected)
+
+	t.Run("run detail has the rejected records", func(t *testing.T) {
+		run, err := history.Run(first.ID)
+		require.NoError(t, err)
+		require.Len(t, run.Rejects, 2)
+
+		assert.Equal(t, "b", run.Rejects[0].ExternalID)
+		assert.Equal(t, "missing title", run.Rejects[0].Reason)
+		assert.Equal(t, "missing external id", run.Rejects[1].Reason)
+		assert.Contains(t, run.Rejects[1].Payload, "Rust Developer")
+
+		_, err = history.Run(404)
+		assert.ErrorIs(t, err, ErrRunNotFound)
+	})
+
+	t.Run("health summarises each source", func(t *testing.T) {
+		health, err := history.Health(clk.Now().Add(-7*24*time.Hour), clk.Now())
+		require.NoError(t, err)
+		require.Len(t, health, 1)
+
+		h := health[0]
+		assert.Equal(t, domain.Indeed, h.Source)
+		assert.Equal(t, failed.ID, h.LastRun.ID)
+		assert.Equal(t, second.FinishedAt, h.LastSuccess)
+		assert.Equal(t, 3, h.Runs)
+		assert.Equal(t, 1, h.Failures)
+		assert.InDelta(t, 1.0/3, h.ErrorRate(), 0.001)
+
+		require.Len(t, h.Added, 8)
+		assert.Equal(t, DailyCount{Day: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Count: 1}, h.Added[6])
+		assert.Equal(t, DailyCount{Day: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), Count: 2}, h.Added[7])
+		assert.Zero(t, h.Added[0].Count)
+	})
+}
+
+func TestRejectSample(t *testing.T) {
+	var run IngestionRun
+	for i := 0; i < rejectSample+5; i++ {
+		run.reject(domain.Job{}, "missing external id")
+	}
+
+	assert.Equal(t, rejectSample+5, run.Rejected)
+	assert.Len(t, run.Rejects, rejectSample)
+}
@@ -1,9 +1,13 @@
 package services
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"htmxjb/db"
 	"htmxjb/models/domain"
+	"htmxjb/services/sanitize"
+	"strings"
 	"time"
 )
 
@@ -11,31 +15,94 @@ type Job struct {
 	ID          int       `json:"id"`
 	Title       string    `json:"title"`
 	Description string    `json:"description,omitempty"`
+	Excerpt     string    `json:"excerpt,omitempty"`
 	CreatedAt   time.Time `json:"created_at,omitempty"`
 	UpdatedAt   time.Time `json:"updated_at,omitempty"`
 	Type        string    `json:"type"`
+	Workplace   string    `json:"workplace"`
 	Location    string    `json:"location"`
 	Salary      string    `json:"salary"`
-	IsNew       bool      `json:"is_new"`
-	Company     string    `json:"company"`
-	Tags        []string  `json:"tags,omitempty"`
+	// SalaryRange is the salary as parsed, for showing it in the visitor's
+	// language. Salaries that could not be parsed only have Raw.
+	SalaryRange domain.Salary `json:"-"`
+	IsNew       bool          `json:"is_new"`
+	Company     string        `json:"company"`
+	CompanySlug string        `json:"company_slug,omitempty"`
+	Department  string        `json:"department,omitempty"`
+	Tags        []string      `json:"tags,omitempty"`
+	IsClosed    bool          `json:"is_closed"`
+	Pinned      bool          `json:"pinned,omitempty"`
+	Highlighted bool          `json:"highlighted,omitempty"`
+
+	// Match is how well the job fits the seeker's resume, as a percentage.
+	// It is only set when the list is filtered with a profile.
+	Match int `json:"match,omitempty"`
+
+	// AcceptsApplications is set for open postings written on the board,
+	// which candidates apply to on the board too.
+	AcceptsApplications bool `json:"accepts_applications,omitempty"`
+
+	DescriptionHTML string `json:"description_html,omitempty"`
 }
 
+// excerptLength is how much of the description job cards show.
+const excerptLength = 280
+
+const jobColumns = "id, title, description, status, type, employment_type, salary_text, " + locationColumns + ", " + tagsColumn + ", " + companyColumns + ", pinned_until, highlighted_until, created_at, salary_min, salary_max, salary_currency, salary_period"
+
+var ErrJobNotFound = errors.New("job not found")
+
 type JobServices struct {
 	Job      Job
 	JobStore db.Store
+	Now      func() time.Time
 }
 
 func NewJobServices(j Job, jobStore db.Store) *JobServices {
 	return &JobServices{
 		Job:      j,
 		JobStore: jobStore,
+		Now:      time.Now,
 	}
 }
 
+// GetAllJobs returns open jobs first, then closed ones that have not been
+// archived yet.
 func (js *JobServices) GetAllJobs() ([]Job, error) {
-	query := "SELECT id, title, description FROM jobs ORDER BY created_at DESC"
-	rows, err := js.JobStore.Query(query)
+	return js.ListJobs(JobFilter{})
+}
+
+// ListJobs returns listed jobs matching filter, open jobs first and pinned
+// ones first among those. Archived jobs and jobs awaiting or failing review
+// are not listed. With a profile in filter, jobs are scored against it.
+func (js *JobServices) ListJobs(filter JobFilter) ([]Job, error) {
+	return js.listJobs(filter, "")
+}
+
+// FilteredJob returns the listed job with id if it matches filter, as it
+// would appear in ListJobs, or ErrJobNotFound.
+func (js *JobServices) FilteredJob(id int, filter JobFilter) (Job, error) {
+	jobs, err := js.listJobs(filter, "id = ?", id)
+	if err != nil {
+		return Job{}, err
+	}
+	if len(jobs) == 0 {
+		return Job{}, ErrJobNotFound
+	}
+	return jobs[0], nil
+}
+
+// listJobs is ListJobs with an extra condition. Scores are relative to
+// the jobs returned.
+func (js *JobServices) listJobs(filter JobFilter, extra string, extraArgs ...interface{}) ([]Job, error) {
+	now := js.Now()
+	where, args := filter.where()
+	if extra != "" {
+		where += " AND " + extra
+		args = append(args, extraArgs...)
+	}
+	query := "SELECT " + jobColumns + " FROM jobs WHERE " + where + " ORDER BY " + filter.orderBy()
+	rows, err := js.JobStore.Query(query, append(args, sqlTime(now))...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get jobs: %w", err)
 	}
@@ -43,10 +110,16 @@ func (js *JobServices) GetAllJobs() ([]Job, error) {
 
 	var jobs []Job
 	for rows.Next() {
-		var job Job
-		if err := rows.Scan(&job.ID, &job.Title, &job.Description); err != nil {
+		job, location, err := scanJob(rows, now)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan job: %w", err)
 		}
+
+		if !filter.matchesRadius(location) {
+			continue
+		}
+
+		job.Excerpt = sanitize.Excerpt(job.Description, excerptLength)
 		jobs = append(jobs, job)
 	}
 
@@ -54,25 +127,113 @@ func (js *JobServices) GetAllJobs() ([]Job, error) {
 		return nil, fmt.Errorf("error iterating jobs: %w", err)
 	}
 
+	scoreMatches(jobs, filter.Profile)
+	if filter.recommended() {
+		sortRecommended(jobs)
+	}
+
 	return jobs, nil
 }
 
+// GetJob returns a listed job with its description markup and department.
+func (js *JobServices) GetJob(id int) (Job, error) {
+	var (
+		descriptionHTML, department sql.NullString
+		source                      domain.JobSource
+		employerID                  sql.NullInt64
+	)
+
+	row := js.JobStore.QueryRow(
+		"SELECT "+jobColumns+", description_html, department, source, employer_id FROM jobs WHERE id = ? AND status IN (?, ?)",
+		id,
+		domain.Active,
+		domain.Closed,
+	)
+	job, _, err := scanJob(row, js.Now(), &descriptionHTML, &department, &source, &employerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Job{}, ErrJobNotFound
+	}
+	if err != nil {
+		return Job{}, fmt.Errorf("failed to get job %d: %w", id, err)
+	}
+
+	job.DescriptionHTML = descriptionHTML.String
+	job.Department = department.String
+	job.AcceptsApplications = !job.IsClosed && source == domain.Direct && employerID.Valid
+
+	return job, nil
+}
+
+// scanJob scans jobColumns, followed by extra, into a Job. Paid features
+// count if their window is open at now. The structured location is
+// returned as well for the radius check.
+func scanJob(row interface{ Scan(...interface{}) error }, now time.Time, extra ...interface{}) (Job, domain.Location, error) {
+	var (
+		job        Job
+		status     domain.JobStatus
+		workplace  domain.JobType
+		employment domain.EmploymentType
+		salary     sql.NullString
+		loc        locationRow
+		tagNames   sql.NullString
+		company    sql.NullString
+		slug       sql.NullString
+		pinned     sql.NullTime
+		highlight  sql.NullTime
+		createdAt  sql.NullTime
+		salaryMin  sql.NullFloat64
+		salaryMax  sql.NullFloat64
+		currency   sql.NullString
+		period     domain.SalaryPeriod
+	)
+	dest := []interface{}{&job.ID, &job.Title, &job.Description, &status, &workplace, &employment, &salary}
+	dest = append(dest, loc.dest()...)
+	dest = append(dest, &tagNames, &company, &slug, &pinned, &highlight, &createdAt, &salaryMin, &salaryMax, &currency, &period)
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return Job{}, domain.Location{}, err
+	}
+
+	location := loc.location()
+	job.IsClosed = status == domain.Closed
+	job.Type = knownOrEmpty(employment, employment != domain.UnknownEmployment)
+	job.Workplace = knownOrEmpty(workplace, workplace != domain.UnknownJobType)
+	job.Salary = salary.String
+	job.SalaryRange = domain.Salary{
+		Raw:      salary.String,
+		Min:      salaryMin.Float64,
+		Max:      salaryMax.Float64,
+		Currency: currency.String,
+		Period:   period,
+	}
+	job.CreatedAt = createdAt.Time
+	job.Location = location.String()
+	job.Tags = splitTags(tagNames.String)
+	job.Company = company.String
+	job.CompanySlug = slug.String
+	job.Pinned = status == domain.Active && pinned.Time.After(now)
+	job.Highlighted = status == domain.Active && highlight.Time.After(now)
+
+	return job, location, nil
+}
+
 func (js *JobServices) Create(job *domain.Job) error {
 	query := `
     INSERT INTO jobs (external_id, title, description, type, source)
     VALUES ($1, $2, $3, $4, $5)
-    RETURNING id
   `
-	var id int64
-	err := js.JobStore.QueryRow(
+	res, err := js.JobStore.Exec(
 		query,
 		job.ExternalID,
 		job.Title,
 		job.Description,
 		job.Type,
 		job.Source,
-	).Scan(&id)
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create job: %w", err)
+	}
 
+	id, err := res.LastInsertId()
 	if err != nil {
 		return fmt.Errorf("failed to create job: %w", err)
 	}
@@ -81,3 +242,208 @@ func (js *JobServices) Create(job *domain.Job) error {
 
 	return nil
 }
+
+// Upsert inserts a job or refreshes the existing row with the same source
+// and external ID. A job that shows up again is reopened unless it is
+// still pending or was rejected, and fields a moderator edited are kept.
+// New jobs are inserted pending if job.Status says so, active otherwise.
+func (js *JobServices) Upsert(job *domain.Job) error {
+	query := `
+    INSERT INTO jobs (
+      external_id, title, description, description_html, type, source, status, last_seen_at, expires_at,
+      salary_text, salary_min, salary_max, salary_currency, salary_period, salary_annual_min, salary_annual_max,
+      location_text, city, region, country_code, latitude, longitude, is_remote,
+      employment_type, type_text, department, rejection_reason, spam_score, spam_reasons, company_id
+    )
+    VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+    ON CONFLICT (source, external_id) DO UPDATE SET
+      title = CASE WHEN jobs.edited_at IS NULL THEN excluded.title ELSE jobs.title END,
+      description = CASE WHEN jobs.edited_at IS NULL THEN excluded.description ELSE jobs.description END,
+      description_html = CASE WHEN jobs.edited_at IS NULL THEN excluded.description_html ELSE jobs.description_html END,
+      type = excluded.type,
+      status = CASE WHEN jobs.status IN (?, ?) THEN jobs.status ELSE ? END,
+      salary_text = excluded.salary_text,
+      salary_min = excluded.salary_min,
+      salary_max = excluded.salary_max,
+      salary_currency = excluded.salary_currency,
+      salary_period = excluded.salary_period,
+      salary_annual_min = excluded.salary_annual_min,
+      salary_annual_max = excluded.salary_annual_max,
+      location_text = excluded.location_text,
+      city = excluded.city,
+      region = excluded.region,
+      country_code = excluded.country_code,
+      latitude = excluded.latitude,
+      longitude = excluded.longitude,
+      is_remote = excluded.is_remote,
+      employment_type = excluded.employment_type,
+      type_text = excluded.type_text,
+      department = excluded.department,
+      spam_score = excluded.spam_score,
+      spam_reasons = excluded.spam_reasons,
+      company_id = COALESCE(excluded.company_id, jobs.company_id),
+      last_seen_at = excluded.last_seen_at,
+      expires_at = excluded.expires_at,
+      closed_at = NULL,
+      updated_at = CURRENT_TIMESTAMP
+  `
+	// New jobs are listed unless moderation or the spam filter held them
+	// back.
+	status, reason := domain.Active, ""
+	switch job.Status {
+	case domain.Pending:
+		status = domain.Pending
+	case domain.Rejected:
+		status, reason = domain.Rejected, job.RejectionReason
+	}
+
+	// The row and its tags are written together, so a failure leaves
+	// neither behind.
+	return js.JobStore.WithTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(
+			query,
+			job.ExternalID,
+			job.Title,
+			job.Description,
+			nullString(job.DescriptionHTML),
+			job.Type,
+			job.Source,
+			status,
+			sqlTime(job.LastSeenAt),
+			sqlTime(job.ExpiresAt),
+			nullString(job.Salary.Raw),
+			nullFloat(job.Salary.Min),
+			nullFloat(job.Salary.Max),
+			nullString(job.Salary.Currency),
+			job.Salary.Period,
+			nullFloat(job.Salary.AnnualMin),
+			nullFloat(job.Salary.AnnualMax),
+			nullString(job.Location.Raw),
+			nullString(job.Location.City),
+			nullString(job.Location.Region),
+			nullString(job.Location.CountryCode),
+			nullCoordinate(job.Location, job.Location.Latitude),
+			nullCoordinate(job.Location, job.Location.Longitude),
+			job.Location.Remote,
+			job.Employment,
+			nullString(job.TypeText),
+			nullString(job.Department),
+			nullString(reason),
+			nullFloat(job.SpamScore),
+			nullString(strings.Join(job.SpamReasons, "\n")),
+			nullInt(job.CompanyID),
+			domain.Pending,
+			domain.Rejected,
+			domain.Active,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to upsert job %s: %w", job.ExternalID, err)
+		}
+
+		err = tx.QueryRow(
+			"SELECT id, status FROM jobs WHERE source = ? AND external_id = ?",
+			job.Source,
+			job.ExternalID,
+		).Scan(&job.ID, &job.Status)
+		if err != nil {
+			return fmt.Errorf("failed to upsert job %s: %w", job.ExternalID, err)
+		}
+
+		return setTags(tx, job.ID, job.Tags)
+	})
+}
+
+// ExternalIDs returns the external IDs of every stored job from source.
+func (js *JobServices) ExternalIDs(source domain.JobSource) (map[string]bool, error) {
+	rows, err := js.JobStore.Query("SELECT external_id FROM jobs WHERE source = ?", source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s job ids: %w", source, err)
+	}
+	defer rows.Close()
+
+	ids := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan job id: %w", err)
+		}
+		ids[id] = true
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating job ids: %w", err)
+	}
+
+	return ids, nil
+}
+
+// sqlTimeLayout is how timestamps are stored so they compare correctly as
+// text.
+const sqlTimeLayout = "2006-01-02 15:04:05.000"
+
+// sqlTime formats t for storage. The zero time is stored as NULL.
+func sqlTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC().Format(sqlTimeLayout)
+}
+
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// execReturningIDs runs an UPDATE ... RETURNING id and returns the ids of
+// the rows it changed. It goes through a transaction, the writer's only
+// way to read rows back.
+func execReturningIDs(store db.Store, query string, args ...interface{}) ([]int, error) {
+	var ids []int
+	err := store.WithTx(func(tx *sql.Tx) error {
+		rows, err := tx.Query(query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				return err
+			}
+			ids = append(ids, id)
+		}
+		return rows.Err()
+	})
+	return ids, err
+}
+
+func nullInt(n int64) interface{} {
+	if n == 0 {
+		return nil
+	}
+	return n
+}
+
+func nullFloat(f float64) interface{} {
+	if f == 0 {
+		return nil
+	}
+	return f
+}
+
+func nullCoordinate(loc domain.Location, v float64) interface{} {
+	if !loc.HasCoordinates() {
+		return nil
+	}
+	return v
+}
+
+func knownOrEmpty(v fmt.Stringer, known bool) string {
+	if !known {
+		return ""
+	}
+	return v.String()
+}
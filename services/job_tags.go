@@ -0,0 +1,94 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"htmxjb/models/domain"
+	"sort"
+	"strings"
+)
+
+// tagsColumn selects a job's tag names joined with "|", which the skills
+// dictionary does not allow in names.
+const tagsColumn = "(SELECT GROUP_CONCAT(t.name, '|') FROM job_tags jt JOIN tags t ON t.id = jt.tag_id WHERE jt.job_id = jobs.id) AS tags"
+
+// TagCount is a tag and the number of open jobs that have it.
+type TagCount struct {
+	Name  string
+	Count int
+}
+
+// SetTags replaces the tags of a job, creating tags that do not exist yet.
+func (js *JobServices) SetTags(jobID int64, names []string) error {
+	return js.JobStore.WithTx(func(tx *sql.Tx) error {
+		return setTags(tx, jobID, names)
+	})
+}
+
+// setTags is SetTags within tx, for callers that change the job too.
+func setTags(tx *sql.Tx, jobID int64, names []string) error {
+	if _, err := tx.Exec("DELETE FROM job_tags WHERE job_id = ?", jobID); err != nil {
+		return fmt.Errorf("failed to clear tags of job %d: %w", jobID, err)
+	}
+
+	for _, name := range names {
+		if _, err := tx.Exec("INSERT INTO tags (name) VALUES (?) ON CONFLICT (name) DO NOTHING", name); err != nil {
+			return fmt.Errorf("failed to create tag %q: %w", name, err)
+		}
+
+		_, err := tx.Exec(
+			"INSERT OR IGNORE INTO job_tags (job_id, tag_id) SELECT ?, id FROM tags WHERE name = ?",
+			jobID,
+			name,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to tag job %d with %q: %w", jobID, name, err)
+		}
+	}
+
+	return nil
+}
+
+// PopularTags returns the tags of open jobs, most used first.
+func (js *JobServices) PopularTags(limit int) ([]TagCount, error) {
+	rows, err := js.JobStore.Query(`
+    SELECT t.name, COUNT(*) AS jobs
+    FROM tags t
+    JOIN job_tags jt ON jt.tag_id = t.id
+    JOIN jobs j ON j.id = jt.job_id
+    WHERE j.status = ?
+    GROUP BY t.id
+    ORDER BY jobs DESC, t.name
+    LIMIT ?
+  `, domain.Active, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get popular tags: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []TagCount
+	for rows.Next() {
+		var tc TagCount
+		if err := rows.Scan(&tc.Name, &tc.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		counts = append(counts, tc)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tags: %w", err)
+	}
+
+	return counts, nil
+}
+
+func splitTags(joined string) []string {
+	if joined == "" {
+		return nil
+	}
+	names := strings.Split(joined, "|")
+	sort.Slice(names, func(i, j int) bool {
+		return strings.ToLower(names[i]) < strings.ToLower(names[j])
+	})
+	return names
+}
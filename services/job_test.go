@@ -1,12 +1,20 @@
 package services
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"htmxjb/models/domain"
+	"htmxjb/services/geo"
+	"htmxjb/services/mapping"
+	"htmxjb/services/salary"
+	"htmxjb/services/tags"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type MockStore struct {
@@ -21,6 +29,22 @@ func (m *MockStore) QueryRow(query string, args ...interface{}) *sql.Row {
 	return m.Db.QueryRow(query, args...)
 }
 
+func (m *MockStore) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return m.Db.Exec(query, args...)
+}
+
+func (m *MockStore) WithTx(fn func(tx *sql.Tx) error) error {
+	tx, err := m.Db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
 func (m *MockStore) Close() error {
 	return m.Db.Close()
 }
@@ -34,13 +58,17 @@ func TestGetAllJobs(t *testing.T) {
 	mockStore := &MockStore{Db: db}
 
 	jobServices := NewJobServices(Job{}, mockStore)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	jobServices.Now = func() time.Time { return now }
 
 	t.Run("Successfully get all jobs", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"id", "title", "description"}).
-			AddRow(1, "Software Engineer", "Develop software").
-			AddRow(2, "Data Scientist", "Analyze data")
+		rows := sqlmock.NewRows([]string{"id", "title", "description", "status", "type", "employment_type", "salary_text", "location_text", "city", "region", "country_code", "latitude", "longitude", "is_remote", "tags", "company", "company_slug", "pinned_until", "highlighted_until", "created_at", "salary_min", "salary_max", "salary_currency", "salary_period"}).
+			AddRow(1, "Software Engineer", "Develop software", 0, domain.Onsite, domain.FullTime, "$100,000 a year", "Austin, TX", "Austin", "TX", "US", 30.2672, -97.7431, false, "SQLite|Go", "Acme", "acme", now.Add(time.Hour), now.Add(-time.Hour), now.Add(-48*time.Hour), 100000, nil, "USD", domain.Yearly).
+			AddRow(2, "Data Scientist", "Analyze data", 1, domain.Remote, domain.UnknownEmployment, nil, "Remote", nil, nil, nil, nil, nil, true, nil, nil, nil, now.Add(time.Hour), nil, now, nil, nil, nil, domain.UnknownPeriod)
 
-		mock.ExpectQuery("SELECT id, title, description FROM jobs ORDER BY created_at DESC").WillReturnRows(rows)
+		mock.ExpectQuery("SELECT id, title, description, status, type, employment_type, salary_text, location_text, city, region, country_code, latitude, longitude, is_remote, \\(SELECT GROUP_CONCAT\\(t.name, '\\|'\\) FROM job_tags jt JOIN tags t ON t.id = jt.tag_id WHERE jt.job_id = jobs.id\\) AS tags, \\(SELECT name FROM companies WHERE id = jobs.company_id\\) AS company, \\(SELECT slug FROM companies WHERE id = jobs.company_id\\) AS company_slug, pinned_until, highlighted_until, created_at, salary_min, salary_max, salary_currency, salary_period FROM jobs WHERE status IN \\(\\?, \\?\\) ORDER BY status, COALESCE\\(pinned_until > \\?, 0\\) DESC, created_at DESC").
+			WithArgs(domain.Active, domain.Closed, sqlTime(now)).
+			WillReturnRows(rows)
 
 		jobs, err := jobServices.GetAllJobs()
 
@@ -48,12 +76,31 @@ func TestGetAllJobs(t *testing.T) {
 		assert.Equal(t, 2, len(jobs))
 		assert.Equal(t, "Software Engineer", jobs[0].Title)
 		assert.Equal(t, "Data Scientist", jobs[1].Title)
+		assert.Equal(t, "Acme", jobs[0].Company)
+		assert.Equal(t, "acme", jobs[0].CompanySlug)
+		assert.Equal(t, "", jobs[1].Company)
+		assert.Equal(t, "$100,000 a year", jobs[0].Salary)
+		assert.Equal(t, "", jobs[1].Salary)
+		assert.Equal(t, domain.Salary{Raw: "$100,000 a year", Min: 100000, Currency: "USD", Period: domain.Yearly}, jobs[0].SalaryRange)
+		assert.Equal(t, now.Add(-48*time.Hour), jobs[0].CreatedAt)
+		assert.Equal(t, "Austin, TX, US", jobs[0].Location)
+		assert.Equal(t, "full-time", jobs[0].Type)
+		assert.Equal(t, "onsite", jobs[0].Workplace)
+		assert.Equal(t, "", jobs[1].Type)
+		assert.Equal(t, "Remote", jobs[1].Location)
+		assert.Equal(t, []string{"Go", "SQLite"}, jobs[0].Tags)
+		assert.Empty(t, jobs[1].Tags)
+		assert.False(t, jobs[0].IsClosed)
+		assert.True(t, jobs[1].IsClosed)
+		assert.True(t, jobs[0].Pinned)
+		assert.False(t, jobs[0].Highlighted, "the highlight ran out")
+		assert.False(t, jobs[1].Pinned, "closed jobs are not pinned")
 
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
 	t.Run("Handle database error", func(t *testing.T) {
-		mock.ExpectQuery("SELECT id, title, description FROM jobs ORDER BY created_at DESC").WillReturnError(fmt.Errorf("mock database error"))
+		mock.ExpectQuery("SELECT id, title, description, status, type, employment_type, salary_text, location_text, city, region, country_code, latitude, longitude, is_remote, \\(SELECT GROUP_CONCAT\\(t.name, '\\|'\\) FROM job_tags jt JOIN tags t ON t.id = jt.tag_id WHERE jt.job_id = jobs.id\\) AS tags, \\(SELECT name FROM companies WHERE id = jobs.company_id\\) AS company, \\(SELECT slug FROM companies WHERE id = jobs.company_id\\) AS company_slug, pinned_until, highlighted_until, created_at, salary_min, salary_max, salary_currency, salary_period FROM jobs WHERE status IN \\(\\?, \\?\\) ORDER BY status, COALESCE\\(pinned_until > \\?, 0\\) DESC, created_at DESC").WillReturnError(fmt.Errorf("mock database error"))
 
 		_, err := jobServices.GetAllJobs()
 
@@ -63,3 +110,211 @@ func TestGetAllJobs(t *testing.T) {
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
+
+func TestListJobsSalaryFilter(t *testing.T) {
+	store := openTestStore(t)
+	jobServices := NewJobServices(Job{}, store)
+
+	fetcher := &stubFetcher{
+		source: domain.Csv,
+		jobs: []domain.Job{
+			{ExternalID: "low", Title: "Junior", Salary: domain.Salary{Raw: "$20 an hour"}},
+			{ExternalID: "mid", Title: "Middle", Salary: domain.Salary{Raw: "€4.000 - €5.000 pro Monat"}},
+			{ExternalID: "high", Title: "Senior", Salary: domain.Salary{Raw: "$150k - $180k a year"}},
+			{ExternalID: "none", Title: "Unknown"},
+		},
+	}
+	retention := NewRetentionService(store, RetentionPolicy{})
+	normalizer := salary.NewNormalizer("USD", map[string]float64{"USD": 1, "EUR": 1})
+	ingestor := NewIngestor(jobServices, retention, []JobEnricher{SalaryEnricher(normalizer)}, fetcher)
+	require.NoError(t, ingestor.RunOnce(context.Background()))
+
+	titles := func(jobs []Job) []string {
+		var out []string
+		for _, job := range jobs {
+			out = append(out, job.Title)
+		}
+		return out
+	}
+
+	jobs, err := jobServices.ListJobs(JobFilter{Sort: SortSalaryDesc})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Senior", "Middle", "Junior", "Unknown"}, titles(jobs))
+
+	jobs, err = jobServices.ListJobs(JobFilter{MinSalary: 45_000, MaxSalary: 100_000, Sort: SortSalaryAsc})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Middle"}, titles(jobs))
+	assert.Equal(t, "€4.000 - €5.000 pro Monat", jobs[0].Salary)
+}
+
+func TestListJobsRadiusFilter(t *testing.T) {
+	store := openTestStore(t)
+	jobServices := NewJobServices(Job{}, store)
+
+	places, err := geo.Bundled()
+	require.NoError(t, err)
+
+	fetcher := &stubFetcher{
+		source: domain.Csv,
+		jobs: []domain.Job{
+			{ExternalID: "nl", Title: "Amsterdam", Location: domain.Location{Raw: "Amsterdam, Netherlands"}},
+			{ExternalID: "rt", Title: "Rotterdam", Location: domain.Location{Raw: "Rotterdam"}},
+			{ExternalID: "de", Title: "Berlin", Location: domain.Location{Raw: "Berlin, Germany"}},
+			{ExternalID: "rm", Title: "Remote", Location: domain.Location{Raw: "Remote"}},
+		},
+	}
+	retention := NewRetentionService(store, RetentionPolicy{})
+	ingestor := NewIngestor(jobServices, retention, []JobEnricher{LocationEnricher(places)}, fetcher)
+	require.NoError(t, ingestor.RunOnce(context.Background()))
+
+	amsterdam, ok := places.Lookup("Amsterdam")
+	require.True(t, ok)
+
+	jobs, err := jobServices.ListJobs(JobFilter{Near: &amsterdam.Point, RadiusKm: 100})
+	require.NoError(t, err)
+
+	var titles []string
+	for _, job := range jobs {
+		titles = append(titles, job.Title)
+	}
+	assert.ElementsMatch(t, []string{"Amsterdam", "Rotterdam"}, titles)
+
+	jobs, err = jobServices.ListJobs(JobFilter{NearCity: "Atlantis", NearUnknown: true, RadiusKm: 100})
+	require.NoError(t, err)
+	assert.Empty(t, jobs, "an unknown city matches nothing")
+}
+
+func TestListJobsTypeFilter(t *testing.T) {
+	store := openTestStore(t)
+	jobServices := NewJobServices(Job{}, store)
+
+	places, err := geo.Bundled()
+	require.NoError(t, err)
+
+	fetcher := &stubFetcher{
+		source: domain.Csv,
+		jobs: []domain.Job{
+			{ExternalID: "1", Title: "Remote contractor", TypeText: "contract", Location: domain.Location{Raw: "Remote"}},
+			{ExternalID: "2", Title: "Office full-timer", TypeText: "full-time, onsite", Location: domain.Location{Raw: "Berlin"}},
+			{ExternalID: "3", Title: "Hybrid intern", TypeText: "intern / hybrid"},
+		},
+	}
+	enrichers := []JobEnricher{LocationEnricher(places), TypeEnricher(mapping.NewMapper())}
+	ingestor := NewIngestor(jobServices, NewRetentionService(store, RetentionPolicy{}), enrichers, fetcher)
+	require.NoError(t, ingestor.RunOnce(context.Background()))
+
+	remote := domain.Remote
+	jobs, err := jobServices.ListJobs(JobFilter{Workplace: &remote})
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "Remote contractor", jobs[0].Title)
+	assert.Equal(t, "contract", jobs[0].Type)
+
+	internship := domain.Internship
+	jobs, err = jobServices.ListJobs(JobFilter{Employment: &internship})
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "hybrid", jobs[0].Workplace)
+}
+
+func TestListJobsTagFilter(t *testing.T) {
+	store := openTestStore(t)
+	jobServices := NewJobServices(Job{}, store)
+
+	extractor, err := tags.Bundled()
+	require.NoError(t, err)
+
+	fetcher := &stubFetcher{
+		source: domain.Csv,
+		jobs: []domain.Job{
+			{ExternalID: "1", Title: "Golang developer", Description: "Go, htmx and SQLite"},
+			{ExternalID: "2", Title: "Backend engineer", Description: "Go and PostgreSQL"},
+			{ExternalID: "3", Title: "Frontend engineer", Description: "React and TypeScript"},
+		},
+	}
+	ingestor := NewIngestor(jobServices, NewRetentionService(store, RetentionPolicy{}), []JobEnricher{TagEnricher(extractor)}, fetcher)
+	require.NoError(t, ingestor.RunOnce(context.Background()))
+
+	jobs, err := jobServices.ListJobs(JobFilter{Tags: []string{"go"}})
+	require.NoError(t, err)
+	assert.Len(t, jobs, 2)
+
+	jobs, err = jobServices.ListJobs(JobFilter{Tags: []string{"Go", "sqlite", "Go"}})
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "Golang developer", jobs[0].Title)
+	assert.Equal(t, []string{"Go", "htmx", "SQLite"}, jobs[0].Tags)
+
+	popular, err := jobServices.PopularTags(2)
+	require.NoError(t, err)
+	assert.Equal(t, []TagCount{{Name: "Go", Count: 2}, {Name: "htmx", Count: 1}}, popular)
+
+	// A job re-ingested with a new description loses its old tags.
+	fetcher.jobs = fetcher.jobs[:1]
+	fetcher.jobs[0].Description = "Rust"
+	require.NoError(t, ingestor.RunOnce(context.Background()))
+
+	jobs, err = jobServices.ListJobs(JobFilter{Tags: []string{"rust"}})
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, []string{"Go", "Rust"}, jobs[0].Tags)
+}
+
+func TestUpsertWritesJobAndTagsTogether(t *testing.T) {
+	store := openTestStore(t)
+	jobServices := NewJobServices(Job{}, store)
+
+	job := domain.Job{ExternalID: "1", Title: "Go developer", Source: domain.Csv, Tags: []string{"Go"}}
+	require.NoError(t, jobServices.Upsert(&job))
+
+	// Tagging fails partway through.
+	_, err := store.Exec(`CREATE TRIGGER no_broken_tags BEFORE INSERT ON job_tags
+		WHEN NEW.tag_id IN (SELECT id FROM tags WHERE name = 'Broken')
+		BEGIN SELECT RAISE(ABORT, 'broken tag'); END`)
+	require.NoError(t, err)
+
+	changed := domain.Job{ExternalID: "1", Title: "Rust developer", Source: domain.Csv, Tags: []string{"Rust", "Broken"}}
+	assert.Error(t, jobServices.Upsert(&changed))
+	added := domain.Job{ExternalID: "2", Title: "Zig developer", Source: domain.Csv, Tags: []string{"Broken"}}
+	assert.Error(t, jobServices.Upsert(&added))
+
+	jobs, err := jobServices.ListJobs(JobFilter{})
+	require.NoError(t, err)
+	require.Len(t, jobs, 1, "the new job was not written")
+	assert.Equal(t, "Go developer", jobs[0].Title, "the update was rolled back")
+	assert.Equal(t, []string{"Go"}, jobs[0].Tags)
+}
+
+func TestGetJobDescription(t *testing.T) {
+	store := openTestStore(t)
+	jobServices := NewJobServices(Job{}, store)
+
+	fetcher := &stubFetcher{
+		source: domain.Indeed,
+		jobs: []domain.Job{{
+			ExternalID:      "1",
+			Title:           "Go developer",
+			Department:      "Engineering",
+			DescriptionHTML: `<h2>About</h2><p onclick="x()">Build things.</p><script>alert(1)</script>`,
+		}},
+	}
+	ingestor := NewIngestor(jobServices, NewRetentionService(store, RetentionPolicy{}), []JobEnricher{DescriptionEnricher()}, fetcher)
+	require.NoError(t, ingestor.RunOnce(context.Background()))
+
+	jobs, err := jobServices.ListJobs(JobFilter{})
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "About Build things.", jobs[0].Excerpt)
+	assert.Empty(t, jobs[0].DescriptionHTML)
+
+	job, err := jobServices.GetJob(jobs[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Go developer", job.Title)
+	assert.Equal(t, "About\nBuild things.", job.Description)
+	assert.Equal(t, "<h2>About</h2><p>Build things.</p>", job.DescriptionHTML)
+	assert.Equal(t, "Engineering", job.Department)
+	assert.False(t, job.AcceptsApplications, "ingested jobs are applied to on their source")
+
+	_, err = jobServices.GetJob(jobs[0].ID + 1)
+	assert.ErrorIs(t, err, ErrJobNotFound)
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"database/sql"
+	"htmxjb/models/domain"
+)
+
+const locationColumns = "location_text, city, region, country_code, latitude, longitude, is_remote"
+
+// locationRow scans the nullable location columns of a jobs row.
+type locationRow struct {
+	raw, city, region, country sql.NullString
+	lat, lon                   sql.NullFloat64
+	remote                     bool
+}
+
+func (r *locationRow) dest() []interface{} {
+	return []interface{}{&r.raw, &r.city, &r.region, &r.country, &r.lat, &r.lon, &r.remote}
+}
+
+func (r *locationRow) location() domain.Location {
+	return domain.Location{
+		Raw:         r.raw.String,
+		City:        r.city.String,
+		Region:      r.region.String,
+		CountryCode: r.country.String,
+		Latitude:    r.lat.Float64,
+		Longitude:   r.lon.Float64,
+		Remote:      r.remote,
+	}
+}
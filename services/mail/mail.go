@@ -0,0 +1,92 @@
+// Package mail sends notification emails.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"mime"
+	"mime/quotedprintable"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Message is a plain text email to one recipient.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// SMTP sends mail through a relay. Auth may be nil for relays that accept
+// mail without it.
+type SMTP struct {
+	Addr string
+	From string
+	Auth smtp.Auth
+}
+
+// NewSMTP returns a mailer for the relay at addr, e.g. "localhost:25",
+// logging in with user and password if a user is given.
+func NewSMTP(addr, from, user, password string) *SMTP {
+	s := &SMTP{Addr: addr, From: from}
+	if user != "" {
+		host, _, _ := strings.Cut(addr, ":")
+		s.Auth = smtp.PlainAuth("", user, password, host)
+	}
+	return s
+}
+
+func (s *SMTP) Send(ctx context.Context, msg Message) error {
+	data, err := format(s.From, msg, time.Now())
+	if err != nil {
+		return err
+	}
+	if err := smtp.SendMail(s.Addr, s.Auth, s.From, []string{msg.To}, data); err != nil {
+		return fmt.Errorf("failed to send mail to %s: %w", msg.To, err)
+	}
+	return nil
+}
+
+// Log writes messages to the log instead of sending them, for development.
+type Log struct{}
+
+func (Log) Send(ctx context.Context, msg Message) error {
+	log.Printf("✅ Mail to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// format renders msg as an RFC 5322 message. Header values may not span
+// lines, so nobody can slip extra headers in through a job title.
+func format(from string, msg Message, now time.Time) ([]byte, error) {
+	for _, v := range []string{from, msg.To, msg.Subject} {
+		if strings.ContainsAny(v, "\r\n") {
+			return nil, fmt.Errorf("failed to format mail to %s: header contains a line break", msg.To)
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	qp := quotedprintable.NewWriter(&buf)
+	if _, err := qp.Write([]byte(strings.ReplaceAll(msg.Body, "\n", "\r\n"))); err != nil {
+		return nil, fmt.Errorf("failed to format mail to %s: %w", msg.To, err)
+	}
+	if err := qp.Close(); err != nil {
+		return nil, fmt.Errorf("failed to format mail to %s: %w", msg.To, err)
+	}
+
+	return buf.Bytes(), nil
+}
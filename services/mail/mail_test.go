@@ -0,0 +1,39 @@
+package mail
+
+import (
+	"io"
+	"mime"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+
+	data, err := format("jobs@example.com", Message{
+		To:      "hr@acme.test",
+		Subject: "New application for Développeur Go",
+		Body:    "Ann applied.\nRead it on your dashboard.",
+	}, now)
+	require.NoError(t, err)
+
+	parsed, err := mail.ReadMessage(strings.NewReader(string(data)))
+	require.NoError(t, err)
+	assert.Equal(t, "hr@acme.test", parsed.Header.Get("To"))
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "New application for Développeur Go", subject)
+
+	body, err := io.ReadAll(parsed.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "Ann applied.\r\n")
+
+	_, err = format("jobs@example.com", Message{To: "hr@acme.test", Subject: "Hi\r\nBcc: everyone@example.com"}, now)
+	assert.Error(t, err, "line breaks in headers are refused")
+}
@@ -0,0 +1,230 @@
+package mapping
+
+import (
+	"htmxjb/models/domain"
+	"log"
+	"strings"
+	"sync"
+)
+
+// Mapping tables from the raw type text each source sends to our enums.
+// Keys are normalized with normalize: lower case, with "-" and "_" read as
+// spaces. Values not in a table map to unknown and are logged for review.
+var (
+	EmploymentTables = map[domain.JobSource]map[string]domain.EmploymentType{
+		domain.Csv: {
+			"full time":  domain.FullTime,
+			"fulltime":   domain.FullTime,
+			"permanent":  domain.FullTime,
+			"part time":  domain.PartTime,
+			"parttime":   domain.PartTime,
+			"contract":   domain.Contract,
+			"contractor": domain.Contract,
+			"freelance":  domain.Contract,
+			"internship": domain.Internship,
+			"intern":     domain.Internship,
+			"temporary":  domain.Temporary,
+			"temp":       domain.Temporary,
+		},
+		domain.Indeed: {
+			"full time":  domain.FullTime,
+			"fulltime":   domain.FullTime,
+			"permanent":  domain.FullTime,
+			"part time":  domain.PartTime,
+			"parttime":   domain.PartTime,
+			"contract":   domain.Contract,
+			"freelance":  domain.Contract,
+			"internship": domain.Internship,
+			"temporary":  domain.Temporary,
+			"seasonal":   domain.Temporary,
+		},
+		domain.LinkedIn: {
+			"full time":  domain.FullTime,
+			"part time":  domain.PartTime,
+			"contractor": domain.Contract,
+			"contract":   domain.Contract,
+			"intern":     domain.Internship,
+			"internship": domain.Internship,
+			"temporary":  domain.Temporary,
+		},
+		// Career pages send schema.org employmentType values, or free text
+		// when scraped with selectors.
+		domain.CareerPage: {
+			"full time":  domain.FullTime,
+			"fulltime":   domain.FullTime,
+			"permanent":  domain.FullTime,
+			"part time":  domain.PartTime,
+			"contractor": domain.Contract,
+			"contract":   domain.Contract,
+			"freelance":  domain.Contract,
+			"intern":     domain.Internship,
+			"internship": domain.Internship,
+			"temporary":  domain.Temporary,
+			"per diem":   domain.Temporary,
+		},
+		// Greenhouse has no standard field; boards name it in custom
+		// metadata.
+		domain.Greenhouse: {
+			"full time":  domain.FullTime,
+			"permanent":  domain.FullTime,
+			"part time":  domain.PartTime,
+			"contract":   domain.Contract,
+			"contractor": domain.Contract,
+			"intern":     domain.Internship,
+			"internship": domain.Internship,
+			"temporary":  domain.Temporary,
+		},
+		domain.Lever: {
+			"full time":  domain.FullTime,
+			"permanent":  domain.FullTime,
+			"part time":  domain.PartTime,
+			"contract":   domain.Contract,
+			"contractor": domain.Contract,
+			"freelance":  domain.Contract,
+			"intern":     domain.Internship,
+			"internship": domain.Internship,
+			"temporary":  domain.Temporary,
+		},
+		domain.Ashby: {
+			"fulltime":  domain.FullTime,
+			"parttime":  domain.PartTime,
+			"contract":  domain.Contract,
+			"intern":    domain.Internship,
+			"temporary": domain.Temporary,
+		},
+	}
+
+	WorkplaceTables = map[domain.JobSource]map[string]domain.JobType{
+		domain.Csv: {
+			"remote":    domain.Remote,
+			"onsite":    domain.Onsite,
+			"on site":   domain.Onsite,
+			"in office": domain.Onsite,
+			"office":    domain.Onsite,
+			"hybrid":    domain.Hybrid,
+		},
+		domain.Indeed: {
+			"remote":        domain.Remote,
+			"on site":       domain.Onsite,
+			"in person":     domain.Onsite,
+			"hybrid":        domain.Hybrid,
+			"hybrid remote": domain.Hybrid,
+		},
+		domain.LinkedIn: {
+			"remote":  domain.Remote,
+			"on site": domain.Onsite,
+			"onsite":  domain.Onsite,
+			"hybrid":  domain.Hybrid,
+		},
+		domain.CareerPage: {
+			"remote":      domain.Remote,
+			"telecommute": domain.Remote,
+			"on site":     domain.Onsite,
+			"onsite":      domain.Onsite,
+			"in office":   domain.Onsite,
+			"hybrid":      domain.Hybrid,
+		},
+		domain.Greenhouse: {
+			"remote":  domain.Remote,
+			"on site": domain.Onsite,
+			"onsite":  domain.Onsite,
+			"hybrid":  domain.Hybrid,
+		},
+		domain.Lever: {
+			"remote": domain.Remote,
+			"onsite": domain.Onsite,
+			"hybrid": domain.Hybrid,
+		},
+		domain.Ashby: {
+			"remote": domain.Remote,
+			"onsite": domain.Onsite,
+			"hybrid": domain.Hybrid,
+		},
+	}
+)
+
+// Mapper maps raw type text to enums using the tables and remembers which
+// unmapped values it has already logged.
+type Mapper struct {
+	Employment map[domain.JobSource]map[string]domain.EmploymentType
+	Workplace  map[domain.JobSource]map[string]domain.JobType
+
+	mu       sync.Mutex
+	unmapped map[string]int
+}
+
+func NewMapper() *Mapper {
+	return &Mapper{
+		Employment: EmploymentTables,
+		Workplace:  WorkplaceTables,
+		unmapped:   make(map[string]int),
+	}
+}
+
+// Map splits raw type text such as "Full-time, Remote" into its parts and
+// returns the first employment and workplace type found in the source's
+// tables. Parts that match neither table are reported as unmapped.
+func (m *Mapper) Map(source domain.JobSource, raw string) (domain.EmploymentType, domain.JobType) {
+	employment, workplace := domain.UnknownEmployment, domain.UnknownJobType
+
+	for _, part := range splitParts(raw) {
+		key := normalize(part)
+		if key == "" {
+			continue
+		}
+
+		et, isEmployment := m.Employment[source][key]
+		if isEmployment && employment == domain.UnknownEmployment {
+			employment = et
+		}
+
+		wt, isWorkplace := m.Workplace[source][key]
+		if isWorkplace && workplace == domain.UnknownJobType {
+			workplace = wt
+		}
+
+		if !isEmployment && !isWorkplace {
+			m.reportUnmapped(source, part)
+		}
+	}
+
+	return employment, workplace
+}
+
+// Unmapped returns how many times each unmapped "source: value" pair has
+// been seen since start.
+func (m *Mapper) Unmapped() map[string]int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make(map[string]int, len(m.unmapped))
+	for k, v := range m.unmapped {
+		out[k] = v
+	}
+	return out
+}
+
+func (m *Mapper) reportUnmapped(source domain.JobSource, value string) {
+	key := source.String() + ": " + strings.TrimSpace(value)
+
+	m.mu.Lock()
+	m.unmapped[key]++
+	first := m.unmapped[key] == 1
+	m.mu.Unlock()
+
+	if first {
+		log.Printf("🔥 unmapped job type %q from %s, add it to the mapping tables", strings.TrimSpace(value), source)
+	}
+}
+
+func splitParts(raw string) []string {
+	return strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ',' || r == '/' || r == ';' || r == '|' || r == '·'
+	})
+}
+
+func normalize(s string) string {
+	s = strings.ToLower(s)
+	s = strings.NewReplacer("-", " ", "_", " ").Replace(s)
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,43 @@
+package mapping
+
+import (
+	"htmxjb/models/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMap(t *testing.T) {
+	tests := []struct {
+		source     domain.JobSource
+		raw        string
+		employment domain.EmploymentType
+		workplace  domain.JobType
+	}{
+		{domain.Csv, "full-time", domain.FullTime, domain.UnknownJobType},
+		{domain.Csv, "Part time / Remote", domain.PartTime, domain.Remote},
+		{domain.Indeed, "Full-time, Contract", domain.FullTime, domain.UnknownJobType},
+		{domain.Indeed, "Hybrid remote", domain.UnknownEmployment, domain.Hybrid},
+		{domain.LinkedIn, "FULL_TIME", domain.FullTime, domain.UnknownJobType},
+		{domain.LinkedIn, "INTERN", domain.Internship, domain.UnknownJobType},
+		{domain.Csv, "", domain.UnknownEmployment, domain.UnknownJobType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source.String()+"/"+tt.raw, func(t *testing.T) {
+			employment, workplace := NewMapper().Map(tt.source, tt.raw)
+
+			assert.Equal(t, tt.employment, employment)
+			assert.Equal(t, tt.workplace, workplace)
+		})
+	}
+}
+
+func TestMapRecordsUnmapped(t *testing.T) {
+	m := NewMapper()
+
+	m.Map(domain.Indeed, "Gig")
+	m.Map(domain.Indeed, "gig, full-time")
+
+	assert.Equal(t, map[string]int{"indeed: Gig": 1, "indeed: gig": 1}, m.Unmapped())
+}
@@ -0,0 +1,127 @@
+package match
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Terms counts the words of text, lower-cased, leaving out stop words and
+// single characters. "+" and "#" are kept inside words so that "C++" and
+// "C#" survive.
+func Terms(text string) map[string]int {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '+' && r != '#'
+	})
+
+	terms := make(map[string]int)
+	for _, w := range words {
+		w = strings.TrimLeft(w, "+#")
+		if len([]rune(w)) < 2 || stopWords[w] {
+			continue
+		}
+		terms[w]++
+	}
+	return terms
+}
+
+// Corpus holds document frequencies for TF-IDF weights. Add every
+// document that will be compared before weighting any of them.
+type Corpus struct {
+	df   map[string]int
+	docs int
+}
+
+func NewCorpus() *Corpus {
+	return &Corpus{df: make(map[string]int)}
+}
+
+// Add counts the document's terms towards the document frequencies.
+func (c *Corpus) Add(terms map[string]int) {
+	c.docs++
+	for t := range terms {
+		c.df[t]++
+	}
+}
+
+// Vector weights terms by TF-IDF, with sub-linear term frequencies and
+// smoothed inverse document frequencies so that no term weighs zero.
+func (c *Corpus) Vector(terms map[string]int) Vector {
+	v := make(Vector, len(terms))
+	for t, n := range terms {
+		idf := math.Log(float64(1+c.docs)/float64(1+c.df[t])) + 1
+		v[t] = (1 + math.Log(float64(n))) * idf
+	}
+	return v
+}
+
+// Vector maps terms to weights.
+type Vector map[string]float64
+
+// Cosine returns the cosine similarity of a and b, from 0 for no shared
+// terms to 1 for the same direction.
+func Cosine(a, b Vector) float64 {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+
+	var dot float64
+	for t, w := range a {
+		dot += w * b[t]
+	}
+	if dot == 0 {
+		return 0
+	}
+	return dot / (a.norm() * b.norm())
+}
+
+func (v Vector) norm() float64 {
+	var sum float64
+	for _, w := range v {
+		sum += w * w
+	}
+	return math.Sqrt(sum)
+}
+
+// Coverage returns the share of want found in have, ignoring case. It is 0
+// when want is empty.
+func Coverage(have, want []string) float64 {
+	if len(want) == 0 {
+		return 0
+	}
+
+	set := make(map[string]bool, len(have))
+	for _, s := range have {
+		set[strings.ToLower(s)] = true
+	}
+
+	found := 0
+	for _, s := range want {
+		if set[strings.ToLower(s)] {
+			found++
+		}
+	}
+	return float64(found) / float64(len(want))
+}
+
+// stopWords are common English words and resume and job ad filler that
+// say nothing about fit.
+var stopWords = func() map[string]bool {
+	words := strings.Fields(`
+		a about above after again all also am an and any are as at be because been before being below
+		between both but by can could did do does doing down during each few for from further had has
+		have having he her here hers herself him himself his how i if in into is it its itself just me
+		more most my myself no nor not now of off on once only or other our ours ourselves out over own
+		same she should so some such than that the their theirs them themselves then there these they
+		this those through to too under until up very was we were what when where which while who whom
+		why will with would you your yours yourself yourselves etc via per within across well must may
+		job jobs role position candidate candidates company team work working experience years year
+		looking seeking responsibilities requirements required preferred including ability strong
+		skills skill knowledge excellent good new join us offer plus
+	`)
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}()
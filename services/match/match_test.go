@@ -0,0 +1,53 @@
+package match
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTerms(t *testing.T) {
+	assert.Equal(t,
+		map[string]int{"senior": 1, "go": 2, "developer": 1, "c++": 1, "c#": 1, "kubernetes": 1},
+		Terms("Senior Go developer: Go, C++ and C#! The Kubernetes role, a plus"),
+	)
+}
+
+func TestCosine(t *testing.T) {
+	resume := Terms("Go engineer: Kubernetes, PostgreSQL, gRPC microservices")
+	goJob := Terms("Backend engineer writing Go microservices on Kubernetes")
+	railsJob := Terms("Ruby on Rails engineer for our booking product")
+	designJob := Terms("Product designer, Figma and user research")
+
+	c := NewCorpus()
+	for _, doc := range []map[string]int{resume, goJob, railsJob, designJob} {
+		c.Add(doc)
+	}
+
+	r := c.Vector(resume)
+	goScore := Cosine(r, c.Vector(goJob))
+	railsScore := Cosine(r, c.Vector(railsJob))
+
+	assert.Greater(t, goScore, railsScore)
+	assert.Greater(t, railsScore, 0.0, "both mention engineer")
+	assert.Zero(t, Cosine(r, c.Vector(designJob)))
+	assert.InDelta(t, 1, Cosine(r, r), 1e-9)
+	assert.Zero(t, Cosine(r, nil))
+}
+
+func TestCorpusWeighsRareTermsHigher(t *testing.T) {
+	c := NewCorpus()
+	c.Add(Terms("engineer go"))
+	c.Add(Terms("engineer rust"))
+	c.Add(Terms("engineer java"))
+
+	v := c.Vector(Terms("engineer go"))
+	assert.Greater(t, v["go"], v["engineer"])
+	assert.Greater(t, v["engineer"], 0.0)
+}
+
+func TestCoverage(t *testing.T) {
+	assert.Equal(t, 0.5, Coverage([]string{"Go", "Docker"}, []string{"go", "Kubernetes"}))
+	assert.Equal(t, 1.0, Coverage([]string{"Go"}, []string{"Go"}))
+	assert.Zero(t, Coverage([]string{"Go"}, nil))
+}
@@ -0,0 +1,139 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+var ErrUnknownSession = errors.New("checkout session not found")
+
+// FakeSignatureHeader carries the signature of the fake provider's events.
+const FakeSignatureHeader = "Fake-Signature"
+
+// Fake is a provider for local development. Its checkout is a page on the
+// board itself where the buyer picks whether the payment goes through, and
+// it signs its events like a real provider would. Sessions live in memory.
+type Fake struct {
+	secret []byte
+
+	mu       sync.Mutex
+	sessions map[string]Checkout
+}
+
+// NewFake returns a fake provider signing its events with secret. Without
+// one it signs with a random key, so only its own checkout page can
+// deliver events.
+func NewFake(secret string) *Fake {
+	key := []byte(secret)
+	if secret == "" {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			panic(fmt.Sprintf("failed to generate fake payment secret: %v", err))
+		}
+	}
+
+	return &Fake{
+		secret:   key,
+		sessions: make(map[string]Checkout),
+	}
+}
+
+func (f *Fake) Name() string {
+	return "fake"
+}
+
+func (f *Fake) Checkout(ctx context.Context, c Checkout) (Session, error) {
+	ref, err := randomID("fake_")
+	if err != nil {
+		return Session{}, err
+	}
+
+	f.mu.Lock()
+	f.sessions[ref] = c
+	f.mu.Unlock()
+
+	return Session{Reference: ref, URL: "/payments/fake/" + ref}, nil
+}
+
+// Session returns the checkout behind a session reference.
+func (f *Fake) Session(ref string) (Checkout, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.sessions[ref]
+	if !ok {
+		return Checkout{}, ErrUnknownSession
+	}
+	return c, nil
+}
+
+// Complete ends a session and returns the signed webhook request it
+// produces, as body and headers.
+func (f *Fake) Complete(ref string, paid bool) ([]byte, http.Header, error) {
+	c, err := f.Session(ref)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	id, err := randomID("evt_")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	event := Event{ID: id, Type: Failed, Reference: ref, AmountCents: c.AmountCents, Currency: c.Currency}
+	if paid {
+		event.Type = Succeeded
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	f.mu.Lock()
+	delete(f.sessions, ref)
+	f.mu.Unlock()
+
+	header := make(http.Header)
+	header.Set(FakeSignatureHeader, f.sign(payload))
+	return payload, header, nil
+}
+
+func (f *Fake) ParseEvent(payload []byte, header http.Header) (Event, error) {
+	if !hmac.Equal([]byte(f.sign(payload)), []byte(header.Get(FakeSignatureHeader))) {
+		return Event{}, ErrBadSignature
+	}
+
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return Event{}, fmt.Errorf("%w: %s", ErrBadEvent, err)
+	}
+	if event.ID == "" || event.Reference == "" || (event.Type != Succeeded && event.Type != Failed) {
+		return Event{}, ErrBadEvent
+	}
+
+	return event, nil
+}
+
+// sign is the hex HMAC-SHA256 of payload.
+func (f *Fake) sign(payload []byte) string {
+	mac := hmac.New(sha256.New, f.secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func randomID(prefix string) (string, error) {
+	raw := make([]byte, 12)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to create id: %w", err)
+	}
+	return prefix + hex.EncodeToString(raw), nil
+}
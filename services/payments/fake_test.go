@@ -0,0 +1,36 @@
+package payments
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFake(t *testing.T) {
+	fake := NewFake("secret")
+
+	session, err := fake.Checkout(context.Background(), Checkout{OrderID: 7, AmountCents: 4900, Currency: "USD"})
+	require.NoError(t, err)
+	assert.Equal(t, "/payments/fake/"+session.Reference, session.URL)
+
+	payload, header, err := fake.Complete(session.Reference, true)
+	require.NoError(t, err)
+
+	event, err := fake.ParseEvent(payload, header)
+	require.NoError(t, err)
+	assert.Equal(t, Succeeded, event.Type)
+	assert.Equal(t, session.Reference, event.Reference)
+	assert.Equal(t, int64(4900), event.AmountCents)
+	assert.NotEmpty(t, event.ID)
+
+	_, err = fake.ParseEvent(payload, http.Header{FakeSignatureHeader: {"forged"}})
+	assert.ErrorIs(t, err, ErrBadSignature)
+	_, err = NewFake("other secret").ParseEvent(payload, header)
+	assert.ErrorIs(t, err, ErrBadSignature)
+
+	_, _, err = fake.Complete(session.Reference, true)
+	assert.ErrorIs(t, err, ErrUnknownSession, "sessions complete once")
+}
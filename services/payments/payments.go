@@ -0,0 +1,81 @@
+// Package payments takes card payments through a payment provider. The
+// board only ever redirects to the provider's checkout and learns about
+// the outcome from signed webhook events.
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	ErrBadSignature    = errors.New("webhook signature does not match")
+	ErrBadEvent        = errors.New("webhook event is malformed")
+	ErrUnknownProvider = errors.New("unknown payment provider")
+	ErrFakeNotAllowed  = errors.New("the fake payment provider charges nothing; set ALLOW_FAKE_PAYMENTS=true to use it in development")
+)
+
+// EventType is the outcome a webhook event reports.
+type EventType string
+
+const (
+	Succeeded EventType = "payment.succeeded"
+	Failed    EventType = "payment.failed"
+)
+
+// Checkout is a payment the board asks the provider to collect.
+type Checkout struct {
+	OrderID     int64
+	Description string
+	AmountCents int64
+	Currency    string
+	// ReturnURL is where the provider sends the buyer afterwards.
+	ReturnURL string
+}
+
+// Session is a started checkout. Reference identifies the payment in
+// webhook events and URL is where the buyer pays.
+type Session struct {
+	Reference string
+	URL       string
+}
+
+// Event is a verified webhook event. Providers may deliver the same event
+// more than once; ID tells the copies apart from new events.
+type Event struct {
+	ID          string    `json:"id"`
+	Type        EventType `json:"type"`
+	Reference   string    `json:"reference"`
+	AmountCents int64     `json:"amount_cents"`
+	Currency    string    `json:"currency"`
+}
+
+// Provider is a payment provider.
+type Provider interface {
+	Name() string
+	// Checkout starts collecting a payment.
+	Checkout(ctx context.Context, c Checkout) (Session, error)
+	// ParseEvent verifies a webhook body against the signature in its
+	// headers.
+	ParseEvent(payload []byte, header http.Header) (Event, error)
+}
+
+// New returns the provider called name, signing webhook events with
+// secret. With no name it returns nil: paid features are then off. The
+// fake provider lets anyone mark an order paid, so it is only returned if
+// allowFake is set.
+func New(name, secret string, allowFake bool) (Provider, error) {
+	switch name {
+	case "":
+		return nil, nil
+	case "fake":
+		if !allowFake {
+			return nil, ErrFakeNotAllowed
+		}
+		return NewFake(secret), nil
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownProvider, name)
+	}
+}
@@ -0,0 +1,40 @@
+package payments
+
+import (
+	"htmxjb/config"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	provider, err := New("", "", false)
+	require.NoError(t, err)
+	assert.Nil(t, provider, "no provider turns paid features off")
+
+	_, err = New("fake", "", false)
+	assert.ErrorIs(t, err, ErrFakeNotAllowed)
+	provider, err = New("fake", "secret", true)
+	require.NoError(t, err)
+	assert.Equal(t, "fake", provider.Name())
+
+	_, err = New("stripe", "", true)
+	assert.ErrorIs(t, err, ErrUnknownProvider)
+}
+
+// TestNewDefaultConfig checks the board starts with nothing configured.
+func TestNewDefaultConfig(t *testing.T) {
+	for _, key := range []string{"PAYMENT_PROVIDER", "PAYMENT_WEBHOOK_SECRET", "ALLOW_FAKE_PAYMENTS"} {
+		if value, ok := os.LookupEnv(key); ok {
+			t.Setenv(key, value)
+			os.Unsetenv(key)
+		}
+	}
+
+	cfg := config.Load()
+	provider, err := New(cfg.PaymentProvider, cfg.PaymentWebhookSecret, cfg.AllowFakePayments)
+	require.NoError(t, err)
+	assert.Nil(t, provider)
+}
@@ -0,0 +1,476 @@
+package services
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"htmxjb/db"
+	"htmxjb/models/domain"
+	"htmxjb/services/events"
+	"strings"
+	"time"
+)
+
+// defaultPostingTTL is how long a posting stays up when the employer sets
+// no expiry date.
+const defaultPostingTTL = 30 * 24 * time.Hour
+
+var (
+	ErrPostingIncomplete = errors.New("the posting is not ready to publish")
+	ErrPostingClosed     = errors.New("closed postings cannot be changed")
+	ErrExpiryBeforeLive  = errors.New("the expiry date must be after the go-live date")
+)
+
+// IncompleteError is ErrPostingIncomplete with what the posting is
+// missing, as Problems lists it, so each can be translated.
+type IncompleteError struct {
+	Problems []string
+}
+
+func (e *IncompleteError) Error() string {
+	return fmt.Sprintf("%s: add %s", ErrPostingIncomplete, strings.Join(e.Problems, ", "))
+}
+
+func (e *IncompleteError) Unwrap() error {
+	return ErrPostingIncomplete
+}
+
+// Posting is a job an employer writes on the board. It is a jobs row from
+// the Direct source, owned by the employer who created it.
+type Posting struct {
+	ID          int
+	Status      domain.JobStatus
+	Title       string
+	Department  string
+	Employment  domain.EmploymentType
+	Workplace   domain.JobType
+	Description string
+	Location    string
+	Salary      string
+	PublishAt   time.Time
+	ExpiresAt   time.Time
+	UpdatedAt   time.Time
+
+	// Ends of the paid feature windows, if any were bought.
+	PinnedUntil      time.Time
+	HighlightedUntil time.Time
+
+	// Applications is how many candidates applied on the board.
+	Applications int
+}
+
+// Problems lists what is missing before the posting can be published.
+func (p Posting) Problems() []string {
+	var problems []string
+	if strings.TrimSpace(p.Title) == "" {
+		problems = append(problems, "a title")
+	}
+	if strings.TrimSpace(p.Description) == "" {
+		problems = append(problems, "a description")
+	}
+	if strings.TrimSpace(p.Location) == "" && p.Workplace != domain.Remote {
+		problems = append(problems, "a location, or remote work")
+	}
+	return problems
+}
+
+// Editable reports whether the employer may still change the posting.
+func (p Posting) Editable() bool {
+	return p.Status == domain.Draft || p.Status == domain.Scheduled || p.Status == domain.Active
+}
+
+// Revision is one field changed by a save.
+type Revision struct {
+	Field     string
+	Old       string
+	New       string
+	ChangedAt time.Time
+}
+
+// PostingService keeps employers' postings. Every query is scoped to the
+// employer, so nobody can read or change another employer's postings.
+type PostingService struct {
+	JobStore  db.Store
+	Jobs      *JobServices
+	Enrichers []JobEnricher
+	Events    *events.Bus
+	Now       func() time.Time
+}
+
+// NewPostingService runs enrichers, e.g. salary and location parsing, on
+// every save.
+func NewPostingService(jobStore db.Store, enrichers []JobEnricher) *PostingService {
+	return &PostingService{
+		JobStore:  jobStore,
+		Jobs:      NewJobServices(Job{}, jobStore),
+		Enrichers: enrichers,
+		Now:       time.Now,
+	}
+}
+
+// WithEvents announces postings on bus as they go live.
+func (ps *PostingService) WithEvents(bus *events.Bus) *PostingService {
+	ps.Events = bus
+	return ps
+}
+
+const postingColumns = "id, status, title, department, employment_type, type, description, location_text, salary_text, publish_at, expires_at, updated_at, pinned_until, highlighted_until, " +
+	"(SELECT COUNT(*) FROM applications WHERE job_id = jobs.id) AS applications"
+
+func scanPosting(row interface{ Scan(...interface{}) error }) (Posting, error) {
+	var (
+		p                                    Posting
+		department, description, loc, salary sql.NullString
+		publishAt, expiresAt, updatedAt      sql.NullTime
+		pinnedUntil, highlightedUntil        sql.NullTime
+	)
+	err := row.Scan(&p.ID, &p.Status, &p.Title, &department, &p.Employment, &p.Workplace,
+		&description, &loc, &salary, &publishAt, &expiresAt, &updatedAt, &pinnedUntil, &highlightedUntil, &p.Applications)
+	if err != nil {
+		return Posting{}, err
+	}
+
+	p.Department = department.String
+	p.Description = description.String
+	p.Location = loc.String
+	p.Salary = salary.String
+	p.PublishAt = publishAt.Time
+	p.ExpiresAt = expiresAt.Time
+	p.UpdatedAt = updatedAt.Time
+	p.PinnedUntil = pinnedUntil.Time
+	p.HighlightedUntil = highlightedUntil.Time
+
+	return p, nil
+}
+
+// Postings returns the employer's postings, most recently changed first.
+func (ps *PostingService) Postings(employerID int) ([]Posting, error) {
+	rows, err := ps.JobStore.Query(
+		"SELECT "+postingColumns+" FROM jobs WHERE employer_id = ? AND source = ? ORDER BY updated_at DESC, id DESC",
+		employerID,
+		domain.Direct,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get postings: %w", err)
+	}
+	defer rows.Close()
+
+	var postings []Posting
+	for rows.Next() {
+		p, err := scanPosting(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan posting: %w", err)
+		}
+		postings = append(postings, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating postings: %w", err)
+	}
+
+	return postings, nil
+}
+
+// Posting returns one of the employer's postings. Postings of other
+// employers are reported as not found.
+func (ps *PostingService) Posting(employerID, id int) (Posting, error) {
+	p, err := scanPosting(ps.JobStore.QueryRow(
+		"SELECT "+postingColumns+" FROM jobs WHERE id = ? AND employer_id = ? AND source = ?",
+		id,
+		employerID,
+		domain.Direct,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Posting{}, ErrJobNotFound
+	}
+	if err != nil {
+		return Posting{}, fmt.Errorf("failed to get posting %d: %w", id, err)
+	}
+	return p, nil
+}
+
+// CreateDraft starts an empty draft for the employer's company.
+func (ps *PostingService) CreateDraft(employer Employer) (Posting, error) {
+	raw := make([]byte, 8)
+	if _, err := rand.Read(raw); err != nil {
+		return Posting{}, fmt.Errorf("failed to create posting: %w", err)
+	}
+
+	now := sqlTime(ps.Now())
+	res, err := ps.JobStore.Exec(
+		"INSERT INTO jobs (external_id, title, type, source, status, employer_id, company_id, created_at, updated_at)"+
+			" VALUES (?, '', ?, ?, ?, ?, ?, ?, ?)",
+		hex.EncodeToString(raw),
+		domain.UnknownJobType,
+		domain.Direct,
+		domain.Draft,
+		employer.ID,
+		nullInt(int64(employer.CompanyID)),
+		now,
+		now,
+	)
+	if err != nil {
+		return Posting{}, fmt.Errorf("failed to create posting: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return Posting{}, fmt.Errorf("failed to create posting: %w", err)
+	}
+
+	return ps.Posting(employer.ID, int(id))
+}
+
+// Save stores the posting's fields and records what changed in its
+// history. Published postings change live.
+func (ps *PostingService) Save(employerID int, p Posting) error {
+	current, err := ps.Posting(employerID, p.ID)
+	if err != nil {
+		return err
+	}
+	if !current.Editable() {
+		return ErrPostingClosed
+	}
+
+	job := domain.Job{
+		ID:          int64(p.ID),
+		Title:       strings.TrimSpace(p.Title),
+		Description: strings.TrimSpace(p.Description),
+		Department:  strings.TrimSpace(p.Department),
+		Employment:  p.Employment,
+		Type:        p.Workplace,
+		Source:      domain.Direct,
+		Salary:      domain.Salary{Raw: strings.TrimSpace(p.Salary)},
+		Location:    domain.Location{Raw: strings.TrimSpace(p.Location), Remote: p.Workplace == domain.Remote},
+	}
+	for _, enricher := range ps.Enrichers {
+		enricher.Enrich(&job)
+	}
+
+	now := ps.Now()
+	saved := p
+	saved.Title, saved.Description, saved.Department = job.Title, job.Description, job.Department
+	saved.Salary, saved.Location = job.Salary.Raw, job.Location.Raw
+
+	// The posting, its tags and the record of the change are written
+	// together, so a failure leaves none of them behind.
+	return ps.JobStore.WithTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+    UPDATE jobs SET
+      title = ?, description = ?, department = ?, employment_type = ?, type = ?,
+      salary_text = ?, salary_min = ?, salary_max = ?, salary_currency = ?, salary_period = ?, salary_annual_min = ?, salary_annual_max = ?,
+      location_text = ?, city = ?, region = ?, country_code = ?, latitude = ?, longitude = ?, is_remote = ?,
+      updated_at = ?
+    WHERE id = ? AND employer_id = ?`,
+			job.Title,
+			nullString(job.Description),
+			nullString(job.Department),
+			job.Employment,
+			job.Type,
+			nullString(job.Salary.Raw),
+			nullFloat(job.Salary.Min),
+			nullFloat(job.Salary.Max),
+			nullString(job.Salary.Currency),
+			job.Salary.Period,
+			nullFloat(job.Salary.AnnualMin),
+			nullFloat(job.Salary.AnnualMax),
+			nullString(job.Location.Raw),
+			nullString(job.Location.City),
+			nullString(job.Location.Region),
+			nullString(job.Location.CountryCode),
+			nullCoordinate(job.Location, job.Location.Latitude),
+			nullCoordinate(job.Location, job.Location.Longitude),
+			job.Location.Remote,
+			sqlTime(now),
+			p.ID,
+			employerID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save posting %d: %w", p.ID, err)
+		}
+
+		if err := setTags(tx, job.ID, job.Tags); err != nil {
+			return err
+		}
+
+		return record(tx, employerID, p.ID, now, changes(current, saved)...)
+	})
+}
+
+// Publish puts the posting live at publishAt, or now if that has passed,
+// until expiresAt. A zero expiry keeps it up for defaultPostingTTL.
+// Publishing a live posting again only moves its expiry date.
+func (ps *PostingService) Publish(employerID, id int, publishAt, expiresAt time.Time) error {
+	p, err := ps.Posting(employerID, id)
+	if err != nil {
+		return err
+	}
+	if !p.Editable() {
+		return ErrPostingClosed
+	}
+	if problems := p.Problems(); len(problems) > 0 {
+		return &IncompleteError{Problems: problems}
+	}
+
+	now := ps.Now()
+	status := domain.Scheduled
+	if p.Status == domain.Active || !publishAt.After(now) {
+		status, publishAt = domain.Active, now
+	}
+	if p.Status == domain.Active {
+		publishAt = p.PublishAt
+	}
+	if expiresAt.IsZero() {
+		expiresAt = publishAt.Add(defaultPostingTTL)
+	}
+	if !expiresAt.After(publishAt) {
+		return ErrExpiryBeforeLive
+	}
+
+	// A posting lists as new from the moment it goes live.
+	_, err = ps.JobStore.Exec(
+		"UPDATE jobs SET status = ?, publish_at = ?, expires_at = ?, last_seen_at = ?,"+
+			" created_at = CASE WHEN status = ? THEN created_at ELSE ? END, updated_at = ?"+
+			" WHERE id = ? AND employer_id = ?",
+		status,
+		sqlTime(publishAt),
+		sqlTime(expiresAt),
+		sqlTime(now),
+		domain.Active,
+		sqlTime(publishAt),
+		sqlTime(now),
+		id,
+		employerID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to publish posting %d: %w", id, err)
+	}
+	if ps.Events != nil && status == domain.Active && p.Status != domain.Active {
+		ps.Events.Publish(events.JobCreated{JobID: id})
+	}
+
+	published := p
+	published.Status, published.PublishAt, published.ExpiresAt = status, publishAt, expiresAt
+	return record(ps.JobStore, employerID, id, now, changes(p, published)...)
+}
+
+// Close takes a posting down for good.
+func (ps *PostingService) Close(employerID, id int) error {
+	p, err := ps.Posting(employerID, id)
+	if err != nil {
+		return err
+	}
+	if !p.Editable() {
+		return ErrPostingClosed
+	}
+
+	now := ps.Now()
+	_, err = ps.JobStore.Exec(
+		"UPDATE jobs SET status = ?, closed_at = ?, updated_at = ? WHERE id = ? AND employer_id = ?",
+		domain.Closed,
+		sqlTime(now),
+		sqlTime(now),
+		id,
+		employerID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to close posting %d: %w", id, err)
+	}
+
+	closed := p
+	closed.Status = domain.Closed
+	return record(ps.JobStore, employerID, id, now, changes(p, closed)...)
+}
+
+// History returns the posting's changes, newest first.
+func (ps *PostingService) History(employerID, id int) ([]Revision, error) {
+	if _, err := ps.Posting(employerID, id); err != nil {
+		return nil, err
+	}
+
+	rows, err := ps.JobStore.Query(
+		"SELECT field, old_value, new_value, changed_at FROM job_revisions WHERE job_id = ? ORDER BY changed_at DESC, id DESC",
+		id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get posting history: %w", err)
+	}
+	defer rows.Close()
+
+	var history []Revision
+	for rows.Next() {
+		var (
+			r        Revision
+			old, new sql.NullString
+		)
+		if err := rows.Scan(&r.Field, &old, &new, &r.ChangedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan revision: %w", err)
+		}
+		r.Old, r.New = old.String, new.String
+		history = append(history, r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating posting history: %w", err)
+	}
+
+	return history, nil
+}
+
+// execer runs statements, on the store or within a transaction.
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// record keeps the revisions of a posting for its history.
+func record(store execer, employerID, id int, at time.Time, revisions ...Revision) error {
+	for _, r := range revisions {
+		_, err := store.Exec(
+			"INSERT INTO job_revisions (job_id, employer_id, field, old_value, new_value, changed_at) VALUES (?, ?, ?, ?, ?, ?)",
+			id,
+			employerID,
+			r.Field,
+			nullString(r.Old),
+			nullString(r.New),
+			sqlTime(at),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to record change to posting %d: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// changes lists the fields that differ between two versions of a posting.
+func changes(before, after Posting) []Revision {
+	var revisions []Revision
+	add := func(field, old, new string) {
+		if old != new {
+			revisions = append(revisions, Revision{Field: field, Old: old, New: new})
+		}
+	}
+
+	add("status", before.Status.String(), after.Status.String())
+	add("title", before.Title, after.Title)
+	add("department", before.Department, after.Department)
+	add("employment", knownOrEmpty(before.Employment, before.Employment != domain.UnknownEmployment),
+		knownOrEmpty(after.Employment, after.Employment != domain.UnknownEmployment))
+	add("workplace", knownOrEmpty(before.Workplace, before.Workplace != domain.UnknownJobType),
+		knownOrEmpty(after.Workplace, after.Workplace != domain.UnknownJobType))
+	add("description", before.Description, after.Description)
+	add("location", before.Location, after.Location)
+	add("salary", before.Salary, after.Salary)
+	add("go-live", formatDate(before.PublishAt), formatDate(after.PublishAt))
+	add("expiry", formatDate(before.ExpiresAt), formatDate(after.ExpiresAt))
+
+	return revisions
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04")
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"htmxjb/models/domain"
+	"htmxjb/services/events"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostings(t *testing.T) {
+	store := openTestStore(t)
+	clk := &clock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	es := NewEmployerService(store, NewCompanyService(store))
+	ps := NewPostingService(store, nil)
+	ps.Now = clk.Now
+	bus := events.NewBus()
+	created, unsubscribe := bus.Subscribe(10)
+	defer unsubscribe()
+	retention := NewRetentionService(store, RetentionPolicy{}).WithEvents(bus)
+	retention.Now = clk.Now
+	jobs := NewJobServices(Job{}, store)
+
+	ann, err := es.SignUp("ann@acme.example", "Ann", "Acme", "correct horse battery")
+	require.NoError(t, err)
+	eve, err := es.SignUp("eve@evil.example", "Eve", "Evil Corp", "correct horse battery")
+	require.NoError(t, err)
+
+	draft, err := ps.CreateDraft(ann)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Draft, draft.Status)
+	assert.Equal(t, domain.UnknownJobType, draft.Workplace)
+
+	err = ps.Publish(ann.ID, draft.ID, time.Time{}, time.Time{})
+	assert.ErrorIs(t, err, ErrPostingIncomplete)
+	assert.ErrorContains(t, err, "a title, a description, a location, or remote work")
+	var incomplete *IncompleteError
+	require.ErrorAs(t, err, &incomplete)
+	assert.Equal(t, []string{"a title", "a description", "a location, or remote work"}, incomplete.Problems)
+
+	draft.Title = " Go Developer "
+	draft.Description = "Build the board."
+	draft.Workplace = domain.Onsite
+	draft.Location = "Berlin"
+	require.NoError(t, ps.Save(ann.ID, draft))
+
+	listed, err := jobs.GetAllJobs()
+	require.NoError(t, err)
+	assert.Empty(t, listed, "drafts are not listed")
+
+	t.Run("ownership", func(t *testing.T) {
+		_, err := ps.Posting(eve.ID, draft.ID)
+		assert.ErrorIs(t, err, ErrJobNotFound)
+
+		stolen := draft
+		stolen.Title = "Hacked"
+		assert.ErrorIs(t, ps.Save(eve.ID, stolen), ErrJobNotFound)
+		assert.ErrorIs(t, ps.Publish(eve.ID, draft.ID, time.Time{}, time.Time{}), ErrJobNotFound)
+		assert.ErrorIs(t, ps.Close(eve.ID, draft.ID), ErrJobNotFound)
+		_, err = ps.History(eve.ID, draft.ID)
+		assert.ErrorIs(t, err, ErrJobNotFound)
+
+		postings, err := ps.Postings(eve.ID)
+		require.NoError(t, err)
+		assert.Empty(t, postings)
+
+		saved, err := ps.Posting(ann.ID, draft.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Go Developer", saved.Title)
+	})
+
+	t.Run("scheduled", func(t *testing.T) {
+		goLive := clk.Now().Add(48 * time.Hour)
+		assert.ErrorIs(t, ps.Publish(ann.ID, draft.ID, goLive, goLive.Add(-time.Hour)), ErrExpiryBeforeLive)
+		require.NoError(t, ps.Publish(ann.ID, draft.ID, goLive, time.Time{}))
+
+		scheduled, err := ps.Posting(ann.ID, draft.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.Scheduled, scheduled.Status)
+		assert.Equal(t, goLive.Add(defaultPostingTTL), scheduled.ExpiresAt.UTC())
+
+		clk.Advance(24 * time.Hour)
+		require.NoError(t, retention.Sweep())
+		listed, err := jobs.GetAllJobs()
+		require.NoError(t, err)
+		assert.Empty(t, listed)
+
+		clk.Advance(24 * time.Hour)
+		published, err := retention.PublishDue()
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, published)
+		require.Len(t, created, 1, "going live is announced")
+		assert.Equal(t, draft.ID, (<-created).JobID)
+		listed, err = jobs.GetAllJobs()
+		require.NoError(t, err)
+		require.Len(t, listed, 1)
+		assert.Equal(t, "Go Developer", listed[0].Title)
+		assert.Equal(t, "Acme", listed[0].Company)
+
+		var createdAt time.Time
+		require.NoError(t, store.QueryRow("SELECT created_at FROM jobs WHERE id = ?", draft.ID).Scan(&createdAt))
+		assert.Equal(t, goLive, createdAt.UTC(), "listed as new from its go-live")
+	})
+
+	t.Run("live edits and expiry", func(t *testing.T) {
+		live, err := ps.Posting(ann.ID, draft.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.Active, live.Status)
+
+		live.Salary = "€60,000 - €70,000"
+		require.NoError(t, ps.Save(ann.ID, live))
+
+		expires := clk.Now().Add(7 * 24 * time.Hour)
+		require.NoError(t, ps.Publish(ann.ID, draft.ID, time.Time{}, expires))
+
+		clk.Advance(8 * 24 * time.Hour)
+		require.NoError(t, retention.Sweep())
+		expired, err := ps.Posting(ann.ID, draft.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.Closed, expired.Status)
+		assert.False(t, expired.Editable())
+
+		expired.Title = "Reopened"
+		assert.ErrorIs(t, ps.Save(ann.ID, expired), ErrPostingClosed)
+	})
+
+	t.Run("history", func(t *testing.T) {
+		history, err := ps.History(ann.ID, draft.ID)
+		require.NoError(t, err)
+
+		var fields []string
+		for _, r := range history {
+			fields = append(fields, r.Field)
+		}
+		assert.Equal(t, []string{"expiry", "salary", "expiry", "go-live", "status", "location", "description", "workplace", "title"}, fields)
+		assert.Equal(t, Revision{Field: "status", Old: "draft", New: "scheduled", ChangedAt: history[4].ChangedAt}, history[4])
+		assert.Equal(t, Revision{Field: "title", New: "Go Developer", ChangedAt: history[8].ChangedAt}, history[8])
+	})
+}
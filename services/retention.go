@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"htmxjb/db"
+	"htmxjb/models/domain"
+	"htmxjb/services/blob"
+	"htmxjb/services/events"
+	"log"
+	"time"
+)
+
+// RetentionPolicy controls how long jobs stay in each lifecycle state.
+// Active jobs close once they pass their source TTL without being seen,
+// closed jobs are archived after ArchiveAfter and archived jobs are deleted
+// after PurgeAfter. A zero duration disables that step.
+type RetentionPolicy struct {
+	TTL          map[domain.JobSource]time.Duration
+	ArchiveAfter time.Duration
+	PurgeAfter   time.Duration
+}
+
+func (p RetentionPolicy) TTLFor(source domain.JobSource) time.Duration {
+	return p.TTL[source]
+}
+
+type RetentionService struct {
+	JobStore db.Store
+	Policy   RetentionPolicy
+	Events   *events.Bus
+	Blobs    blob.Store
+	Now      func() time.Time
+}
+
+func NewRetentionService(jobStore db.Store, policy RetentionPolicy) *RetentionService {
+	return &RetentionService{
+		JobStore: jobStore,
+		Policy:   policy,
+		Now:      time.Now,
+	}
+}
+
+// WithEvents announces scheduled postings on bus as they go live.
+func (rs *RetentionService) WithEvents(bus *events.Bus) *RetentionService {
+	rs.Events = bus
+	return rs
+}
+
+// WithBlobs deletes the resumes sent to purged jobs from blobs.
+func (rs *RetentionService) WithBlobs(blobs blob.Store) *RetentionService {
+	rs.Blobs = blobs
+	return rs
+}
+
+// CloseMissing closes active jobs from source that were not seen since the
+// given time, i.e. that disappeared from the source during a full ingest.
+func (rs *RetentionService) CloseMissing(source domain.JobSource, since time.Time) (int64, error) {
+	res, err := rs.JobStore.Exec(
+		"UPDATE jobs SET status = ?, closed_at = ? WHERE source = ? AND status = ? AND (last_seen_at IS NULL OR last_seen_at < ?)",
+		domain.Closed,
+		sqlTime(rs.Now()),
+		source,
+		domain.Active,
+		sqlTime(since),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to close missing %s jobs: %w", source, err)
+	}
+
+	return res.RowsAffected()
+}
+
+// PublishDue puts scheduled postings live once their publish time has
+// come. They list as new from that moment.
+func (rs *RetentionService) PublishDue() (int64, error) {
+	now := sqlTime(rs.Now())
+
+	ids, err := execReturningIDs(rs.JobStore,
+		"UPDATE jobs SET status = ?, created_at = publish_at, last_seen_at = ?, updated_at = ? WHERE status = ? AND publish_at <= ? RETURNING id",
+		domain.Active,
+		now,
+		now,
+		domain.Scheduled,
+		now,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to publish scheduled jobs: %w", err)
+	}
+
+	if rs.Events != nil {
+		for _, id := range ids {
+			rs.Events.Publish(events.JobCreated{JobID: id})
+		}
+	}
+
+	return int64(len(ids)), nil
+}
+
+// ExpireStale closes active jobs whose TTL has run out.
+func (rs *RetentionService) ExpireStale() (int64, error) {
+	now := sqlTime(rs.Now())
+
+	res, err := rs.JobStore.Exec(
+		"UPDATE jobs SET status = ?, closed_at = ? WHERE status = ? AND expires_at IS NOT NULL AND expires_at < ?",
+		domain.Closed,
+		now,
+		domain.Active,
+		now,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire jobs: %w", err)
+	}
+
+	return res.RowsAffected()
+}
+
+// ArchiveClosed archives jobs that have been closed for longer than
+// ArchiveAfter, hiding them from the job list.
+func (rs *RetentionService) ArchiveClosed() (int64, error) {
+	if rs.Policy.ArchiveAfter <= 0 {
+		return 0, nil
+	}
+
+	res, err := rs.JobStore.Exec(
+		"UPDATE jobs SET status = ? WHERE status = ? AND closed_at < ?",
+		domain.Archived,
+		domain.Closed,
+		sqlTime(rs.Now().Add(-rs.Policy.ArchiveAfter)),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to archive jobs: %w", err)
+	}
+
+	return res.RowsAffected()
+}
+
+// Purge deletes archived jobs closed longer than PurgeAfter ago, along
+// with the resumes sent to them.
+func (rs *RetentionService) Purge() (int64, error) {
+	if rs.Policy.PurgeAfter <= 0 {
+		return 0, nil
+	}
+
+	var (
+		purged int64
+		keys   []string
+	)
+	where := " WHERE status = ? AND closed_at < ?"
+	args := []interface{}{domain.Archived, sqlTime(rs.Now().Add(-rs.Policy.PurgeAfter))}
+	err := rs.JobStore.WithTx(func(tx *sql.Tx) error {
+		rows, err := tx.Query("SELECT resume_key FROM applications WHERE job_id IN (SELECT id FROM jobs"+where+")", args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var key string
+			if err := rows.Scan(&key); err != nil {
+				return err
+			}
+			keys = append(keys, key)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		res, err := tx.Exec("DELETE FROM jobs"+where, args...)
+		if err != nil {
+			return err
+		}
+		purged, err = res.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge jobs: %w", err)
+	}
+
+	// The rows are gone, so a resume left behind here is only wasted space.
+	if rs.Blobs != nil {
+		for _, key := range keys {
+			if err := rs.Blobs.Delete(context.Background(), key); err != nil {
+				log.Printf("🔥 failed to delete resume %s: %v", key, err)
+			}
+		}
+	}
+
+	return purged, nil
+}
+
+// Sweep runs scheduled publishing, expiry, archival and purge in order.
+func (rs *RetentionService) Sweep() error {
+	published, err := rs.PublishDue()
+	if err != nil {
+		return err
+	}
+
+	expired, err := rs.ExpireStale()
+	if err != nil {
+		return err
+	}
+
+	archived, err := rs.ArchiveClosed()
+	if err != nil {
+		return err
+	}
+
+	purged, err := rs.Purge()
+	if err != nil {
+		return err
+	}
+
+	if published+expired+archived+purged > 0 {
+		log.Printf("✅ Retention sweep: %d published, %d expired, %d archived, %d purged", published, expired, archived, purged)
+	}
+
+	return nil
+}
+
+// publishInterval is how often Run checks for scheduled postings, so
+// they go live within a minute of their publish time.
+const publishInterval = time.Minute
+
+// Run sweeps every interval, and publishes due postings every minute,
+// until ctx is cancelled.
+func (rs *RetentionService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	publish := time.NewTicker(publishInterval)
+	defer publish.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-publish.C:
+			published, err := rs.PublishDue()
+			if err != nil {
+				log.Printf("🔥 scheduled publishing failed: %s", err)
+			} else if published > 0 {
+				log.Printf("✅ Published %d scheduled jobs", published)
+			}
+		case <-ticker.C:
+			if err := rs.Sweep(); err != nil {
+				log.Printf("🔥 retention sweep failed: %s", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"htmxjb/db"
+	"htmxjb/models/domain"
+	"htmxjb/services/blob"
+	"htmxjb/services/events"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubFetcher struct {
+	source domain.JobSource
+	jobs   []domain.Job
+	err    error
+}
+
+func (f *stubFetcher) Source() domain.JobSource {
+	return f.source
+}
+
+func (f *stubFetcher) FetchJobs(ctx context.Context) ([]domain.Job, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return append([]domain.Job(nil), f.jobs...), nil
+}
+
+// windowFetcher returns only part of its source's postings.
+type windowFetcher struct {
+	stubFetcher
+}
+
+func (f *windowFetcher) Complete() bool {
+	return false
+}
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func openTestStore(t *testing.T) *db.SQLiteStore {
+	t.Helper()
+
+	store, err := db.Open(filepath.Join(t.TempDir(), "jobs.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	return store
+}
+
+func jobStatuses(t *testing.T, store db.Store) map[string]domain.JobStatus {
+	t.Helper()
+
+	rows, err := store.Query("SELECT external_id, status FROM jobs")
+	require.NoError(t, err)
+	defer rows.Close()
+
+	statuses := make(map[string]domain.JobStatus)
+	for rows.Next() {
+		var (
+			id     string
+			status domain.JobStatus
+		)
+		require.NoError(t, rows.Scan(&id, &status))
+		statuses[id] = status
+	}
+	require.NoError(t, rows.Err())
+
+	return statuses
+}
+
+func TestIngestLifecycle(t *testing.T) {
+	store := openTestStore(t)
+	clk := &clock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+
+	retention := NewRetentionService(store, RetentionPolicy{
+		TTL:          map[domain.JobSource]time.Duration{domain.Csv: 48 * time.Hour},
+		ArchiveAfter: 24 * time.Hour,
+		PurgeAfter:   72 * time.Hour,
+	})
+	retention.Now = clk.Now
+	disk, err := blob.NewDisk(t.TempDir())
+	require.NoError(t, err)
+	retention.WithBlobs(disk)
+
+	fetcher := &stubFetcher{
+		source: domain.Csv,
+		jobs: []domain.Job{
+			{ExternalID: "a", Title: "Go Developer"},
+			{ExternalID: "b", Title: "Rust Developer"},
+		},
+	}
+	ingestor := NewIngestor(NewJobServices(Job{}, store), retention, nil, fetcher)
+
+	require.NoError(t, ingestor.RunOnce(context.Background()))
+	assert.Equal(t, map[string]domain.JobStatus{"a": domain.Active, "b": domain.Active}, jobStatuses(t, store))
+
+	t.Run("missing postings close on the next full ingest", func(t *testing.T) {
+		clk.Advance(time.Hour)
+		fetcher.jobs = fetcher.jobs[:1]
+
+		require.NoError(t, ingestor.RunOnce(context.Background()))
+		assert.Equal(t, map[string]domain.JobStatus{"a": domain.Active, "b": domain.Closed}, jobStatuses(t, store))
+	})
+
+	t.Run("closed jobs are still listed with a closed flag", func(t *testing.T) {
+		jobs, err := NewJobServices(Job{}, store).GetAllJobs()
+		require.NoError(t, err)
+		require.Len(t, jobs, 2)
+		assert.False(t, jobs[0].IsClosed)
+		assert.True(t, jobs[1].IsClosed)
+	})
+
+	t.Run("ttl expiry, archival and purge", func(t *testing.T) {
+		// A resume sent to the closed job goes with it.
+		ctx := context.Background()
+		require.NoError(t, disk.Put(ctx, "resumes/b.pdf", strings.NewReader("%PDF-1.7")))
+		_, err := store.Exec(
+			"INSERT INTO applications (job_id, name, email, cover_letter, resume_key, resume_name, resume_type, resume_size, created_at)"+
+				" SELECT id, 'Bob', 'bob@example.com', '', 'resumes/b.pdf', 'b.pdf', 'application/pdf', 8, ? FROM jobs WHERE external_id = 'b'",
+			sqlTime(clk.Now()),
+		)
+		require.NoError(t, err)
+
+		clk.Advance(49 * time.Hour)
+		require.NoError(t, retention.Sweep())
+		assert.Equal(t, map[string]domain.JobStatus{"a": domain.Closed, "b": domain.Archived}, jobStatuses(t, store))
+
+		clk.Advance(25 * time.Hour)
+		require.NoError(t, retention.Sweep())
+		assert.Equal(t, map[string]domain.JobStatus{"a": domain.Archived}, jobStatuses(t, store))
+
+		_, err = disk.Open(ctx, "resumes/b.pdf")
+		assert.ErrorIs(t, err, blob.ErrNotFound)
+	})
+
+	t.Run("reappearing posting is reopened", func(t *testing.T) {
+		require.NoError(t, ingestor.RunOnce(context.Background()))
+		assert.Equal(t, map[string]domain.JobStatus{"a": domain.Active}, jobStatuses(t, store))
+	})
+}
+
+func TestIngestKeepsJobsMissingFromPartialFetch(t *testing.T) {
+	store := openTestStore(t)
+	clk := &clock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	retention := NewRetentionService(store, RetentionPolicy{
+		TTL: map[domain.JobSource]time.Duration{domain.LinkedIn: 48 * time.Hour},
+	})
+	retention.Now = clk.Now
+
+	fetcher := &windowFetcher{stubFetcher{
+		source: domain.LinkedIn,
+		jobs: []domain.Job{
+			{ExternalID: "a", Title: "Go Developer"},
+			{ExternalID: "b", Title: "Rust Developer"},
+		},
+	}}
+	ingestor := NewIngestor(NewJobServices(Job{}, store), retention, nil, CombineFetchers(domain.LinkedIn, fetcher))
+	require.NoError(t, ingestor.RunOnce(context.Background()))
+
+	clk.Advance(time.Hour)
+	fetcher.jobs = fetcher.jobs[:1]
+	require.NoError(t, ingestor.RunOnce(context.Background()))
+	assert.Equal(t, map[string]domain.JobStatus{"a": domain.Active, "b": domain.Active}, jobStatuses(t, store))
+
+	clk.Advance(48 * time.Hour)
+	require.NoError(t, ingestor.RunOnce(context.Background()))
+	require.NoError(t, retention.Sweep())
+	assert.Equal(t, map[string]domain.JobStatus{"a": domain.Active, "b": domain.Closed}, jobStatuses(t, store), "left to expire by TTL")
+}
+
+func TestIngestPublishesNewJobs(t *testing.T) {
+	store := openTestStore(t)
+	retention := NewRetentionService(store, RetentionPolicy{})
+	jobs := NewJobServices(Job{}, store)
+	bus := events.NewBus()
+	created, unsubscribe := bus.Subscribe(10)
+	defer unsubscribe()
+
+	fetcher := &stubFetcher{
+		source: domain.Csv,
+		jobs:   []domain.Job{{ExternalID: "a", Title: "Go Developer"}},
+	}
+	ingestor := NewIngestor(jobs, retention, nil, fetcher).WithEvents(bus)
+
+	require.NoError(t, ingestor.RunOnce(context.Background()))
+	require.Len(t, created, 1)
+	first := <-created
+
+	fetcher.jobs = append(fetcher.jobs, domain.Job{ExternalID: "b", Title: "Rust Developer"})
+	require.NoError(t, ingestor.RunOnce(context.Background()))
+	require.Len(t, created, 1, "updates are not announced")
+	second := <-created
+	assert.NotEqual(t, first.JobID, second.JobID)
+
+	job, err := jobs.FilteredJob(second.JobID, JobFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, "Rust Developer", job.Title)
+	_, err = jobs.FilteredJob(second.JobID, JobFilter{MinSalary: 1})
+	assert.ErrorIs(t, err, ErrJobNotFound, "jobs outside the filter")
+
+	moderated, err := ParseModerationRules("*", "", "")
+	require.NoError(t, err)
+	ingestor.WithModeration(moderated)
+	fetcher.jobs = append(fetcher.jobs, domain.Job{ExternalID: "c", Title: "Held Developer"})
+	require.NoError(t, ingestor.RunOnce(context.Background()))
+	assert.Empty(t, created, "jobs held for review are not announced")
+
+	reviews := NewReviewService(store).WithEvents(bus)
+	pending, err := reviews.PendingJobs()
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	approved, err := reviews.Approve(pending[0].ID)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, approved)
+	require.Len(t, created, 1, "approved jobs are announced")
+	assert.Equal(t, pending[0].ID, (<-created).JobID)
+}
+
+func TestCombinedFetcher(t *testing.T) {
+	golang := &stubFetcher{source: domain.Indeed, jobs: []domain.Job{{ExternalID: "a"}, {ExternalID: "b"}}}
+	htmx := &stubFetcher{source: domain.Indeed, jobs: []domain.Job{{ExternalID: "b"}, {ExternalID: "c"}}}
+
+	combined := CombineFetchers(domain.Indeed, golang, htmx)
+	assert.Equal(t, domain.Indeed, combined.Source())
+	assert.True(t, combined.Complete())
+	assert.False(t, CombineFetchers(domain.Indeed, golang, &windowFetcher{}).Complete())
+
+	jobs, err := combined.FetchJobs(context.Background())
+	require.NoError(t, err)
+
+	var ids []string
+	for _, job := range jobs {
+		ids = append(ids, job.ExternalID)
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, ids)
+
+	htmx.err = errors.New("timeout")
+	_, err = combined.FetchJobs(context.Background())
+	assert.Error(t, err)
+}
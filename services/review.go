@@ -0,0 +1,274 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"htmxjb/db"
+	"htmxjb/models/domain"
+	"htmxjb/services/events"
+	"strings"
+	"time"
+)
+
+// RejectionReasons are offered when rejecting a job. Moderators may add a
+// note to any of them.
+var RejectionReasons = []string{
+	SpamReason,
+	"Duplicate",
+	"Not a tech job",
+	"Expired",
+	"Missing details",
+}
+
+// SpamReason is the rejection reason the spam filter learns from.
+const SpamReason = "Spam or scam"
+
+var (
+	ErrReasonRequired = errors.New("a rejection reason is required")
+	ErrTitleRequired  = errors.New("a title is required")
+)
+
+// ModerationRules decide which newly ingested jobs wait for review. Jobs
+// from moderated sources are held unless their source or company is
+// trusted.
+type ModerationRules struct {
+	AllSources       bool
+	Sources          map[domain.JobSource]bool
+	TrustedSources   map[domain.JobSource]bool
+	TrustedCompanies map[string]bool
+}
+
+// ParseModerationRules reads comma separated lists of source names, where
+// "*" moderates every source, trusted sources and trusted company names.
+// Company names match regardless of case.
+func ParseModerationRules(sources, trustedSources, trustedCompanies string) (ModerationRules, error) {
+	rules := ModerationRules{
+		Sources:          make(map[domain.JobSource]bool),
+		TrustedSources:   make(map[domain.JobSource]bool),
+		TrustedCompanies: make(map[string]bool),
+	}
+
+	for _, name := range splitList(sources) {
+		if name == "*" {
+			rules.AllSources = true
+			continue
+		}
+		source, err := domain.ParseJobSource(name)
+		if err != nil {
+			return rules, fmt.Errorf("invalid moderated source: %w", err)
+		}
+		rules.Sources[source] = true
+	}
+
+	for _, name := range splitList(trustedSources) {
+		source, err := domain.ParseJobSource(name)
+		if err != nil {
+			return rules, fmt.Errorf("invalid trusted source: %w", err)
+		}
+		rules.TrustedSources[source] = true
+	}
+
+	for _, name := range splitList(trustedCompanies) {
+		rules.TrustedCompanies[strings.ToLower(name)] = true
+	}
+
+	return rules, nil
+}
+
+// StatusFor is the status a new job is stored with.
+func (r ModerationRules) StatusFor(job domain.Job) domain.JobStatus {
+	moderated := r.AllSources || r.Sources[job.Source]
+	if moderated && !r.Trusted(job) {
+		return domain.Pending
+	}
+	return domain.Active
+}
+
+// Trusted reports whether job's source or company is trusted. Trusted jobs
+// skip both moderation and the spam filter.
+func (r ModerationRules) Trusted(job domain.Job) bool {
+	return r.TrustedSources[job.Source] || r.TrustedCompanies[strings.ToLower(strings.TrimSpace(job.Company))]
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// ReviewJob is a pending job as the review queue shows it.
+type ReviewJob struct {
+	Job
+	Source      domain.JobSource
+	Edited      bool
+	SpamScore   float64
+	SpamReasons []string
+}
+
+// ReviewService moderates jobs held for review.
+type ReviewService struct {
+	JobStore db.Store
+	Events   *events.Bus
+	Now      func() time.Time
+}
+
+func NewReviewService(jobStore db.Store) *ReviewService {
+	return &ReviewService{
+		JobStore: jobStore,
+		Now:      time.Now,
+	}
+}
+
+// WithEvents announces approved jobs on bus as they are listed.
+func (rs *ReviewService) WithEvents(bus *events.Bus) *ReviewService {
+	rs.Events = bus
+	return rs
+}
+
+const reviewColumns = jobColumns + ", source, edited_at, spam_score, spam_reasons"
+
+func scanReviewJob(row interface{ Scan(...interface{}) error }) (ReviewJob, error) {
+	var (
+		source      domain.JobSource
+		editedAt    sql.NullTime
+		spamScore   sql.NullFloat64
+		spamReasons sql.NullString
+	)
+	job, _, err := scanJob(row, time.Now(), &source, &editedAt, &spamScore, &spamReasons)
+	if err != nil {
+		return ReviewJob{}, err
+	}
+
+	review := ReviewJob{Job: job, Source: source, Edited: editedAt.Valid, SpamScore: spamScore.Float64}
+	if spamReasons.String != "" {
+		review.SpamReasons = strings.Split(spamReasons.String, "\n")
+	}
+
+	return review, nil
+}
+
+// PendingJobs returns the review queue, oldest first.
+func (rs *ReviewService) PendingJobs() ([]ReviewJob, error) {
+	rows, err := rs.JobStore.Query(
+		"SELECT "+reviewColumns+" FROM jobs WHERE status = ? ORDER BY created_at, id",
+		domain.Pending,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []ReviewJob
+	for rows.Next() {
+		job, err := scanReviewJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan pending job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pending jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// PendingJob returns one job from the review queue.
+func (rs *ReviewService) PendingJob(id int) (ReviewJob, error) {
+	job, err := scanReviewJob(rs.JobStore.QueryRow(
+		"SELECT "+reviewColumns+" FROM jobs WHERE id = ? AND status = ?",
+		id,
+		domain.Pending,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return ReviewJob{}, ErrJobNotFound
+	}
+	if err != nil {
+		return ReviewJob{}, fmt.Errorf("failed to get pending job %d: %w", id, err)
+	}
+
+	return job, nil
+}
+
+// Approve lists the pending jobs among ids and returns how many it
+// approved.
+func (rs *ReviewService) Approve(ids ...int) (int64, error) {
+	return rs.review(domain.Active, "", ids)
+}
+
+// Reject turns down the pending jobs among ids for reason and returns how
+// many it rejected.
+func (rs *ReviewService) Reject(reason string, ids ...int) (int64, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return 0, ErrReasonRequired
+	}
+	return rs.review(domain.Rejected, reason, ids)
+}
+
+func (rs *ReviewService) review(status domain.JobStatus, reason string, ids []int) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	args := []interface{}{status, nullString(reason), sqlTime(rs.Now()), domain.Pending}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	reviewed, err := execReturningIDs(rs.JobStore,
+		"UPDATE jobs SET status = ?, rejection_reason = ?, reviewed_at = ?, updated_at = CURRENT_TIMESTAMP"+
+			" WHERE status = ? AND id IN (?"+strings.Repeat(", ?", len(ids)-1)+") RETURNING id",
+		args...,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark jobs %s: %w", status, err)
+	}
+
+	if rs.Events != nil && status == domain.Active {
+		for _, id := range reviewed {
+			rs.Events.Publish(events.JobCreated{JobID: id})
+		}
+	}
+
+	return int64(len(reviewed)), nil
+}
+
+// Edit corrects a pending job's title and description. Edited fields are
+// kept when the source sends the job again; the description is kept as
+// plain text.
+func (rs *ReviewService) Edit(id int, title, description string) error {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return ErrTitleRequired
+	}
+
+	res, err := rs.JobStore.Exec(
+		"UPDATE jobs SET title = ?, description = ?, description_html = NULL, edited_at = ?, updated_at = CURRENT_TIMESTAMP"+
+			" WHERE id = ? AND status = ?",
+		title,
+		strings.TrimSpace(description),
+		sqlTime(rs.Now()),
+		id,
+		domain.Pending,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to edit job %d: %w", id, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to edit job %d: %w", id, err)
+	}
+	if n == 0 {
+		return ErrJobNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"htmxjb/models/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseModerationRules(t *testing.T) {
+	rules, err := ParseModerationRules("indeed, linkedin", "greenhouse", "Acme Cloud")
+	require.NoError(t, err)
+
+	assert.Equal(t, domain.Pending, rules.StatusFor(domain.Job{Source: domain.Indeed, Company: "Initech"}))
+	assert.Equal(t, domain.Active, rules.StatusFor(domain.Job{Source: domain.Indeed, Company: " acme cloud "}))
+	assert.Equal(t, domain.Active, rules.StatusFor(domain.Job{Source: domain.Csv}))
+
+	all, err := ParseModerationRules("*", "greenhouse", "")
+	require.NoError(t, err)
+	assert.Equal(t, domain.Pending, all.StatusFor(domain.Job{Source: domain.Csv}))
+	assert.Equal(t, domain.Active, all.StatusFor(domain.Job{Source: domain.Greenhouse}))
+
+	var none ModerationRules
+	assert.Equal(t, domain.Active, none.StatusFor(domain.Job{Source: domain.Indeed}))
+
+	_, err = ParseModerationRules("indeed, newspaper", "", "")
+	assert.ErrorContains(t, err, `"newspaper"`)
+}
+
+func TestReviewQueue(t *testing.T) {
+	store := openTestStore(t)
+	clk := &clock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+
+	retention := NewRetentionService(store, RetentionPolicy{})
+	retention.Now = clk.Now
+	rules, err := ParseModerationRules("indeed", "", "Acme")
+	require.NoError(t, err)
+
+	fetcher := &stubFetcher{
+		source: domain.Indeed,
+		jobs: []domain.Job{
+			{ExternalID: "a", Title: "Go Developer", Company: "Acme"},
+			{ExternalID: "b", Title: "Crypto Ninja"},
+			{ExternalID: "c", Title: "Rust Developr", Description: "Typo"},
+		},
+	}
+	jobs := NewJobServices(Job{}, store)
+	ingestor := NewIngestor(jobs, retention, nil, fetcher).WithModeration(rules)
+	require.NoError(t, ingestor.RunOnce(context.Background()))
+
+	reviews := NewReviewService(store)
+	reviews.Now = clk.Now
+
+	ids := func() map[string]int {
+		pending, err := reviews.PendingJobs()
+		require.NoError(t, err)
+		byTitle := make(map[string]int)
+		for _, job := range pending {
+			byTitle[job.Title] = job.ID
+		}
+		return byTitle
+	}
+
+	// The trusted company skips the queue; the rest wait and are not listed.
+	pending := ids()
+	assert.Len(t, pending, 2)
+	listed, err := jobs.GetAllJobs()
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, "Go Developer", listed[0].Title)
+
+	_, err = jobs.GetJob(pending["Crypto Ninja"])
+	assert.ErrorIs(t, err, ErrJobNotFound)
+
+	t.Run("edits are kept across ingests", func(t *testing.T) {
+		id := pending["Rust Developr"]
+		require.NoError(t, reviews.Edit(id, "Rust Developer", "Fixed"))
+
+		job, err := reviews.PendingJob(id)
+		require.NoError(t, err)
+		assert.Equal(t, "Rust Developer", job.Title)
+		assert.Equal(t, domain.Indeed, job.Source)
+		assert.True(t, job.Edited)
+
+		assert.ErrorIs(t, reviews.Edit(id, " ", ""), ErrTitleRequired)
+		assert.ErrorIs(t, reviews.Edit(404, "Title", ""), ErrJobNotFound)
+	})
+
+	t.Run("rejection needs a reason", func(t *testing.T) {
+		_, err := reviews.Reject(" ", pending["Crypto Ninja"])
+		assert.ErrorIs(t, err, ErrReasonRequired)
+
+		n, err := reviews.Reject("Spam or scam", pending["Crypto Ninja"])
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+	})
+
+	t.Run("approval lists the job", func(t *testing.T) {
+		n, err := reviews.Approve(pending["Rust Developr"], pending["Crypto Ninja"])
+		require.NoError(t, err)
+		// The rejected job is no longer pending.
+		assert.Equal(t, int64(1), n)
+
+		job, err := jobs.GetJob(pending["Rust Developr"])
+		require.NoError(t, err)
+		assert.Equal(t, "Rust Developer", job.Title)
+		assert.False(t, job.IsClosed)
+	})
+
+	t.Run("ingests do not reopen rejected jobs", func(t *testing.T) {
+		clk.Advance(time.Hour)
+		require.NoError(t, ingestor.RunOnce(context.Background()))
+
+		assert.Empty(t, ids())
+		assert.Equal(t, map[string]domain.JobStatus{
+			"a": domain.Active,
+			"b": domain.Rejected,
+			"c": domain.Active,
+		}, jobStatuses(t, store))
+
+		job, err := jobs.GetJob(pending["Rust Developr"])
+		require.NoError(t, err)
+		assert.Equal(t, "Rust Developer", job.Title)
+		assert.Equal(t, "Fixed", job.Description)
+	})
+}
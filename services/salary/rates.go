@@ -0,0 +1,99 @@
+package salary
+
+import (
+	"fmt"
+	"htmxjb/models/domain"
+	"strconv"
+	"strings"
+)
+
+// DefaultRates is a static table of USD per one unit of each currency. It
+// is only used to rank and filter salaries, so it does not need to be
+// current; override it with SALARY_RATES.
+var DefaultRates = map[string]float64{
+	"USD": 1,
+	"EUR": 1.08,
+	"GBP": 1.27,
+	"CHF": 1.13,
+	"CAD": 0.73,
+	"AUD": 0.66,
+	"SEK": 0.095,
+	"PLN": 0.25,
+	"RUB": 0.011,
+	"UAH": 0.024,
+	"KZT": 0.0021,
+	"INR": 0.012,
+	"JPY": 0.0067,
+}
+
+// Normalizer converts parsed salaries to yearly amounts in Base.
+type Normalizer struct {
+	Base            string
+	DefaultCurrency string
+	Rates           map[string]float64
+}
+
+func NewNormalizer(base string, rates map[string]float64) *Normalizer {
+	return &Normalizer{
+		Base:            strings.ToUpper(base),
+		DefaultCurrency: strings.ToUpper(base),
+		Rates:           rates,
+	}
+}
+
+// Normalize fills AnnualMin and AnnualMax. It reports false when the
+// currency is not in the rate table.
+func (n *Normalizer) Normalize(s *domain.Salary) bool {
+	from, ok := n.Rates[s.Currency]
+	if !ok {
+		return false
+	}
+	to, ok := n.Rates[n.Base]
+	if !ok || to == 0 {
+		return false
+	}
+
+	factor := PeriodsPerYear(s.Period) * from / to
+	s.AnnualMin = s.Min * factor
+	s.AnnualMax = s.Max * factor
+
+	return true
+}
+
+// Parse parses raw salary text and normalizes it in one step.
+func (n *Normalizer) Parse(raw string) (domain.Salary, bool) {
+	s, ok := Parse(raw, n.DefaultCurrency)
+	if !ok {
+		return s, false
+	}
+	return s, n.Normalize(&s)
+}
+
+// ParseRates reads a rate table written as "EUR=1.08,GBP=1.27" on top of
+// DefaultRates.
+func ParseRates(spec string) (map[string]float64, error) {
+	rates := make(map[string]float64, len(DefaultRates))
+	for code, rate := range DefaultRates {
+		rates[code] = rate
+	}
+
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		code, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rate %q, want CODE=rate", pair)
+		}
+
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("invalid rate %q", pair)
+		}
+		rates[strings.ToUpper(strings.TrimSpace(code))] = rate
+	}
+
+	return rates, nil
+}
@@ -0,0 +1,321 @@
+package salary
+
+import (
+	"htmxjb/models/domain"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Working time used to turn hourly, daily and weekly pay into a yearly
+// amount.
+const (
+	HoursPerYear  = 2080
+	DaysPerYear   = 260
+	WeeksPerYear  = 52
+	MonthsPerYear = 12
+)
+
+var (
+	// A number is either digits grouped in lakhs ("5,00,000"), digits
+	// grouped in threes by a thousands separator (space, nbsp, dot, comma or
+	// apostrophe) or a plain run of digits, each with an optional decimal
+	// part, followed by an optional multiplier.
+	numberRe = regexp.MustCompile(`(\d{1,2}(?:,\d{2})+,\d{3}(?:\.\d{1,2})?|\d{1,3}(?:[ \x{00a0}\x{202f}.,']\d{3})+(?:[.,]\d{1,2})?|\d+(?:[.,]\d+)?)(?:\s?(k|тыс\.?|thousand|mio|млн|m)(?:[^\p{L}\d]|$))?`)
+	codeRe   = regexp.MustCompile(`\b[A-Z]{3}\b`)
+)
+
+// currencySymbols is checked in order, so longer symbols that contain a
+// shorter one ("CA$" and "$") come first. Letters of a symbol must not
+// run into other letters, so "kr" does not match inside "Kraków", but may
+// touch the amount, as in "5000руб". A symbol ending in "*" is a stem.
+var currencySymbols = []struct {
+	symbol   string
+	currency string
+}{
+	{"ca$", "CAD"},
+	{"c$", "CAD"},
+	{"a$", "AUD"},
+	{"us$", "USD"},
+	{"$", "USD"},
+	{"€", "EUR"},
+	{"£", "GBP"},
+	{"₽", "RUB"},
+	{"руб", "RUB"},
+	{"рубл*", "RUB"},
+	{"₴", "UAH"},
+	{"грн", "UAH"},
+	{"₸", "KZT"},
+	{"zł", "PLN"},
+	{"₹", "INR"},
+	{"¥", "JPY"},
+	{"kr", "SEK"},
+	{"sfr.", "CHF"},
+	{"fr.", "CHF"},
+}
+
+// Keywords match whole words, so "dia" does not match inside "India". A
+// keyword ending in "*" is a stem that matches any word it starts, as
+// "month*" does "monthly".
+var periodKeywords = []struct {
+	period   domain.SalaryPeriod
+	keywords []string
+}{
+	{domain.Hourly, []string{"hour*", "/hr", "/h", "stunde*", "час", "heure*"}},
+	{domain.Daily, []string{"day", "daily", "/d", "tag", "täglich", "день", "jour", "dia", "día"}},
+	{domain.Weekly, []string{"week*", "woche*", "недел*", "semaine*", "semana*"}},
+	{domain.Monthly, []string{"month*", "/mo", "monat*", "месяц*", "мес", "mois", "mes"}},
+	{domain.Yearly, []string{"year*", "annum", "annual*", "/yr", "p.a", "jahr*", "jährlich", "год*", "an", "año", "ano", "anual"}},
+}
+
+var (
+	upToKeywords = []string{"up to", "bis", "до", "jusqu", "hasta"}
+	fromKeywords = []string{"from", "starting", "ab", "от", "à partir", "desde"}
+	// payKeywords mark an attribute without a currency as pay, so that
+	// "8 hour shift" is not read as 8 an hour.
+	payKeywords = []string{"salary", "pay*", "wage*", "gehalt", "lohn", "зарплат*", "оклад*", "salaire", "salario", "salário"}
+)
+
+// Parse extracts a pay range, currency and period from free-form salary
+// text such as "$50,000 - $70,000 a year", "45.000–55.000 € pro Jahr" or
+// "от 150 000 руб. в месяц". defaultCurrency is used when the text does not
+// name one. It reports false when no amount is found.
+func Parse(raw, defaultCurrency string) (domain.Salary, bool) {
+	s := domain.Salary{Raw: strings.TrimSpace(raw)}
+	lower := strings.ToLower(s.Raw)
+
+	amounts := parseAmounts(lower)
+	if len(amounts) == 0 {
+		return s, false
+	}
+
+	switch {
+	case len(amounts) >= 2:
+		s.Min, s.Max = amounts[0], amounts[1]
+		if s.Min > s.Max {
+			s.Min, s.Max = s.Max, s.Min
+		}
+	case containsWord(lower, upToKeywords):
+		s.Max = amounts[0]
+	case containsWord(lower, fromKeywords):
+		s.Min = amounts[0]
+	default:
+		s.Min, s.Max = amounts[0], amounts[0]
+	}
+
+	s.Currency = detectCurrency(s.Raw, lower)
+	if s.Currency == "" {
+		s.Currency = strings.ToUpper(defaultCurrency)
+	}
+
+	s.Period = detectPeriod(lower, math.Max(s.Min, s.Max))
+
+	return s, true
+}
+
+// FromAttributes returns the first attribute that reads as salary text:
+// one naming a currency or pay. Indeed mixes salary in with other
+// attributes like "Full-time" and "8 hour shift".
+func FromAttributes(attributes []string, defaultCurrency string) (domain.Salary, bool) {
+	for _, attr := range attributes {
+		lower := strings.ToLower(attr)
+		if detectCurrency(attr, lower) == "" && !containsWord(lower, payKeywords) {
+			continue
+		}
+		if s, ok := Parse(attr, defaultCurrency); ok {
+			return s, true
+		}
+	}
+	return domain.Salary{}, false
+}
+
+func parseAmounts(lower string) []float64 {
+	matches := numberRe.FindAllStringSubmatch(lower, -1)
+
+	var (
+		amounts     []float64
+		multipliers []float64
+	)
+	for _, m := range matches {
+		n, ok := parseNumber(m[1])
+		if !ok {
+			continue
+		}
+		amounts = append(amounts, n)
+		multipliers = append(multipliers, multiplier(m[2]))
+	}
+
+	// "30-40k" means 30k to 40k: carry a trailing multiplier back to a bare
+	// lower bound.
+	if len(amounts) >= 2 && multipliers[0] == 1 && multipliers[1] > 1 && amounts[0] <= amounts[1] {
+		multipliers[0] = multipliers[1]
+	}
+
+	for i := range amounts {
+		amounts[i] *= multipliers[i]
+	}
+
+	return amounts
+}
+
+// parseNumber reads a number written with either "." or "," as the decimal
+// separator and any of space, dot, comma or apostrophe as the thousands
+// separator.
+func parseNumber(token string) (float64, bool) {
+	token = strings.NewReplacer(" ", "", "\u00a0", "", "\u202f", "", "'", "").Replace(token)
+
+	lastDot := strings.LastIndex(token, ".")
+	lastComma := strings.LastIndex(token, ",")
+
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		// Both present: whichever comes last is the decimal separator.
+		if lastDot > lastComma {
+			token = strings.ReplaceAll(token, ",", "")
+		} else {
+			token = strings.ReplaceAll(token, ".", "")
+			token = strings.Replace(token, ",", ".", 1)
+		}
+	case lastDot >= 0:
+		token = normalizeSeparator(token, ".")
+	case lastComma >= 0:
+		token = normalizeSeparator(token, ",")
+	}
+
+	n, err := strconv.ParseFloat(token, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// normalizeSeparator decides whether a lone separator kind is grouping
+// thousands ("45.000", "1,000,000") or marking decimals ("12,5").
+func normalizeSeparator(token, sep string) string {
+	parts := strings.Split(token, sep)
+	if len(parts) > 2 || len(parts[len(parts)-1]) == 3 {
+		return strings.Join(parts, "")
+	}
+	return strings.Join(parts, ".")
+}
+
+func multiplier(suffix string) float64 {
+	switch strings.TrimSuffix(suffix, ".") {
+	case "k", "тыс", "thousand":
+		return 1_000
+	case "m", "mio", "млн":
+		return 1_000_000
+	default:
+		return 1
+	}
+}
+
+func detectCurrency(raw, lower string) string {
+	for _, code := range codeRe.FindAllString(raw, -1) {
+		if _, ok := DefaultRates[code]; ok {
+			return code
+		}
+	}
+	for _, cs := range currencySymbols {
+		if containsToken(lower, []string{cs.symbol}, unicode.IsLetter) {
+			return cs.currency
+		}
+	}
+	return ""
+}
+
+func explicitPeriod(lower string) domain.SalaryPeriod {
+	for _, pk := range periodKeywords {
+		if containsWord(lower, pk.keywords) {
+			return pk.period
+		}
+	}
+	return domain.UnknownPeriod
+}
+
+// detectPeriod uses keywords when present and otherwise guesses from the
+// size of the amount.
+func detectPeriod(lower string, amount float64) domain.SalaryPeriod {
+	if period := explicitPeriod(lower); period != domain.UnknownPeriod {
+		return period
+	}
+
+	switch {
+	case amount < 500:
+		return domain.Hourly
+	case amount < 20_000:
+		return domain.Monthly
+	default:
+		return domain.Yearly
+	}
+}
+
+// containsWord reports whether s contains one of words as a whole word,
+// or as the start of a word for stems ending in "*". Words that begin or
+// end with punctuation, like "/hr", need no boundary on that side.
+func containsWord(s string, words []string) bool {
+	return containsToken(s, words, isWordRune)
+}
+
+// containsToken reports whether one of words occurs in s without runes
+// that inWord accepts running into either end of it.
+func containsToken(s string, words []string, inWord func(rune) bool) bool {
+	for _, w := range words {
+		stem := strings.HasSuffix(w, "*")
+		w = strings.TrimSuffix(w, "*")
+
+		for i := 0; i < len(s); {
+			j := strings.Index(s[i:], w)
+			if j < 0 {
+				break
+			}
+			start, end := i+j, i+j+len(w)
+			if tokenStart(s, start, w, inWord) && (stem || tokenEnd(s, end, w, inWord)) {
+				return true
+			}
+			i = start + 1
+		}
+	}
+	return false
+}
+
+func tokenStart(s string, i int, w string, inWord func(rune) bool) bool {
+	first, _ := utf8.DecodeRuneInString(w)
+	if !inWord(first) || i == 0 {
+		return true
+	}
+	before, _ := utf8.DecodeLastRuneInString(s[:i])
+	return !inWord(before)
+}
+
+func tokenEnd(s string, i int, w string, inWord func(rune) bool) bool {
+	last, _ := utf8.DecodeLastRuneInString(w)
+	if !inWord(last) || i == len(s) {
+		return true
+	}
+	after, _ := utf8.DecodeRuneInString(s[i:])
+	return !inWord(after)
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// PeriodsPerYear is how many times a period's pay is earned in a year.
+func PeriodsPerYear(period domain.SalaryPeriod) float64 {
+	switch period {
+	case domain.Hourly:
+		return HoursPerYear
+	case domain.Daily:
+		return DaysPerYear
+	case domain.Weekly:
+		return WeeksPerYear
+	case domain.Monthly:
+		return MonthsPerYear
+	default:
+		return 1
+	}
+}
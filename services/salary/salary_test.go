@@ -0,0 +1,124 @@
+package salary
+
+import (
+	"htmxjb/models/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		raw      string
+		min, max float64
+		currency string
+		period   domain.SalaryPeriod
+	}{
+		{"$50,000 - $70,000 a year", 50_000, 70_000, "USD", domain.Yearly},
+		{"$25 an hour", 25, 25, "USD", domain.Hourly},
+		{"$22.50 - $28.75 an hour", 22.5, 28.75, "USD", domain.Hourly},
+		{"Up to $80K a year", 0, 80_000, "USD", domain.Yearly},
+		{"£30k-£40k per annum", 30_000, 40_000, "GBP", domain.Yearly},
+		{"£30-40k", 30_000, 40_000, "GBP", domain.Yearly},
+		{"45.000–55.000 € pro Jahr", 45_000, 55_000, "EUR", domain.Yearly},
+		{"3.500,50 € pro Monat", 3_500.5, 3_500.5, "EUR", domain.Monthly},
+		{"от 150 000 руб. в месяц", 150_000, 0, "RUB", domain.Monthly},
+		{"150 000 – 200 000 ₽", 150_000, 200_000, "RUB", domain.Yearly},
+		{"100-150 тыс. руб.", 100_000, 150_000, "RUB", domain.Yearly},
+		{"CHF 110'000 - 130'000", 110_000, 130_000, "CHF", domain.Yearly},
+		{"À partir de 4 000 € par mois", 4_000, 0, "EUR", domain.Monthly},
+		{"4,000 a month", 4_000, 4_000, "", domain.Monthly},
+		{"₹5,00,000 per annum India", 500_000, 500_000, "INR", domain.Yearly},
+		{"₹3,50,000 - ₹6,00,000 a year", 350_000, 600_000, "INR", domain.Yearly},
+		{"About $50k", 50_000, 50_000, "USD", domain.Yearly},
+		{"ab 60.000 € pro Jahr", 60_000, 0, "EUR", domain.Yearly},
+		{"$30 hourly", 30, 30, "USD", domain.Hourly},
+		{"$2,000 paid twice per month", 2_000, 2_000, "USD", domain.Monthly},
+		{"45 000 kr", 45_000, 45_000, "SEK", domain.Yearly},
+		{"SFr. 9'000 pro Monat", 9_000, 9_000, "CHF", domain.Monthly},
+		{"5000руб в месяц", 5_000, 5_000, "RUB", domain.Monthly},
+		{"200 000 рублей", 200_000, 200_000, "RUB", domain.Yearly},
+		{"12 000 zł miesięcznie", 12_000, 12_000, "PLN", domain.Monthly},
+		{"30 000 грн", 30_000, 30_000, "UAH", domain.Yearly},
+		{"60 000 per year, Kraków", 60_000, 60_000, "", domain.Yearly},
+		{"Ukraine, 3000/month", 3_000, 3_000, "", domain.Monthly},
+		{"Bitkraft, 70k a year", 70_000, 70_000, "", domain.Yearly},
+		{"150 000 в месяц, работа за рубежом", 150_000, 150_000, "", domain.Monthly},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			s, ok := Parse(tt.raw, "")
+
+			assert.True(t, ok)
+			assert.InDelta(t, tt.min, s.Min, 0.001)
+			assert.InDelta(t, tt.max, s.Max, 0.001)
+			assert.Equal(t, tt.currency, s.Currency)
+			assert.Equal(t, tt.period, s.Period)
+		})
+	}
+}
+
+func TestParseNoAmount(t *testing.T) {
+	_, ok := Parse("Competitive salary", "USD")
+	assert.False(t, ok)
+}
+
+func TestFromAttributes(t *testing.T) {
+	s, ok := FromAttributes([]string{"Full-time", "Health insurance", "$60,000 - $80,000 a year"}, "")
+
+	assert.True(t, ok)
+	assert.Equal(t, 60_000.0, s.Min)
+	assert.Equal(t, "USD", s.Currency)
+
+	_, ok = FromAttributes([]string{"Full-time", "401(k)"}, "")
+	assert.False(t, ok)
+
+	_, ok = FromAttributes([]string{"8 hour shift", "10 hour shift", "Day shift", "Monday to Friday"}, "")
+	assert.False(t, ok, "shifts are not pay")
+
+	s, ok = FromAttributes([]string{"10 hour shift", "Pay: 4,000 a month"}, "USD")
+	assert.True(t, ok)
+	assert.Equal(t, 4_000.0, s.Max)
+	assert.Equal(t, domain.Monthly, s.Period)
+}
+
+func TestFromRange(t *testing.T) {
+	s, ok := FromRange(70_000, 90_000, "eur", "YEAR")
+	assert.True(t, ok)
+	assert.Equal(t, domain.Salary{Raw: "EUR 70,000 - 90,000 per year", Min: 70_000, Max: 90_000, Currency: "EUR", Period: domain.Yearly}, s)
+
+	s, ok = FromRange(0, 62.5, "USD", "hour")
+	assert.True(t, ok)
+	assert.Equal(t, "USD 62.50 per hour", s.Raw)
+	assert.Equal(t, 62.5, s.Min)
+
+	_, ok = FromRange(0, 0, "USD", "YEAR")
+	assert.False(t, ok)
+}
+
+func TestNormalize(t *testing.T) {
+	n := NewNormalizer("USD", map[string]float64{"USD": 1, "EUR": 1.1})
+
+	s, ok := n.Parse("€20 - €30 per hour")
+	assert.True(t, ok)
+	assert.InDelta(t, 20*1.1*HoursPerYear, s.AnnualMin, 0.01)
+	assert.InDelta(t, 30*1.1*HoursPerYear, s.AnnualMax, 0.01)
+
+	_, ok = n.Parse("1000 XYZ a month")
+	assert.True(t, ok, "unknown codes fall back to the base currency")
+
+	s = domain.Salary{Min: 1, Max: 1, Currency: "JPY", Period: domain.Yearly}
+	assert.False(t, n.Normalize(&s))
+}
+
+func TestParseRates(t *testing.T) {
+	rates, err := ParseRates("eur=1.2, GBP=1.3")
+	assert.NoError(t, err)
+	assert.Equal(t, 1.2, rates["EUR"])
+	assert.Equal(t, 1.3, rates["GBP"])
+	assert.Equal(t, 1.0, rates["USD"])
+
+	_, err = ParseRates("EUR")
+	assert.Error(t, err)
+}
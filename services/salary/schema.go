@@ -0,0 +1,72 @@
+package salary
+
+import (
+	"htmxjb/models/domain"
+	"strconv"
+	"strings"
+)
+
+// unitPeriods maps schema.org QuantitativeValue unitText values to periods.
+var unitPeriods = map[string]domain.SalaryPeriod{
+	"HOUR":  domain.Hourly,
+	"DAY":   domain.Daily,
+	"WEEK":  domain.Weekly,
+	"MONTH": domain.Monthly,
+	"YEAR":  domain.Yearly,
+}
+
+// FromRange builds a salary from a structured range such as a schema.org
+// MonetaryAmount, where unit is the unitText ("YEAR", "HOUR", ...). A
+// missing bound takes the other's value, and Raw is filled in for display,
+// e.g. "EUR 70,000 - 90,000 per year". It reports false when both bounds
+// are zero.
+func FromRange(minValue, maxValue float64, currency, unit string) (domain.Salary, bool) {
+	if minValue == 0 {
+		minValue = maxValue
+	}
+	if maxValue == 0 {
+		maxValue = minValue
+	}
+	if minValue == 0 {
+		return domain.Salary{}, false
+	}
+
+	unit = strings.ToUpper(strings.TrimSpace(unit))
+	s := domain.Salary{
+		Min:      minValue,
+		Max:      maxValue,
+		Currency: strings.ToUpper(strings.TrimSpace(currency)),
+		Period:   unitPeriods[unit],
+	}
+
+	s.Raw = FormatAmount(s.Min)
+	if s.Max != s.Min {
+		s.Raw += " - " + FormatAmount(s.Max)
+	}
+	if s.Currency != "" {
+		s.Raw = s.Currency + " " + s.Raw
+	}
+	if s.Period != domain.UnknownPeriod {
+		s.Raw += " per " + strings.ToLower(unit)
+	}
+
+	return s, true
+}
+
+// FormatAmount writes whole amounts with thousands separators and others
+// with two decimals.
+func FormatAmount(v float64) string {
+	if v != float64(int64(v)) {
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	}
+	digits := strconv.FormatFloat(v, 'f', 0, 64)
+
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	return b.String()
+}
@@ -0,0 +1,193 @@
+package sanitize
+
+import (
+	"net/url"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// allowed lists the elements kept in job descriptions. Other elements are
+// unwrapped: their tags are dropped and their children kept.
+var allowed = map[atom.Atom]bool{
+	atom.P: true, atom.Br: true, atom.Hr: true, atom.Div: true, atom.Span: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Ul: true, atom.Ol: true, atom.Li: true, atom.Dl: true, atom.Dt: true, atom.Dd: true,
+	atom.Strong: true, atom.B: true, atom.Em: true, atom.I: true, atom.U: true,
+	atom.Blockquote: true, atom.Code: true, atom.Pre: true, atom.A: true,
+	atom.Table: true, atom.Thead: true, atom.Tbody: true, atom.Tr: true, atom.Th: true, atom.Td: true,
+}
+
+// dropped lists elements removed together with their content.
+var dropped = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Iframe: true, atom.Object: true,
+	atom.Embed: true, atom.Noscript: true, atom.Template: true, atom.Head: true,
+	atom.Title: true, atom.Form: true, atom.Svg: true, atom.Math: true,
+}
+
+// block lists elements that start a new line in plain text.
+var block = map[atom.Atom]bool{
+	atom.P: true, atom.Br: true, atom.Hr: true, atom.Div: true, atom.Li: true, atom.Tr: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Ul: true, atom.Ol: true, atom.Dt: true, atom.Dd: true, atom.Blockquote: true, atom.Pre: true,
+}
+
+var linkSchemes = map[string]bool{"http": true, "https": true, "mailto": true}
+
+// HTML returns raw with only allow-listed elements. All attributes are
+// stripped except link targets with a safe scheme, and links get
+// rel="nofollow noopener noreferrer".
+func HTML(raw string) string {
+	nodes, err := parse(raw)
+	if err != nil {
+		return html.EscapeString(raw)
+	}
+
+	var b strings.Builder
+	for _, n := range nodes {
+		writeNode(&b, n)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// Text returns the text content of an HTML fragment with block elements on
+// separate lines and runs of spaces collapsed.
+func Text(raw string) string {
+	nodes, err := parse(raw)
+	if err != nil {
+		return raw
+	}
+
+	var b strings.Builder
+	for _, n := range nodes {
+		writeText(&b, n)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(b.String(), "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Excerpt shortens plain text to at most limit runes, cutting at a word
+// boundary and adding an ellipsis when anything was cut.
+func Excerpt(text string, limit int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+
+	cut := runes[:limit]
+	if i := lastSpace(cut); i > limit/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRightFunc(string(cut), func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}) + "…"
+}
+
+func parse(raw string) ([]*html.Node, error) {
+	return html.ParseFragment(strings.NewReader(raw), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+}
+
+func writeNode(b *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		b.WriteString(html.EscapeString(n.Data))
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	if dropped[n.DataAtom] {
+		return
+	}
+	if !allowed[n.DataAtom] {
+		writeChildren(b, n)
+		return
+	}
+
+	b.WriteString("<" + n.Data)
+	if n.DataAtom == atom.A {
+		if href, ok := safeHref(n); ok {
+			b.WriteString(` href="` + html.EscapeString(href) + `"`)
+		}
+		b.WriteString(` rel="nofollow noopener noreferrer" target="_blank"`)
+	}
+	b.WriteString(">")
+
+	if n.DataAtom == atom.Br || n.DataAtom == atom.Hr {
+		return
+	}
+
+	writeChildren(b, n)
+	b.WriteString("</" + n.Data + ">")
+}
+
+func writeChildren(b *strings.Builder, n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		writeNode(b, c)
+	}
+}
+
+func writeText(b *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		// Line breaks in source HTML are just whitespace; only block
+		// elements start new lines.
+		b.WriteString(strings.ReplaceAll(n.Data, "\n", " "))
+		return
+	case html.ElementNode:
+		if dropped[n.DataAtom] {
+			return
+		}
+	}
+
+	isBlock := n.Type == html.ElementNode && block[n.DataAtom]
+	if isBlock {
+		b.WriteString("\n")
+	}
+	if n.DataAtom == atom.Li {
+		b.WriteString("• ")
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		writeText(b, c)
+	}
+	if isBlock {
+		b.WriteString("\n")
+	}
+}
+
+func safeHref(n *html.Node) (string, bool) {
+	for _, attr := range n.Attr {
+		if attr.Key != "href" {
+			continue
+		}
+		u, err := url.Parse(strings.TrimSpace(attr.Val))
+		if err != nil || !linkSchemes[strings.ToLower(u.Scheme)] {
+			return "", false
+		}
+		return u.String(), true
+	}
+	return "", false
+}
+
+func lastSpace(runes []rune) int {
+	for i := len(runes) - 1; i >= 0; i-- {
+		if unicode.IsSpace(runes[i]) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,64 @@
+package sanitize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{
+			"keeps allowed markup",
+			"<h2>About</h2><ul><li><b>Go</b></li><li><em>SQL</em></li></ul>",
+			"<h2>About</h2><ul><li><b>Go</b></li><li><em>SQL</em></li></ul>",
+		},
+		{
+			"drops scripts and styles with their content",
+			`<p>Hi<script>alert(1)</script><style>p{color:red}</style></p>`,
+			"<p>Hi</p>",
+		},
+		{
+			"strips attributes and unwraps unknown elements",
+			`<p class="x" onclick="evil()"><font color="red">Red</font> <img src=x onerror=alert(1)></p>`,
+			"<p>Red </p>",
+		},
+		{
+			"adds nofollow to links",
+			`<a href="https://example.com/apply?a=1&b=2" onclick="x">Apply</a>`,
+			`<a href="https://example.com/apply?a=1&amp;b=2" rel="nofollow noopener noreferrer" target="_blank">Apply</a>`,
+		},
+		{
+			"drops unsafe link targets",
+			`<a href="javascript:alert(1)">Click</a>`,
+			`<a rel="nofollow noopener noreferrer" target="_blank">Click</a>`,
+		},
+		{
+			"escapes text",
+			"5 &lt; 6 &amp; <br/>done",
+			"5 &lt; 6 &amp; <br>done",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, HTML(tt.raw))
+		})
+	}
+}
+
+func TestText(t *testing.T) {
+	raw := "<h2>About us</h2><p>We  build\n things.</p><ul><li>Go</li><li>SQL</li></ul><script>x()</script>"
+
+	assert.Equal(t, "About us\nWe build things.\n• Go\n• SQL", Text(raw))
+}
+
+func TestExcerpt(t *testing.T) {
+	assert.Equal(t, "short text", Excerpt("short   text", 20))
+	assert.Equal(t, "We are hiring a senior…", Excerpt("We are hiring a senior, remote Go engineer", 24))
+	assert.Equal(t, "Привет…", Excerpt("Привет мир", 8))
+}
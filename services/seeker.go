@@ -0,0 +1,207 @@
+package services
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"htmxjb/db"
+	"htmxjb/services/extract"
+	"htmxjb/services/match"
+	"htmxjb/services/tags"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	defaultSeekerProfileTTL = 30 * 24 * time.Hour
+	// maxResumeText is how much resume text a profile keeps.
+	maxResumeText = 64 << 10
+)
+
+var (
+	ErrResumeUnreadable      = errors.New("no text could be read from the resume; scanned documents are not supported")
+	ErrSeekerProfileNotFound = errors.New("resume profile not found or expired")
+)
+
+// SeekerProfile is what a job seeker's uploaded resume says about them:
+// its text and the skills found in it. The file itself is not kept.
+type SeekerProfile struct {
+	ResumeName string
+	Skills     []string
+	Text       string
+	ExpiresAt  time.Time
+}
+
+// SeekerProfileService reads uploaded resumes into profiles that jobs are
+// ranked against. Seekers have no accounts; a profile belongs to whoever
+// holds its random token, and only the token's hash is stored.
+type SeekerProfileService struct {
+	JobStore       db.Store
+	Skills         *tags.Extractor
+	TTL            time.Duration
+	MaxResumeBytes int
+	Now            func() time.Time
+}
+
+func NewSeekerProfileService(jobStore db.Store, skills *tags.Extractor) *SeekerProfileService {
+	return &SeekerProfileService{
+		JobStore:       jobStore,
+		Skills:         skills,
+		TTL:            defaultSeekerProfileTTL,
+		MaxResumeBytes: defaultMaxResumeBytes,
+		Now:            time.Now,
+	}
+}
+
+// Upload extracts the text and skills of resume and stores them as a new
+// profile, returning its token. Expired profiles are cleared as well.
+func (ss *SeekerProfileService) Upload(resume Resume) (string, SeekerProfile, error) {
+	ext, err := checkResume(resume, ss.MaxResumeBytes)
+	if err != nil {
+		return "", SeekerProfile{}, err
+	}
+
+	text, err := extract.Text(resume.Name, resume.Data)
+	if errors.Is(err, extract.ErrNoText) || errors.Is(err, extract.ErrMalformed) {
+		return "", SeekerProfile{}, ErrResumeUnreadable
+	}
+	if err != nil {
+		return "", SeekerProfile{}, fmt.Errorf("failed to read resume: %w", err)
+	}
+
+	now := ss.Now()
+	profile := SeekerProfile{
+		ResumeName: resumeName(resume.Name, ext),
+		Text:       truncateText(text, maxResumeText),
+		ExpiresAt:  now.Add(ss.TTL),
+	}
+	if ss.Skills != nil {
+		profile.Skills = ss.Skills.Extract(profile.Text)
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", SeekerProfile{}, fmt.Errorf("failed to create profile token: %w", err)
+	}
+	token := hex.EncodeToString(raw)
+
+	if _, err := ss.JobStore.Exec("DELETE FROM seeker_profiles WHERE expires_at <= ?", sqlTime(now)); err != nil {
+		return "", SeekerProfile{}, fmt.Errorf("failed to clear expired profiles: %w", err)
+	}
+
+	_, err = ss.JobStore.Exec(
+		"INSERT INTO seeker_profiles (token_hash, resume_name, resume_text, skills, created_at, expires_at) VALUES (?, ?, ?, ?, ?, ?)",
+		hashToken(token),
+		profile.ResumeName,
+		profile.Text,
+		strings.Join(profile.Skills, "|"),
+		sqlTime(now),
+		sqlTime(profile.ExpiresAt),
+	)
+	if err != nil {
+		return "", SeekerProfile{}, fmt.Errorf("failed to save profile: %w", err)
+	}
+
+	return token, profile, nil
+}
+
+// Profile returns the unexpired profile for token.
+func (ss *SeekerProfileService) Profile(token string) (SeekerProfile, error) {
+	if token == "" {
+		return SeekerProfile{}, ErrSeekerProfileNotFound
+	}
+
+	var (
+		p      SeekerProfile
+		skills string
+	)
+	err := ss.JobStore.QueryRow(
+		"SELECT resume_name, resume_text, skills, expires_at FROM seeker_profiles WHERE token_hash = ? AND expires_at > ?",
+		hashToken(token),
+		sqlTime(ss.Now()),
+	).Scan(&p.ResumeName, &p.Text, &skills, &p.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return SeekerProfile{}, ErrSeekerProfileNotFound
+	}
+	if err != nil {
+		return SeekerProfile{}, fmt.Errorf("failed to get profile: %w", err)
+	}
+
+	if skills != "" {
+		p.Skills = strings.Split(skills, "|")
+	}
+	return p, nil
+}
+
+// Forget deletes the profile for token.
+func (ss *SeekerProfileService) Forget(token string) error {
+	if _, err := ss.JobStore.Exec("DELETE FROM seeker_profiles WHERE token_hash = ?", hashToken(token)); err != nil {
+		return fmt.Errorf("failed to delete profile: %w", err)
+	}
+	return nil
+}
+
+// truncateText cuts s to at most n bytes without splitting a character.
+func truncateText(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// Weights of the two parts of a match score.
+const (
+	textWeight  = 0.5
+	skillWeight = 0.5
+)
+
+// scoreMatches sets each job's Match to how well it fits profile, as a
+// percentage. Text similarity is TF-IDF cosine over the resume and the
+// jobs given; jobs with tags also score by the share of their tags found
+// among the resume's skills.
+func scoreMatches(jobs []Job, profile *SeekerProfile) {
+	if profile == nil || len(jobs) == 0 {
+		return
+	}
+
+	corpus := match.NewCorpus()
+	resume := match.Terms(profile.Text)
+	corpus.Add(resume)
+	terms := make([]map[string]int, len(jobs))
+	for i, job := range jobs {
+		// Titles say most about a job, so they count twice.
+		terms[i] = match.Terms(job.Title + "\n" + job.Title + "\n" + job.Description)
+		corpus.Add(terms[i])
+	}
+
+	r := corpus.Vector(resume)
+	for i := range jobs {
+		score := match.Cosine(r, corpus.Vector(terms[i]))
+		if len(jobs[i].Tags) > 0 {
+			score = textWeight*score + skillWeight*match.Coverage(profile.Skills, jobs[i].Tags)
+		}
+		jobs[i].Match = int(score*100 + 0.5)
+	}
+}
+
+// sortRecommended orders jobs by match score, keeping open jobs ahead of
+// closed ones and pinned jobs first among those.
+func sortRecommended(jobs []Job) {
+	sort.SliceStable(jobs, func(i, j int) bool {
+		a, b := jobs[i], jobs[j]
+		if a.IsClosed != b.IsClosed {
+			return !a.IsClosed
+		}
+		if a.Pinned != b.Pinned {
+			return a.Pinned
+		}
+		return a.Match > b.Match
+	})
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"htmxjb/models/domain"
+	"htmxjb/services/tags"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeekerProfiles(t *testing.T) {
+	store := openTestStore(t)
+	clk := &clock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	skills, err := tags.Bundled()
+	require.NoError(t, err)
+
+	ss := NewSeekerProfileService(store, skills)
+	ss.Now = clk.Now
+	ss.TTL = 24 * time.Hour
+
+	_, _, err = ss.Upload(Resume{Name: "cv.docx", Data: docx(t)})
+	assert.ErrorIs(t, err, ErrResumeUnreadable, "documents without text")
+	_, _, err = ss.Upload(Resume{Name: "cv.txt", Data: []byte("Go")})
+	assert.ErrorIs(t, err, ErrResumeType)
+
+	token, profile, err := ss.Upload(Resume{Name: "Bob.docx", Data: docx(t,
+		"Bob Smith, backend engineer",
+		"Go and Kubernetes microservices, PostgreSQL, Docker",
+	)})
+	require.NoError(t, err)
+	assert.Equal(t, "Bob.docx", profile.ResumeName)
+	assert.ElementsMatch(t, []string{"Go", "Kubernetes", "Microservices", "PostgreSQL", "Docker"}, profile.Skills)
+
+	got, err := ss.Profile(token)
+	require.NoError(t, err)
+	assert.Equal(t, profile.Skills, got.Skills)
+	assert.Contains(t, got.Text, "microservices")
+	assert.True(t, got.ExpiresAt.Equal(clk.now.Add(24*time.Hour)))
+
+	_, err = ss.Profile("not-a-token")
+	assert.ErrorIs(t, err, ErrSeekerProfileNotFound)
+
+	t.Run("ranking", func(t *testing.T) {
+		es := NewEmployerService(store, NewCompanyService(store))
+		ps := NewPostingService(store, []JobEnricher{TagEnricher(skills)})
+		ps.Now = clk.Now
+		jobs := NewJobServices(Job{}, store)
+		jobs.Now = clk.Now
+
+		ann, err := es.SignUp("ann@acme.example", "Ann", "Acme", "correct horse battery")
+		require.NoError(t, err)
+		post := func(title, description string) int {
+			p, err := ps.CreateDraft(ann)
+			require.NoError(t, err)
+			p.Title, p.Description, p.Workplace = title, description, domain.Remote
+			require.NoError(t, ps.Save(ann.ID, p))
+			require.NoError(t, ps.Publish(ann.ID, p.ID, time.Time{}, time.Time{}))
+			clk.Advance(time.Minute)
+			return p.ID
+		}
+		goJob := post("Go Engineer", "Build Go microservices on Kubernetes with PostgreSQL.")
+		design := post("Product Designer", "Design our app in Figma.")
+		rails := post("Ruby Engineer", "Rails and PostgreSQL.")
+
+		listed, err := jobs.ListJobs(JobFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, []int{rails, design, goJob}, jobIDs(listed))
+		assert.Zero(t, listed[0].Match, "no scores without a profile")
+
+		listed, err = jobs.ListJobs(JobFilter{Sort: SortRecommended, Profile: &got})
+		require.NoError(t, err)
+		assert.Equal(t, []int{goJob, rails, design}, jobIDs(listed))
+		assert.Greater(t, listed[0].Match, 50)
+		assert.Zero(t, listed[2].Match)
+
+		listed, err = jobs.ListJobs(JobFilter{Profile: &got})
+		require.NoError(t, err)
+		assert.Equal(t, []int{rails, design, goJob}, jobIDs(listed), "other sorts keep their order")
+		assert.Greater(t, listed[2].Match, listed[0].Match)
+
+		listed, err = jobs.ListJobs(JobFilter{Sort: SortRecommended})
+		require.NoError(t, err)
+		assert.Equal(t, []int{rails, design, goJob}, jobIDs(listed), "newest first without a profile")
+	})
+
+	require.NoError(t, ss.Forget(token))
+	_, err = ss.Profile(token)
+	assert.ErrorIs(t, err, ErrSeekerProfileNotFound)
+
+	t.Run("expiry", func(t *testing.T) {
+		old, _, err := ss.Upload(Resume{Name: "cv.docx", Data: docx(t, "Go")})
+		require.NoError(t, err)
+		clk.Advance(25 * time.Hour)
+		_, err = ss.Profile(old)
+		assert.ErrorIs(t, err, ErrSeekerProfileNotFound)
+
+		_, _, err = ss.Upload(Resume{Name: "cv.docx", Data: docx(t, "Go")})
+		require.NoError(t, err)
+		var count int
+		require.NoError(t, store.QueryRow("SELECT COUNT(*) FROM seeker_profiles").Scan(&count))
+		assert.Equal(t, 1, count, "expired profiles are cleared on upload")
+	})
+}
+
+func jobIDs(jobs []Job) []int {
+	ids := make([]int, len(jobs))
+	for i, j := range jobs {
+		ids[i] = j.ID
+	}
+	return ids
+}
@@ -0,0 +1,284 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"htmxjb/db"
+	"htmxjb/models/domain"
+	"htmxjb/services/match"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultSimilarLimit is how many similar jobs are kept for each job.
+	defaultSimilarLimit = 5
+	// minSimilarity leaves out jobs that only share a word or a city.
+	minSimilarity = 0.15
+)
+
+// Weights of the features jobs are compared on. They add up to one.
+const (
+	similarTitleWeight    = 0.45
+	similarTagWeight      = 0.35
+	similarCompanyWeight  = 0.1
+	similarLocationWeight = 0.1
+)
+
+// SimilarService finds related jobs by title, tags, company and location.
+// Comparing every job with every other is too slow for a page view, so
+// the lists are computed in the background and kept in similar_jobs.
+type SimilarService struct {
+	JobStore db.Store
+	Limit    int
+	Now      func() time.Time
+}
+
+func NewSimilarService(jobStore db.Store) *SimilarService {
+	return &SimilarService{
+		JobStore: jobStore,
+		Limit:    defaultSimilarLimit,
+		Now:      time.Now,
+	}
+}
+
+// similarFeatures are what jobs are compared on.
+type similarFeatures struct {
+	id      int
+	title   match.Vector
+	tags    map[string]bool
+	company int64
+	place   string
+	country string
+	remote  bool
+	// keys index the job for finding candidates.
+	keys  []string
+	terms map[string]int
+}
+
+// Similar returns the open jobs most like jobID, best first. It is empty
+// until the job's list has been computed.
+func (ss *SimilarService) Similar(jobID int) ([]Job, error) {
+	now := ss.Now()
+	rows, err := ss.JobStore.Query(
+		"SELECT "+jobColumns+" FROM similar_jobs s JOIN jobs ON jobs.id = s.similar_id"+
+			" WHERE s.job_id = ? AND jobs.status = ? ORDER BY s.score DESC, jobs.id LIMIT ?",
+		jobID,
+		domain.Active,
+		ss.Limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get similar jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []Job
+	for rows.Next() {
+		job, _, err := scanJob(rows, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan similar job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating similar jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// Refresh recomputes the similar jobs of every open job. Each job's list
+// is replaced on its own, so pages keep showing the old list until then.
+func (ss *SimilarService) Refresh() error {
+	jobs, err := ss.features()
+	if err != nil {
+		return err
+	}
+
+	// Only jobs sharing a title word, a tag or the company can score
+	// above minSimilarity, so those are the only pairs compared.
+	index := make(map[string][]int)
+	for i, f := range jobs {
+		for _, key := range f.keys {
+			index[key] = append(index[key], i)
+		}
+	}
+
+	for i, f := range jobs {
+		seen := map[int]bool{i: true}
+		type scored struct {
+			id    int
+			score float64
+		}
+		var best []scored
+		for _, key := range f.keys {
+			for _, j := range index[key] {
+				if seen[j] {
+					continue
+				}
+				seen[j] = true
+				if score := similarity(f, jobs[j]); score >= minSimilarity {
+					best = append(best, scored{jobs[j].id, score})
+				}
+			}
+		}
+
+		sort.Slice(best, func(a, b int) bool {
+			if best[a].score != best[b].score {
+				return best[a].score > best[b].score
+			}
+			return best[a].id < best[b].id
+		})
+		if len(best) > ss.Limit {
+			best = best[:ss.Limit]
+		}
+
+		if _, err := ss.JobStore.Exec("DELETE FROM similar_jobs WHERE job_id = ?", f.id); err != nil {
+			return fmt.Errorf("failed to clear similar jobs: %w", err)
+		}
+		if len(best) == 0 {
+			continue
+		}
+		values := make([]string, len(best))
+		args := make([]interface{}, 0, 3*len(best))
+		for k, b := range best {
+			values[k] = "(?, ?, ?)"
+			args = append(args, f.id, b.id, b.score)
+		}
+		if _, err := ss.JobStore.Exec(
+			"INSERT INTO similar_jobs (job_id, similar_id, score) VALUES "+strings.Join(values, ", "),
+			args...,
+		); err != nil {
+			return fmt.Errorf("failed to save similar jobs: %w", err)
+		}
+	}
+
+	// Lists of jobs that closed or went away are no longer shown.
+	if _, err := ss.JobStore.Exec(
+		"DELETE FROM similar_jobs WHERE job_id NOT IN (SELECT id FROM jobs WHERE status = ?)",
+		domain.Active,
+	); err != nil {
+		return fmt.Errorf("failed to clear similar jobs: %w", err)
+	}
+
+	log.Printf("✅ Computed similar jobs for %d jobs", len(jobs))
+	return nil
+}
+
+// features loads the open jobs and weights their titles by TF-IDF.
+func (ss *SimilarService) features() ([]similarFeatures, error) {
+	rows, err := ss.JobStore.Query(
+		"SELECT id, title, company_id, city, country_code, is_remote, "+tagsColumn+" FROM jobs WHERE status = ?",
+		domain.Active,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []similarFeatures
+	corpus := match.NewCorpus()
+	for rows.Next() {
+		var (
+			f                   similarFeatures
+			title               string
+			company             sql.NullInt64
+			city, country, tags sql.NullString
+		)
+		if err := rows.Scan(&f.id, &title, &company, &city, &country, &f.remote, &tags); err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+
+		f.terms = match.Terms(title)
+		corpus.Add(f.terms)
+		f.company = company.Int64
+		f.country = strings.ToLower(country.String)
+		if city.Valid && city.String != "" {
+			f.place = strings.ToLower(city.String) + "," + f.country
+		}
+		f.tags = make(map[string]bool)
+		for _, tag := range strings.Split(tags.String, "|") {
+			if tag != "" {
+				f.tags[strings.ToLower(tag)] = true
+			}
+		}
+		jobs = append(jobs, f)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating jobs: %w", err)
+	}
+
+	for i := range jobs {
+		f := &jobs[i]
+		f.title = corpus.Vector(f.terms)
+		for term := range f.terms {
+			f.keys = append(f.keys, "t:"+term)
+		}
+		for tag := range f.tags {
+			f.keys = append(f.keys, "g:"+tag)
+		}
+		if f.company != 0 {
+			f.keys = append(f.keys, "c:"+strconv.FormatInt(f.company, 10))
+		}
+	}
+
+	return jobs, nil
+}
+
+// similarity scores two jobs from 0 to 1.
+func similarity(a, b similarFeatures) float64 {
+	score := similarTitleWeight * match.Cosine(a.title, b.title)
+	score += similarTagWeight * jaccard(a.tags, b.tags)
+	if a.company != 0 && a.company == b.company {
+		score += similarCompanyWeight
+	}
+	switch {
+	case a.remote && b.remote, a.place != "" && a.place == b.place:
+		score += similarLocationWeight
+	case a.country != "" && a.country == b.country:
+		score += similarLocationWeight / 2
+	}
+	return score
+}
+
+// jaccard is the share of tags two jobs have in common.
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	common := 0
+	for tag := range a {
+		if b[tag] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
+
+// Run refreshes the similar jobs now and then every interval until ctx is
+// cancelled.
+func (ss *SimilarService) Run(ctx context.Context, interval time.Duration) {
+	if err := ss.Refresh(); err != nil {
+		log.Printf("🔥 similar jobs refresh failed: %s", err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := ss.Refresh(); err != nil {
+				log.Printf("🔥 similar jobs refresh failed: %s", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"htmxjb/models/domain"
+	"htmxjb/services/geo"
+	"htmxjb/services/tags"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimilarJobs(t *testing.T) {
+	store := openTestStore(t)
+	clk := &clock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	skills, err := tags.Bundled()
+	require.NoError(t, err)
+	places, err := geo.Bundled()
+	require.NoError(t, err)
+
+	es := NewEmployerService(store, NewCompanyService(store))
+	ps := NewPostingService(store, []JobEnricher{LocationEnricher(places), TagEnricher(skills)})
+	ps.Now = clk.Now
+	ss := NewSimilarService(store)
+	ss.Now = clk.Now
+
+	ann, err := es.SignUp("ann@acme.example", "Ann", "Acme", "correct horse battery")
+	require.NoError(t, err)
+	gus, err := es.SignUp("gus@globex.example", "Gus", "Globex", "correct horse battery")
+	require.NoError(t, err)
+
+	post := func(e Employer, title, description, location string) int {
+		p, err := ps.CreateDraft(e)
+		require.NoError(t, err)
+		p.Title, p.Description = title, description
+		p.Workplace, p.Location = domain.Onsite, location
+		require.NoError(t, ps.Save(e.ID, p))
+		require.NoError(t, ps.Publish(e.ID, p.ID, time.Time{}, time.Time{}))
+		return p.ID
+	}
+	goBerlin := post(ann, "Senior Go Engineer", "Go and Kubernetes.", "Berlin")
+	goAcme := post(ann, "Go Backend Engineer", "Go and PostgreSQL.", "Munich")
+	goGlobex := post(gus, "Go Developer", "Go and Kubernetes.", "Berlin")
+	designer := post(gus, "Product Designer", "Figma.", "Paris")
+
+	similar, err := ss.Similar(goBerlin)
+	require.NoError(t, err)
+	assert.Empty(t, similar, "nothing until the first refresh")
+
+	require.NoError(t, ss.Refresh())
+
+	similar, err = ss.Similar(goBerlin)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int{goAcme, goGlobex}, jobIDs(similar))
+	assert.Equal(t, "Go Developer", similar[0].Title, "same tags and city outweigh the same company")
+
+	similar, err = ss.Similar(designer)
+	require.NoError(t, err)
+	assert.Empty(t, similar)
+
+	t.Run("limit", func(t *testing.T) {
+		ss.Limit = 1
+		defer func() { ss.Limit = defaultSimilarLimit }()
+		require.NoError(t, ss.Refresh())
+
+		similar, err := ss.Similar(goAcme)
+		require.NoError(t, err)
+		assert.Len(t, similar, 1)
+	})
+
+	require.NoError(t, ss.Refresh())
+	require.NoError(t, ps.Close(gus.ID, goGlobex))
+
+	similar, err = ss.Similar(goBerlin)
+	require.NoError(t, err)
+	assert.Equal(t, []int{goAcme}, jobIDs(similar), "closed jobs are not suggested")
+
+	require.NoError(t, ss.Refresh())
+	var count int
+	require.NoError(t, store.QueryRow("SELECT COUNT(*) FROM similar_jobs WHERE job_id = ? OR similar_id = ?", goGlobex, goGlobex).Scan(&count))
+	assert.Zero(t, count, "refresh drops closed jobs")
+}
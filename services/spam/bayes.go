@@ -0,0 +1,101 @@
+package spam
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// minExamples is how many spam and how many good postings the model needs
+// before its scores are used.
+const minExamples = 10
+
+// Bayes is a multinomial naive Bayes model over the words of a posting,
+// with add-one smoothing.
+type Bayes struct {
+	words  [2]map[string]int
+	totals [2]int
+	docs   [2]int
+	vocab  map[string]bool
+}
+
+const (
+	ham = iota
+	spam
+)
+
+func NewBayes() *Bayes {
+	return &Bayes{
+		words: [2]map[string]int{make(map[string]int), make(map[string]int)},
+		vocab: make(map[string]bool),
+	}
+}
+
+// Train adds one labelled posting.
+func (b *Bayes) Train(text string, isSpam bool) {
+	class := ham
+	if isSpam {
+		class = spam
+	}
+
+	b.docs[class]++
+	for _, w := range Tokenize(text) {
+		b.words[class][w]++
+		b.totals[class]++
+		b.vocab[w] = true
+	}
+}
+
+// Ready reports whether the model has seen enough of both classes.
+func (b *Bayes) Ready() bool {
+	return b.docs[ham] >= minExamples && b.docs[spam] >= minExamples
+}
+
+// Examples returns how many good and spam postings the model was trained
+// on.
+func (b *Bayes) Examples() (good, bad int) {
+	return b.docs[ham], b.docs[spam]
+}
+
+// SpamProbability is P(spam | text). Words the model never saw are
+// ignored.
+func (b *Bayes) SpamProbability(text string) float64 {
+	total := float64(b.docs[ham] + b.docs[spam])
+	if total == 0 {
+		return 0
+	}
+
+	var logp [2]float64
+	for class := range logp {
+		logp[class] = math.Log(float64(b.docs[class]+1) / (total + 2))
+	}
+
+	vocab := float64(len(b.vocab))
+	for _, w := range Tokenize(text) {
+		if !b.vocab[w] {
+			continue
+		}
+		for class := range logp {
+			logp[class] += math.Log(float64(b.words[class][w]+1) / (float64(b.totals[class]) + vocab))
+		}
+	}
+
+	// 1 / (1 + e^(ham - spam)) avoids underflow on long descriptions.
+	return 1 / (1 + math.Exp(logp[ham]-logp[spam]))
+}
+
+// Tokenize splits text into lower-case words of two or more letters or
+// digits.
+func Tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := words[:0]
+	for _, w := range words {
+		if len(w) >= 2 {
+			tokens = append(tokens, w)
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,174 @@
+package spam
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"htmxjb/models/domain"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+//go:embed rules.json
+var bundledRules []byte
+
+// How much each rule adds to the score. Hits are combined as independent
+// evidence, so two weak hits score higher than either alone.
+const (
+	weightBlockedKeyword   = 0.6
+	weightBlockedCompany   = 1.0
+	weightNoDescription    = 0.5
+	weightShortDescription = 0.3
+	weightSuspiciousURL    = 0.5
+	weightAllCapsTitle     = 0.3
+)
+
+var urlPattern = regexp.MustCompile(`https?://[^\s"'<>)]+`)
+
+// Rules are the configurable checks. Keywords and companies match
+// regardless of case; suspicious hosts also match their subdomains.
+type Rules struct {
+	BlockedKeywords      []string `json:"blocked_keywords"`
+	BlockedCompanies     []string `json:"blocked_companies"`
+	MinDescriptionLength int      `json:"min_description_length"`
+	SuspiciousHosts      []string `json:"suspicious_hosts"`
+}
+
+// Bundled returns the rules shipped with the binary.
+func Bundled() (Rules, error) {
+	return Load(bundledRules)
+}
+
+// LoadFile reads rules from a JSON file, replacing the bundled ones.
+func LoadFile(path string) (Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Rules{}, fmt.Errorf("failed to read spam rules: %w", err)
+	}
+	return Load(data)
+}
+
+func Load(data []byte) (Rules, error) {
+	var rules Rules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return Rules{}, fmt.Errorf("failed to parse spam rules: %w", err)
+	}
+	return rules, nil
+}
+
+// hit is a rule that matched, with its weight.
+type hit struct {
+	reason string
+	weight float64
+}
+
+func (r Rules) check(job domain.Job) []hit {
+	var hits []hit
+
+	text := strings.ToLower(job.Title + "\n" + job.Description)
+	for _, kw := range r.BlockedKeywords {
+		if kw = strings.ToLower(strings.TrimSpace(kw)); kw != "" && containsWord(text, kw) {
+			hits = append(hits, hit{fmt.Sprintf("blocked keyword %q", kw), weightBlockedKeyword})
+		}
+	}
+
+	company := strings.TrimSpace(job.Company)
+	for _, blocked := range r.BlockedCompanies {
+		if company != "" && strings.EqualFold(company, strings.TrimSpace(blocked)) {
+			hits = append(hits, hit{fmt.Sprintf("blocked company %q", company), weightBlockedCompany})
+			break
+		}
+	}
+
+	switch length := len([]rune(strings.TrimSpace(job.Description))); {
+	case length == 0:
+		hits = append(hits, hit{"missing description", weightNoDescription})
+	case length < r.MinDescriptionLength:
+		hits = append(hits, hit{fmt.Sprintf("description shorter than %d characters", r.MinDescriptionLength), weightShortDescription})
+	}
+
+	for _, link := range links(job) {
+		if host, ok := r.suspicious(link); ok {
+			hits = append(hits, hit{fmt.Sprintf("suspicious link to %s", host), weightSuspiciousURL})
+			break
+		}
+	}
+
+	if allCaps(job.Title) {
+		hits = append(hits, hit{"all-caps title", weightAllCapsTitle})
+	}
+
+	return hits
+}
+
+// suspicious reports whether link points at a listed host or a bare IP
+// address.
+func (r Rules) suspicious(link string) (string, bool) {
+	u, err := url.Parse(link)
+	if err != nil || u.Hostname() == "" {
+		return "", false
+	}
+	host := strings.ToLower(u.Hostname())
+
+	if net.ParseIP(host) != nil {
+		return host, true
+	}
+	for _, s := range r.SuspiciousHosts {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if s != "" && (host == s || strings.HasSuffix(host, "."+s)) {
+			return host, true
+		}
+	}
+	return "", false
+}
+
+// links are the job's own URL and every URL in its description.
+func links(job domain.Job) []string {
+	var found []string
+	if job.URL != "" {
+		found = append(found, job.URL)
+	}
+	found = append(found, urlPattern.FindAllString(job.Description, -1)...)
+	found = append(found, urlPattern.FindAllString(job.DescriptionHTML, -1)...)
+	return found
+}
+
+// containsWord finds phrase in text at word boundaries, so "mlm" does not
+// match inside "html".
+func containsWord(text, phrase string) bool {
+	for start := 0; ; {
+		i := strings.Index(text[start:], phrase)
+		if i < 0 {
+			return false
+		}
+		i += start
+		end := i + len(phrase)
+		if (i == 0 || !isWordByte(text[i-1])) && (end == len(text) || !isWordByte(text[end])) {
+			return true
+		}
+		start = i + 1
+	}
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 0x80
+}
+
+// allCaps reports a title that shouts: two or more words, eight or more
+// letters and none of them lower case. Acronyms such as "CTO" are fine.
+func allCaps(title string) bool {
+	letters := 0
+	for _, r := range title {
+		if unicode.IsLower(r) {
+			return false
+		}
+		if unicode.IsLetter(r) {
+			letters++
+		}
+	}
+	return letters >= 8 && len(strings.Fields(title)) >= 2
+}
@@ -0,0 +1,99 @@
+package spam
+
+import (
+	"fmt"
+	"htmxjb/models/domain"
+	"sync"
+)
+
+// Verdict is what happens to a scored posting.
+type Verdict int
+
+const (
+	Publish Verdict = iota
+	Review
+	Reject
+)
+
+func (v Verdict) String() string {
+	switch v {
+	case Publish:
+		return "publish"
+	case Review:
+		return "review"
+	case Reject:
+		return "reject"
+	default:
+		return "unknown"
+	}
+}
+
+// Result is a posting's spam score from 0 to 1, the reasons behind it and
+// the verdict the thresholds give.
+type Result struct {
+	Score   float64
+	Reasons []string
+	Verdict Verdict
+}
+
+// Classifier scores postings with the rules and, once trained, a naive
+// Bayes model of moderator decisions. The higher of the two scores wins.
+type Classifier struct {
+	rules    Rules
+	reviewAt float64
+	rejectAt float64
+
+	mu    sync.RWMutex
+	model *Bayes
+}
+
+// NewClassifier returns a classifier that holds postings scoring reviewAt
+// or more for review and rejects those scoring rejectAt or more.
+func NewClassifier(rules Rules, reviewAt, rejectAt float64) *Classifier {
+	return &Classifier{
+		rules:    rules,
+		reviewAt: reviewAt,
+		rejectAt: rejectAt,
+	}
+}
+
+// SetModel replaces the trained model. A model that is not Ready is kept
+// but not used.
+func (c *Classifier) SetModel(b *Bayes) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.model = b
+}
+
+func (c *Classifier) Classify(job domain.Job) Result {
+	var res Result
+
+	// Hits are combined as independent evidence: 1 - Π(1 - weight).
+	clean := 1.0
+	for _, h := range c.rules.check(job) {
+		clean *= 1 - h.weight
+		res.Reasons = append(res.Reasons, h.reason)
+	}
+	res.Score = 1 - clean
+
+	c.mu.RLock()
+	model := c.model
+	c.mu.RUnlock()
+
+	if model != nil && model.Ready() {
+		p := model.SpamProbability(job.Title + "\n" + job.Description)
+		if p >= c.reviewAt {
+			res.Reasons = append(res.Reasons, fmt.Sprintf("classifier %.2f", p))
+		}
+		res.Score = max(res.Score, p)
+	}
+
+	switch {
+	case res.Score >= c.rejectAt:
+		res.Verdict = Reject
+	case res.Score >= c.reviewAt:
+		res.Verdict = Review
+	}
+
+	return res
+}
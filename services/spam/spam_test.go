@@ -0,0 +1,134 @@
+package spam
+
+import (
+	"fmt"
+	"htmxjb/models/domain"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var description = strings.Repeat("We build accounting software for small businesses in Go and Postgres. ", 4)
+
+func TestClassifyRules(t *testing.T) {
+	rules, err := Bundled()
+	require.NoError(t, err)
+	rules.BlockedCompanies = []string{"Acme Pyramid"}
+	c := NewClassifier(rules, 0.5, 0.9)
+
+	for _, tc := range []struct {
+		name    string
+		job     domain.Job
+		verdict Verdict
+		reason  string
+	}{
+		{
+			name:    "clean",
+			job:     domain.Job{Title: "Backend Engineer", Company: "Ledger", Description: description, URL: "https://ledger.example/jobs/1"},
+			verdict: Publish,
+		},
+		{
+			name:    "keyword",
+			job:     domain.Job{Title: "Be your own boss", Description: description},
+			verdict: Review,
+			reason:  `blocked keyword "be your own boss"`,
+		},
+		{
+			name:    "keyword inside a word",
+			job:     domain.Job{Title: "Frontend Engineer", Description: description + " We use HTML and forex-free pricing."},
+			verdict: Publish,
+		},
+		{
+			name:    "blocked company",
+			job:     domain.Job{Title: "Sales", Company: " acme pyramid ", Description: description},
+			verdict: Reject,
+			reason:  `blocked company "acme pyramid"`,
+		},
+		{
+			name:    "missing description",
+			job:     domain.Job{Title: "Engineer"},
+			verdict: Review,
+			reason:  "missing description",
+		},
+		{
+			name:    "short description",
+			job:     domain.Job{Title: "Engineer", Description: "Apply now."},
+			verdict: Publish,
+			reason:  "description shorter than 200 characters",
+		},
+		{
+			name:    "suspicious link",
+			job:     domain.Job{Title: "Engineer", Description: description + " Apply at https://bit.ly/abc"},
+			verdict: Review,
+			reason:  "suspicious link to bit.ly",
+		},
+		{
+			name:    "ip address",
+			job:     domain.Job{Title: "Engineer", Description: description, URL: "http://203.0.113.7/apply"},
+			verdict: Review,
+			reason:  "suspicious link to 203.0.113.7",
+		},
+		{
+			name:    "evidence adds up",
+			job:     domain.Job{Title: "EARN MONEY FROM HOME NOW", Description: "Message https://wa.me/123"},
+			verdict: Reject,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			res := c.Classify(tc.job)
+			assert.Equal(t, tc.verdict, res.Verdict, "score %.2f %v", res.Score, res.Reasons)
+			if tc.reason != "" {
+				assert.Contains(t, res.Reasons, tc.reason)
+			}
+		})
+	}
+}
+
+func TestClassifyModel(t *testing.T) {
+	c := NewClassifier(Rules{}, 0.5, 0.9)
+	job := domain.Job{Title: "Crypto signals", Description: "Join our telegram group for daily crypto signals and passive income"}
+
+	b := NewBayes()
+	for i := 0; i < minExamples; i++ {
+		b.Train(fmt.Sprintf("passive income crypto signals telegram group %d", i), true)
+		b.Train(fmt.Sprintf("backend engineer go postgres kubernetes team %d", i), false)
+	}
+
+	c.SetModel(b)
+	res := c.Classify(job)
+	assert.Equal(t, Reject, res.Verdict)
+	assert.Greater(t, res.Score, 0.9)
+	assert.Len(t, res.Reasons, 1)
+
+	ok := c.Classify(domain.Job{Title: "Backend engineer", Description: "Go and Postgres on Kubernetes"})
+	assert.Equal(t, Publish, ok.Verdict)
+	assert.Less(t, ok.Score, 0.1)
+}
+
+func TestBayesNotReady(t *testing.T) {
+	b := NewBayes()
+	for i := 0; i < minExamples; i++ {
+		b.Train("passive income", true)
+	}
+	assert.False(t, b.Ready())
+
+	c := NewClassifier(Rules{}, 0.5, 0.9)
+	c.SetModel(b)
+	assert.Zero(t, c.Classify(domain.Job{Title: "Passive income", Description: "passive income"}).Score)
+}
+
+func TestTokenize(t *testing.T) {
+	assert.Equal(t, []string{"senior", "go", "engineer", "k8s", "über"}, Tokenize("Senior Go-Engineer (k8s), a Über!"))
+}
+
+func TestLoad(t *testing.T) {
+	rules, err := Load([]byte(`{"blocked_keywords": ["mlm"], "min_description_length": 50}`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mlm"}, rules.BlockedKeywords)
+	assert.Equal(t, 50, rules.MinDescriptionLength)
+
+	_, err = Load([]byte(`{`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"htmxjb/db"
+	"htmxjb/models/domain"
+	"htmxjb/services/spam"
+	"log"
+	"strings"
+	"time"
+)
+
+// SpamFilter scores ingested jobs with the spam classifier and retrains
+// its model on moderator decisions.
+type SpamFilter struct {
+	JobStore   db.Store
+	Classifier *spam.Classifier
+}
+
+func NewSpamFilter(jobStore db.Store, classifier *spam.Classifier) *SpamFilter {
+	return &SpamFilter{
+		JobStore:   jobStore,
+		Classifier: classifier,
+	}
+}
+
+// Screen scores job and holds it for review or rejects it as the verdict
+// says. It never lists a job that moderation held back.
+func (sf *SpamFilter) Screen(job *domain.Job) {
+	res := sf.Classifier.Classify(*job)
+	job.SpamScore, job.SpamReasons = res.Score, res.Reasons
+
+	switch res.Verdict {
+	case spam.Reject:
+		job.Status = domain.Rejected
+		job.RejectionReason = SpamReason + ": " + strings.Join(res.Reasons, ", ")
+	case spam.Review:
+		job.Status = domain.Pending
+	}
+}
+
+// Train rebuilds the model from reviewed jobs. Approved jobs are good and
+// jobs a moderator rejected as spam are bad; other rejections say nothing
+// about spam and are left out.
+func (sf *SpamFilter) Train() error {
+	rows, err := sf.JobStore.Query(
+		"SELECT title, description, status FROM jobs WHERE reviewed_at IS NOT NULL AND (status <> ? OR rejection_reason LIKE ?)",
+		domain.Rejected,
+		SpamReason+"%",
+	)
+	if err != nil {
+		return fmt.Errorf("failed to get reviewed jobs: %w", err)
+	}
+	defer rows.Close()
+
+	model := spam.NewBayes()
+	for rows.Next() {
+		var (
+			title       string
+			description sql.NullString
+			status      domain.JobStatus
+		)
+		if err := rows.Scan(&title, &description, &status); err != nil {
+			return fmt.Errorf("failed to scan reviewed job: %w", err)
+		}
+		model.Train(title+"\n"+description.String, status == domain.Rejected)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating reviewed jobs: %w", err)
+	}
+
+	sf.Classifier.SetModel(model)
+
+	good, bad := model.Examples()
+	if !model.Ready() {
+		log.Printf("✅ Spam model has %d good and %d spam examples, using rules only", good, bad)
+		return nil
+	}
+	log.Printf("✅ Trained spam model on %d good and %d spam jobs", good, bad)
+
+	return nil
+}
+
+// Run retrains every interval until ctx is cancelled.
+func (sf *SpamFilter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := sf.Train(); err != nil {
+				log.Printf("🔥 spam model training failed: %s", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"htmxjb/models/domain"
+	"htmxjb/services/spam"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpamFilterScreen(t *testing.T) {
+	store := openTestStore(t)
+	retention := NewRetentionService(store, RetentionPolicy{})
+	rules, err := ParseModerationRules("", "", "Acme")
+	require.NoError(t, err)
+
+	classifier := spam.NewClassifier(spam.Rules{
+		BlockedKeywords:  []string{"be your own boss"},
+		BlockedCompanies: []string{"Pyramid Inc"},
+	}, 0.5, 0.9)
+
+	fetcher := &stubFetcher{
+		source: domain.Indeed,
+		jobs: []domain.Job{
+			{ExternalID: "clean", Title: "Go Developer", Description: "Services in Go"},
+			{ExternalID: "keyword", Title: "Be your own boss", Description: "Sales"},
+			{ExternalID: "company", Title: "Sales", Description: "Sales", Company: "Pyramid Inc"},
+			{ExternalID: "trusted", Title: "Be your own boss", Description: "Founders", Company: "Acme"},
+		},
+	}
+	ingestor := NewIngestor(NewJobServices(Job{}, store), retention, nil, fetcher).
+		WithModeration(rules).
+		WithSpamFilter(NewSpamFilter(store, classifier))
+	require.NoError(t, ingestor.RunOnce(context.Background()))
+
+	assert.Equal(t, map[string]domain.JobStatus{
+		"clean":   domain.Active,
+		"keyword": domain.Pending,
+		"company": domain.Rejected,
+		"trusted": domain.Active,
+	}, jobStatuses(t, store))
+
+	pending, err := NewReviewService(store).PendingJobs()
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.InDelta(t, 0.6, pending[0].SpamScore, 0.001)
+	assert.Equal(t, []string{`blocked keyword "be your own boss"`}, pending[0].SpamReasons)
+
+	var reason string
+	require.NoError(t, store.QueryRow("SELECT rejection_reason FROM jobs WHERE external_id = 'company'").Scan(&reason))
+	assert.Equal(t, `Spam or scam: blocked company "Pyramid Inc"`, reason)
+}
+
+func TestSpamFilterTrain(t *testing.T) {
+	store := openTestStore(t)
+	clk := &clock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	retention := NewRetentionService(store, RetentionPolicy{})
+	retention.Now = clk.Now
+	rules, err := ParseModerationRules("*", "", "")
+	require.NoError(t, err)
+
+	fetcher := &stubFetcher{source: domain.Csv}
+	for i := 0; i < 10; i++ {
+		fetcher.jobs = append(fetcher.jobs,
+			domain.Job{ExternalID: fmt.Sprintf("spam-%d", i), Title: "Crypto signals", Description: "Passive income from crypto signals, join our telegram"},
+			domain.Job{ExternalID: fmt.Sprintf("dupe-%d", i), Title: "Go Developer", Description: "Duplicate of another posting"},
+			domain.Job{ExternalID: fmt.Sprintf("ham-%d", i), Title: "Backend Engineer", Description: "Go and Postgres services on Kubernetes"},
+		)
+	}
+
+	filter := NewSpamFilter(store, spam.NewClassifier(spam.Rules{}, 0.5, 0.9))
+	ingestor := NewIngestor(NewJobServices(Job{}, store), retention, nil, fetcher).
+		WithModeration(rules).
+		WithSpamFilter(filter)
+	require.NoError(t, ingestor.RunOnce(context.Background()))
+
+	reviews := NewReviewService(store)
+	pending, err := reviews.PendingJobs()
+	require.NoError(t, err)
+	require.Len(t, pending, 30)
+	for _, job := range pending {
+		switch {
+		case strings.HasPrefix(job.Title, "Crypto"):
+			_, err = reviews.Reject(SpamReason+": telegram", job.ID)
+		case strings.HasPrefix(job.Description, "Duplicate"):
+			_, err = reviews.Reject("Duplicate", job.ID)
+		default:
+			_, err = reviews.Approve(job.ID)
+		}
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, filter.Train())
+
+	fetcher.jobs = []domain.Job{
+		{ExternalID: "new-spam", Title: "Crypto signals", Description: "Daily crypto signals on telegram"},
+		{ExternalID: "new-ham", Title: "Platform Engineer", Description: "Kubernetes and Postgres"},
+	}
+	clk.Advance(time.Hour)
+	require.NoError(t, ingestor.RunOnce(context.Background()))
+
+	statuses := jobStatuses(t, store)
+	assert.Equal(t, domain.Rejected, statuses["new-spam"])
+	assert.Equal(t, domain.Pending, statuses["new-ham"])
+}
@@ -0,0 +1,170 @@
+package tags
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+//go:embed skills.tsv
+var bundledSkills []byte
+
+// Extractor finds skills from a dictionary in job text and returns their
+// canonical names.
+type Extractor struct {
+	fold     map[string]string
+	exact    map[string]string
+	maxWords int
+}
+
+// Bundled returns an extractor loaded from the skills dictionary shipped
+// with the binary.
+func Bundled() (*Extractor, error) {
+	return Load(bundledSkills)
+}
+
+// LoadFile reads a skills dictionary from path, replacing the bundled one.
+func LoadFile(path string) (*Extractor, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read skills file: %w", err)
+	}
+	return Load(data)
+}
+
+// Load parses a tab-separated dictionary with columns name and synonyms
+// (separated by "|"). The name always matches as well. A term with a
+// leading "=" only matches with exact case, for names that are also common
+// words such as "Go" or "REST". Lines starting with "#" are comments.
+func Load(data []byte) (*Extractor, error) {
+	x := &Extractor{
+		fold:  make(map[string]string),
+		exact: make(map[string]string),
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Split(text, "\t")
+		if len(fields) > 2 {
+			return nil, fmt.Errorf("skills line %d: want at most 2 fields, got %d", line, len(fields))
+		}
+
+		name := strings.TrimPrefix(strings.TrimSpace(fields[0]), "=")
+		if name == "" || strings.Contains(name, "|") {
+			return nil, fmt.Errorf("skills line %d: invalid name %q", line, fields[0])
+		}
+
+		terms := []string{strings.TrimSpace(fields[0])}
+		if len(fields) == 2 && fields[1] != "" {
+			terms = append(terms, strings.Split(fields[1], "|")...)
+		}
+
+		for _, term := range terms {
+			if err := x.add(name, strings.TrimSpace(term)); err != nil {
+				return nil, fmt.Errorf("skills line %d: %w", line, err)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read skills: %w", err)
+	}
+
+	return x, nil
+}
+
+func (x *Extractor) add(name, term string) error {
+	table, exact := x.fold, strings.HasPrefix(term, "=")
+	if exact {
+		table, term = x.exact, term[1:]
+	}
+
+	words := tokenize(term)
+	if len(words) == 0 {
+		return fmt.Errorf("empty term for %q", name)
+	}
+
+	key := strings.Join(words, " ")
+	if !exact {
+		key = strings.ToLower(key)
+	}
+	if other, ok := table[key]; ok && other != name {
+		return fmt.Errorf("term %q is used by both %q and %q", term, other, name)
+	}
+	table[key] = name
+
+	if len(words) > x.maxWords {
+		x.maxWords = len(words)
+	}
+
+	return nil
+}
+
+// Extract returns the canonical names of the skills mentioned in texts, in
+// order of first mention. Longer phrases win, so "React Native" is not
+// also tagged "React".
+func (x *Extractor) Extract(texts ...string) []string {
+	var found []string
+	seen := make(map[string]bool)
+
+	for _, text := range texts {
+		words := tokenize(text)
+		for i := 0; i < len(words); {
+			name, n := x.match(words[i:])
+			if n == 0 {
+				i++
+				continue
+			}
+			if !seen[name] {
+				seen[name] = true
+				found = append(found, name)
+			}
+			i += n
+		}
+	}
+
+	return found
+}
+
+// match looks up the longest term at the start of words and returns its
+// name and length in words.
+func (x *Extractor) match(words []string) (string, int) {
+	for n := min(x.maxWords, len(words)); n > 0; n-- {
+		key := strings.Join(words[:n], " ")
+		if name, ok := x.exact[key]; ok {
+			return name, n
+		}
+		if name, ok := x.fold[strings.ToLower(key)]; ok {
+			return name, n
+		}
+	}
+	return "", 0
+}
+
+// tokenize splits text into words. "+", "#", "." and "_" are kept inside
+// words so that "C++", "C#", ".NET" and "Node.js" survive; a trailing "."
+// is treated as punctuation.
+func tokenize(text string) []string {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("+#._", r)
+	})
+
+	words := fields[:0]
+	for _, field := range fields {
+		if field = strings.TrimRight(field, "."); field != "" {
+			words = append(words, field)
+		}
+	}
+	return words
+}
@@ -0,0 +1,53 @@
+package tags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtract(t *testing.T) {
+	x, err := Bundled()
+	require.NoError(t, err)
+
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Senior Golang engineer, Postgres and k8s.", []string{"Go", "PostgreSQL", "Kubernetes"}},
+		{"We use Go, templ and htmx.", []string{"Go", "templ", "htmx"}},
+		{"Ready to go the extra mile? Make a template.", nil},
+		{"C++ and C# developers; .NET experience is a plus", []string{"C++", "C#", ".NET"}},
+		{"React Native or React.js, Node.js", []string{"React Native", "React", "Node.js"}},
+		{"NodeJS backend with a REST API, the rest is up to you", []string{"Node.js", "REST"}},
+		{"Spring Boot microservices on AWS", []string{"Spring", "Microservices", "AWS"}},
+		{"Test-driven development, CI/CD", []string{"TDD", "CI/CD"}},
+		{"Write C and R, not c or r", []string{"C", "R"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			assert.Equal(t, tt.want, x.Extract(tt.text))
+		})
+	}
+}
+
+func TestExtractDedupesAcrossTexts(t *testing.T) {
+	x, err := Bundled()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Python", "Django"}, x.Extract("Python developer", "Django and python3"))
+}
+
+func TestLoad(t *testing.T) {
+	x, err := Load([]byte("# custom\n=Go\tgolang\nElm\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Go", "Elm"}, x.Extract("golang and elm"))
+
+	_, err = Load([]byte("Go\tgolang\tlang\n"))
+	assert.Error(t, err)
+
+	_, err = Load([]byte("Go\tgolang\nGopher\tgolang\n"))
+	assert.Error(t, err)
+}